@@ -0,0 +1,69 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// FromActor adapts an oklog/run style actor into a Service, so projects can
+// move between the two patterns one actor at a time. execute runs as the
+// start function, which is ready as soon as it is called, and interrupt is
+// called with ErrStopping to stop it. Whatever execute returns once stopping
+// has begun is not treated as a failure.
+func FromActor(name string, execute func() error, interrupt func(error)) Service {
+	return Service{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			Ready(ctx)
+
+			err := execute()
+			if errors.Is(context.Cause(ctx), ErrStopping) {
+				return nil
+			}
+
+			return err
+		},
+		Stop: func(_ context.Context) {
+			interrupt(ErrStopping)
+		},
+	}
+}
+
+// Actor adapts the service into an oklog/run style (execute, interrupt) pair
+// for adding to a run.Group. execute calls the start function and then
+// blocks until interrupt is called, which cancels the start context with the
+// given error and calls the stop function under DefaultShutdownTimeout.
+// Errors from a StopErr function are dropped, as interrupt can't return them.
+func (s Service) Actor() (execute func() error, interrupt func(error)) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	once := sync.Once{}
+
+	execute = func() error {
+		if err := s.Start(ctx); err != nil {
+			return err
+		}
+
+		<-ctx.Done()
+
+		return nil
+	}
+
+	interrupt = func(err error) {
+		once.Do(func() {
+			cancel(err)
+
+			stopCtx, stop := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
+			defer stop()
+
+			switch {
+			case s.StopErr != nil:
+				_ = s.StopErr(stopCtx)
+			case s.Stop != nil:
+				s.Stop(stopCtx)
+			}
+		})
+	}
+
+	return execute, interrupt
+}
@@ -0,0 +1,98 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// actor is an oklog/run style pair.
+type actor struct {
+	execute   func() error
+	interrupt func(error)
+}
+
+// runGroup mimics run.Group.Run: it runs every actor, interrupts them all
+// once the first returns and returns that actor's error.
+func runGroup(actors ...actor) error {
+	errs := make(chan error, len(actors))
+
+	for _, a := range actors {
+		go func() { errs <- a.execute() }()
+	}
+
+	err := <-errs
+
+	for _, a := range actors {
+		a.interrupt(err)
+	}
+
+	for range len(actors) - 1 {
+		<-errs
+	}
+
+	return err
+}
+
+func TestFromActor(t *testing.T) {
+	quit := make(chan struct{})
+	interrupted := make(chan error, 1)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithServices(controls.FromActor("loop",
+			func() error {
+				<-quit
+
+				return errors.New("interrupted") //nolint:err113
+			},
+			func(err error) {
+				interrupted <- err
+				close(quit)
+			},
+		)),
+	)
+
+	c.Start()
+	assert.Equal(t, 1, c.RunningCount())
+
+	require.NoError(t, c.Stop())
+	require.ErrorIs(t, <-interrupted, controls.ErrStopping)
+	assert.Empty(t, c.Errs(), "the error returned once interrupted is not a failure")
+}
+
+func TestService_Actor(t *testing.T) {
+	errDone := errors.New("done") //nolint:err113
+
+	stopped := make(chan struct{})
+
+	svc, err := controls.NewService("api").
+		Start(func(_ context.Context) error { return nil }).
+		Stop(func(_ context.Context) { close(stopped) }).
+		Build()
+	require.NoError(t, err)
+
+	execute, interrupt := svc.Actor()
+
+	err = runGroup(
+		actor{execute: execute, interrupt: interrupt},
+		actor{
+			execute:   func() error { time.Sleep(10 * time.Millisecond); return errDone },
+			interrupt: func(error) {},
+		},
+	)
+	require.ErrorIs(t, err, errDone)
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("interrupt did not stop the service")
+	}
+}
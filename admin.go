@@ -0,0 +1,255 @@
+package controls
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const adminReadHeaderTimeout = 5 * time.Second
+
+// WithAdminAPI serves an HTTP admin API on addr while the controller runs.
+//
+//	GET  /services                 list registered services, filtered by ?label=key=value
+//	GET  /status                   full Report
+//	POST /services/{name}/stop     stop a single service
+//	POST /services/{name}/restart  restart a single service
+//	POST /reload                   send a Reload message to the services
+//	POST /shutdown                 gracefully stop the controller
+//
+// Use WithAdminToken, WithAdminClientCerts and WithAdminAuthorizer to restrict
+// it, and WithAdminTLS or WithAdminCertFiles to serve it over TLS.
+//
+// An addr of the form "unix:/path/to.sock" listens on a unix socket instead of
+// TCP.
+func WithAdminAPI(addr string) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.adminAddr = addr
+		})
+	}
+}
+
+// AdminAddr returns the address the admin API is listening on, or an empty
+// string if it isn't running.
+func (c *Controller) AdminAddr() string {
+	c.adminMutex.Lock()
+	defer c.adminMutex.Unlock()
+
+	if c.adminListener == nil {
+		return ""
+	}
+
+	return c.adminListener.Addr().String()
+}
+
+// listenAdmin binds the admin API's listener, wrapped in TLS when configured,
+// so Start can fail before anything runs. It returns nil when the admin API
+// isn't enabled.
+func (c *Controller) listenAdmin() (net.Listener, error) {
+	if c.adminAddr == "" {
+		return nil, nil
+	}
+
+	network, addr := "tcp", c.adminAddr
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		network, addr = "unix", path
+	}
+
+	cfg, err := c.adminTLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("admin API: %w", err)
+	}
+
+	ln, err := (&net.ListenConfig{}).Listen(c.ctx, network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("admin API: %w", err)
+	}
+
+	if cfg != nil {
+		ln = tls.NewListener(ln, cfg)
+	}
+
+	return ln, nil
+}
+
+// startAdmin serves the admin API on ln, the listener from listenAdmin.
+func (c *Controller) startAdmin(ln net.Listener) {
+	if ln == nil {
+		return
+	}
+
+	c.reloadAdminCertOnHangup()
+
+	srv := &http.Server{
+		Handler:           c.AdminHandler(),
+		ReadHeaderTimeout: adminReadHeaderTimeout,
+	}
+
+	c.adminMutex.Lock()
+	c.adminListener = ln
+	c.adminServer = srv
+	c.adminMutex.Unlock()
+
+	c.goBackground(func(_ <-chan struct{}) {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			c.log().Error("Admin API stopped", "error", err)
+		}
+	})
+
+	c.log().Info("Admin API listening", "addr", ln.Addr().String())
+}
+
+func (c *Controller) stopAdmin(ctx context.Context) {
+	c.adminMutex.Lock()
+	srv := c.adminServer
+	c.adminServer = nil
+	c.adminListener = nil
+	c.adminMutex.Unlock()
+
+	if srv == nil {
+		return
+	}
+
+	if err := srv.Shutdown(ctx); err != nil {
+		c.log().Error("Failed to stop admin API", "error", err)
+	}
+}
+
+// AdminHandler returns the http.Handler serving the admin API, for mounting
+// on an existing server instead of using WithAdminAPI.
+func (c *Controller) AdminHandler() http.Handler {
+	for _, principal := range c.adminAuth.emptyTokens {
+		c.log().Error("Ignoring empty admin token", "principal", principal)
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /services", c.guard(ActionServices, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, c.Report().Filter(labelSelector(r)).Services)
+	}))
+
+	mux.HandleFunc("GET /status", c.guard(ActionStatus, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, c.Report())
+	}))
+
+	mux.Handle("GET /health", c.HealthHandler())
+	mux.Handle("GET /livez", c.LivezHandler())
+	mux.Handle("GET /readyz", c.ReadyzHandler())
+	mux.Handle("GET /startupz", c.StartupzHandler())
+
+	mux.HandleFunc("GET /audit", c.guard(ActionAudit, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, c.AuditLog())
+	}))
+
+	mux.HandleFunc("GET /graph", c.guard(ActionGraph, c.graphHandler))
+
+	mux.HandleFunc("POST /services/{name}/stop", c.guard(ActionStopService, func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		writeResult(w, c.audited(adminOrigin(r), ActionStopService, name, "", func() error {
+			return c.stopService(context.Background(), name)
+		}))
+	}))
+
+	mux.HandleFunc("POST /services/{name}/restart", c.guard(ActionRestartService, func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		writeResult(w, c.audited(adminOrigin(r), ActionRestartService, name, "", func() error {
+			return c.restartService(context.Background(), name)
+		}))
+	}))
+
+	mux.HandleFunc("POST /reload", c.guard(ActionReload, func(w http.ResponseWriter, r *http.Request) {
+		if !c.isUp() {
+			writeResult(w, ErrNotRunning)
+
+			return
+		}
+
+		if c.sendMessage(Reload, adminOrigin(r), r.Context().Done()) {
+			writeJSON(w, http.StatusAccepted, adminResponse{Status: "reloading"})
+		}
+	}))
+
+	mux.HandleFunc("POST /maintenance", c.guard(ActionEnterMaintenance, func(w http.ResponseWriter, r *http.Request) {
+		var selector map[string]string
+		if r.URL.Query().Has("label") {
+			selector = labelSelector(r)
+		}
+
+		writeResult(w, c.audited(adminOrigin(r), ActionEnterMaintenance, "", selectorString(selector), func() error {
+			return c.enterMaintenance(selector)
+		}))
+	}))
+
+	mux.HandleFunc("DELETE /maintenance", c.guard(ActionExitMaintenance, func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, c.audited(adminOrigin(r), ActionExitMaintenance, "", "", c.exitMaintenance))
+	}))
+
+	mux.HandleFunc("POST /shutdown", c.guard(ActionShutdown, func(w http.ResponseWriter, r *http.Request) {
+		if !c.isUp() {
+			writeResult(w, ErrNotRunning)
+
+			return
+		}
+
+		writeJSON(w, http.StatusAccepted, adminResponse{Status: "stopping"})
+
+		go c.requestStop(StopRequested, adminOrigin(r))
+	}))
+
+	return mux
+}
+
+// adminOrigin attributes a command to the admin API client making r: its
+// authenticated principal, or else its address.
+func adminOrigin(r *http.Request) origin {
+	if principal, ok := r.Context().Value(principalKey{}).(string); ok {
+		return origin{source: AuditAdmin, actor: principal}
+	}
+
+	return origin{source: AuditAdmin, actor: r.RemoteAddr}
+}
+
+// labelSelector builds a selector from repeated label=key=value query
+// parameters.
+func labelSelector(r *http.Request) map[string]string {
+	selector := map[string]string{}
+
+	for _, l := range r.URL.Query()["label"] {
+		k, v, _ := strings.Cut(l, "=")
+		selector[k] = v
+	}
+
+	return selector
+}
+
+type adminResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func writeResult(w http.ResponseWriter, err error) {
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, adminResponse{Status: "ok"})
+	case errors.Is(err, ErrServiceNotFound):
+		writeJSON(w, http.StatusNotFound, adminResponse{Status: "error", Error: err.Error()})
+	case errors.Is(err, ErrNotRunning), errors.Is(err, ErrServiceDisabled):
+		writeJSON(w, http.StatusConflict, adminResponse{Status: "error", Error: err.Error()})
+	default:
+		writeJSON(w, http.StatusInternalServerError, adminResponse{Status: "error", Error: err.Error()})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
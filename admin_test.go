@@ -0,0 +1,127 @@
+package controls_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_AdminHandler(t *testing.T) {
+	var started, stopped atomic.Int64
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("api",
+		controls.WithStart(func(_ context.Context) error { started.Add(1); return nil }),
+		controls.WithStop(func(_ context.Context) { stopped.Add(1) }),
+	)
+	c.Start()
+
+	h := c.AdminHandler()
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), method, path, nil))
+
+		return rec
+	}
+
+	t.Run("list services", func(t *testing.T) {
+		rec := do(http.MethodGet, "/services")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var services []map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &services))
+		require.Len(t, services, 1)
+		assert.Equal(t, "api", services[0]["name"])
+	})
+
+	t.Run("status", func(t *testing.T) {
+		rec := do(http.MethodGet, "/status")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"state":"running"`)
+	})
+
+	t.Run("unknown service", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/services/nope/stop").Code)
+	})
+
+	t.Run("stop and restart service", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do(http.MethodPost, "/services/api/stop").Code)
+		assert.Equal(t, int64(1), stopped.Load())
+		assert.Equal(t, controls.Stopped, c.Report().Services[0].State)
+
+		assert.Equal(t, http.StatusOK, do(http.MethodPost, "/services/api/restart").Code)
+		assert.Eventually(t, func() bool { return started.Load() == 2 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, controls.Running, c.Report().Services[0].State)
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		assert.Equal(t, http.StatusAccepted, do(http.MethodPost, "/shutdown").Code)
+		assert.Eventually(t, c.IsStopped, time.Second, 5*time.Millisecond)
+		assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/shutdown").Code)
+	})
+}
+
+func TestController_WithAdminAPI(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithAdminAPI("127.0.0.1:0"),
+	)
+	c.Register("svc",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+	c.Start()
+
+	addr := c.AdminAddr()
+	require.NotEmpty(t, addr)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+addr+"/status", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	c.Stop()
+	assert.Eventually(t, func() bool { return c.AdminAddr() == "" }, time.Second, 5*time.Millisecond)
+}
+
+func TestController_WithAdminAPIAddressInUse(t *testing.T) {
+	ln, err := (&net.ListenConfig{}).Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	var started atomic.Bool
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithAdminAPI(ln.Addr().String()),
+	)
+	c.Register("svc", controls.WithStart(func(_ context.Context) error {
+		started.Store(true)
+
+		return nil
+	}))
+
+	require.Error(t, c.Start())
+	assert.Equal(t, controls.Unknown, c.GetState())
+	assert.False(t, started.Load())
+	require.ErrorIs(t, c.Stop(), controls.ErrNotStarted)
+}
@@ -0,0 +1,133 @@
+package controls
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// The actions the admin API authorizes. Stop, restart, reload, maintenance
+// and shutdown actions match the commands recorded in the audit trail.
+const (
+	ActionServices         = "services"
+	ActionStatus           = "status"
+	ActionAudit            = "audit"
+	ActionGraph            = "graph"
+	ActionStopService      = "stop_service"
+	ActionRestartService   = "restart_service"
+	ActionReload           = "reload"
+	ActionEnterMaintenance = "enter_maintenance"
+	ActionExitMaintenance  = "exit_maintenance"
+	ActionShutdown         = "shutdown"
+)
+
+// AdminAuthorizer decides whether principal may perform action through the
+// admin API. principal is empty when no authentication is configured.
+type AdminAuthorizer func(action, principal string) bool
+
+// WithAdminToken lets requests carrying "Authorization: Bearer <token>" use
+// the admin API as principal. It can be given more than once. An empty
+// token, such as from an unset environment variable, is logged and ignored,
+// but still requires requests to authenticate.
+func WithAdminToken(principal, token string) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			if token == "" {
+				ctrl.adminAuth.emptyTokens = append(ctrl.adminAuth.emptyTokens, principal)
+
+				return
+			}
+
+			if ctrl.adminAuth.tokens == nil {
+				ctrl.adminAuth.tokens = map[string]string{}
+			}
+
+			ctrl.adminAuth.tokens[token] = principal
+		})
+	}
+}
+
+// WithAdminClientCerts lets requests presenting a verified TLS client
+// certificate use the admin API, as the certificate's subject common name.
+// The server must request and verify client certificates; see WithAdminTLS.
+func WithAdminClientCerts() ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.adminAuth.clientCerts = true
+		})
+	}
+}
+
+// WithAdminAuthorizer checks every authenticated request to the admin API
+// with fn, refusing those it rejects with 403 Forbidden.
+func WithAdminAuthorizer(fn AdminAuthorizer) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.adminAuth.authorize = fn
+		})
+	}
+}
+
+type adminAuth struct {
+	tokens      map[string]string
+	clientCerts bool
+	authorize   AdminAuthorizer
+	// emptyTokens names the principals given an empty token.
+	emptyTokens []string
+}
+
+type principalKey struct{}
+
+// authenticating reports whether requests must identify themselves.
+func (a *adminAuth) authenticating() bool {
+	return len(a.tokens) > 0 || len(a.emptyTokens) > 0 || a.clientCerts
+}
+
+// authenticate returns the principal making r, if it can be identified.
+func (a *adminAuth) authenticate(r *http.Request) (string, bool) {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && token != "" {
+		for known, principal := range a.tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(known)) == 1 {
+				return principal, true
+			}
+		}
+	}
+
+	if a.clientCerts && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		return r.TLS.VerifiedChains[0][0].Subject.CommonName, true
+	}
+
+	return "", false
+}
+
+// guard authenticates and authorizes each request for action before passing
+// it to h. Without any authentication or authorizer configured, every
+// request is allowed.
+func (c *Controller) guard(action string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var principal string
+
+		if c.adminAuth.authenticating() {
+			var ok bool
+			if principal, ok = c.adminAuth.authenticate(r); !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="controls"`)
+				writeJSON(w, http.StatusUnauthorized, adminResponse{Status: "error", Error: "unauthorized"})
+
+				return
+			}
+		}
+
+		if c.adminAuth.authorize != nil && !c.adminAuth.authorize(action, principal) {
+			writeJSON(w, http.StatusForbidden, adminResponse{Status: "error", Error: "forbidden"})
+
+			return
+		}
+
+		if principal != "" {
+			r = r.WithContext(context.WithValue(r.Context(), principalKey{}, principal))
+		}
+
+		h(w, r)
+	}
+}
@@ -0,0 +1,194 @@
+package controls_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAuthController(t *testing.T, opts ...controls.ControllerOpt) *controls.Controller {
+	t.Helper()
+
+	c := controls.NewController(context.Background(), append([]controls.ControllerOpt{
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	}, opts...)...)
+	c.Register("api",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	c.Start()
+	t.Cleanup(func() { _ = c.Stop() })
+
+	return c
+}
+
+func adminRequest(h http.Handler, method, path, token string) int {
+	req := httptest.NewRequestWithContext(context.Background(), method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	return rec.Code
+}
+
+func TestAdminAPI_Token(t *testing.T) {
+	c := newAuthController(t,
+		controls.WithAdminToken("ops", "ops-token"),
+		controls.WithAdminToken("viewer", "viewer-token"),
+		controls.WithAdminAuthorizer(func(action, principal string) bool {
+			return principal == "ops" || action == controls.ActionStatus
+		}),
+	)
+	h := c.AdminHandler()
+
+	assert.Equal(t, http.StatusUnauthorized, adminRequest(h, http.MethodGet, "/status", ""))
+	assert.Equal(t, http.StatusUnauthorized, adminRequest(h, http.MethodGet, "/status", "wrong"))
+	assert.Equal(t, http.StatusOK, adminRequest(h, http.MethodGet, "/status", "viewer-token"))
+	assert.Equal(t, http.StatusForbidden, adminRequest(h, http.MethodPost, "/services/api/restart", "viewer-token"))
+	assert.Equal(t, http.StatusOK, adminRequest(h, http.MethodPost, "/services/api/restart", "ops-token"))
+
+	assert.Equal(t, http.StatusOK, adminRequest(h, http.MethodGet, "/livez", ""), "probes stay open")
+	assert.Equal(t, http.StatusOK, adminRequest(h, http.MethodGet, "/health", ""))
+
+	log := c.AuditLog()
+	require.Len(t, log, 1, "refused requests never reach the controller")
+	assert.Equal(t, "ops", log[0].Actor)
+}
+
+func TestAdminAPI_AuthorizerWithoutAuthentication(t *testing.T) {
+	c := newAuthController(t, controls.WithAdminAuthorizer(func(action, principal string) bool {
+		assert.Empty(t, principal)
+
+		return action != controls.ActionShutdown
+	}))
+	h := c.AdminHandler()
+
+	assert.Equal(t, http.StatusOK, adminRequest(h, http.MethodGet, "/services", ""))
+	assert.Equal(t, http.StatusForbidden, adminRequest(h, http.MethodPost, "/shutdown", ""))
+}
+
+// newTestCA returns a pool holding a new CA, and a certificate the CA issued
+// for commonName that serves as either a server or a client certificate.
+func newTestCA(t *testing.T, commonName string) (*x509.CertPool, tls.Certificate) {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	return pool, tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestAdminAPI_ClientCerts(t *testing.T) {
+	var principals []string
+
+	c := newAuthController(t,
+		controls.WithAdminClientCerts(),
+		controls.WithAdminAuthorizer(func(_, principal string) bool {
+			principals = append(principals, principal)
+
+			return true
+		}),
+	)
+
+	pool, cert := newTestCA(t, "operator")
+
+	srv := httptest.NewUnstartedServer(c.AdminHandler())
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		MinVersion:   tls.VersionTLS12,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	get := func(certs ...tls.Certificate) int {
+		hc := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      pool,
+			Certificates: certs,
+			MinVersion:   tls.VersionTLS12,
+		}}}
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+"/status", nil)
+		require.NoError(t, err)
+
+		resp, err := hc.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, get())
+	assert.Equal(t, http.StatusOK, get(cert))
+	assert.Equal(t, []string{"operator"}, principals)
+}
+
+func TestAdminAPI_EmptyToken(t *testing.T) {
+	c := newAuthController(t, controls.WithAdminToken("ops", ""))
+	h := c.AdminHandler()
+
+	assert.Equal(t, http.StatusUnauthorized, adminRequest(h, http.MethodGet, "/status", ""))
+
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/status", nil)
+	req.Header.Set("Authorization", "Bearer ")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,131 @@
+package controls
+
+import (
+	"crypto/tls"
+	"errors"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// ErrNoAdminCertificate is returned by ReloadAdminCertificate when the admin
+// API wasn't given certificate files.
+var ErrNoAdminCertificate = errors.New("admin API has no certificate files")
+
+// WithAdminTLS serves the admin API, and so the health endpoints and probes
+// it carries, over TLS using a clone of cfg. When WithAdminClientCerts is set
+// and cfg has ClientCAs but doesn't ask for client certificates, verified
+// certificates are requested.
+func WithAdminTLS(cfg *tls.Config) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.adminTLS = cfg.Clone()
+		})
+	}
+}
+
+// WithAdminCertFiles serves the admin API over TLS with the PEM certificate
+// and key in certFile and keyFile, combined with any WithAdminTLS config.
+// The files are read again when the process receives SIGHUP, or on
+// ReloadAdminCertificate, so a renewed certificate is used without a restart.
+// Because it handles SIGHUP, the signal no longer terminates the process.
+func WithAdminCertFiles(certFile, keyFile string) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.adminCert = &certFiles{certFile: certFile, keyFile: keyFile}
+		})
+	}
+}
+
+// ReloadAdminCertificate reads the admin API's certificate files again. New
+// connections use the new certificate; if the files can't be loaded the old
+// one is kept.
+func (c *Controller) ReloadAdminCertificate() error {
+	if c.adminCert == nil {
+		return ErrNoAdminCertificate
+	}
+
+	if err := c.adminCert.load(); err != nil {
+		c.log().Error("Failed to reload admin certificate", "error", err)
+
+		return err
+	}
+
+	c.log().Info("Reloaded admin certificate", "cert", c.adminCert.certFile)
+
+	return nil
+}
+
+// certFiles holds a certificate loaded from files, swapped atomically when
+// they are read again.
+type certFiles struct {
+	certFile, keyFile string
+	cert              atomic.Pointer[tls.Certificate]
+}
+
+func (f *certFiles) load() error {
+	cert, err := tls.LoadX509KeyPair(f.certFile, f.keyFile)
+	if err != nil {
+		return err
+	}
+
+	f.cert.Store(&cert)
+
+	return nil
+}
+
+func (f *certFiles) get(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return f.cert.Load(), nil
+}
+
+// adminTLSConfig returns the TLS config for the admin API, or nil when it is
+// served in the clear.
+func (c *Controller) adminTLSConfig() (*tls.Config, error) {
+	if c.adminTLS == nil && c.adminCert == nil {
+		return nil, nil //nolint:nilnil // nil config means plain HTTP
+	}
+
+	cfg := c.adminTLS.Clone()
+	if cfg == nil {
+		cfg = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if c.adminCert != nil {
+		if err := c.adminCert.load(); err != nil {
+			return nil, err
+		}
+
+		cfg.GetCertificate = c.adminCert.get
+	}
+
+	if c.adminAuth.clientCerts && cfg.ClientCAs != nil && cfg.ClientAuth == tls.NoClientCert {
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return cfg, nil
+}
+
+// reloadAdminCertOnHangup reloads the admin API's certificate files on SIGHUP
+// while the controller runs, when it was configured with them.
+func (c *Controller) reloadAdminCertOnHangup() {
+	if c.adminCert == nil {
+		return
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	c.goBackground(func(done <-chan struct{}) {
+		defer signal.Stop(hup)
+
+		for {
+			select {
+			case <-hup:
+				_ = c.ReloadAdminCertificate()
+			case <-done:
+				return
+			}
+		}
+	})
+}
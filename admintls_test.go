@@ -0,0 +1,97 @@
+package controls_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCertFiles(t *testing.T, dir string, cert tls.Certificate) (string, string) {
+	t.Helper()
+
+	key, err := x509.MarshalECPrivateKey(cert.PrivateKey.(*ecdsa.PrivateKey))
+	require.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "admin.crt"), filepath.Join(dir, "admin.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: key}), 0o600))
+
+	return certFile, keyFile
+}
+
+func TestAdminAPI_CertFiles(t *testing.T) {
+	dir := t.TempDir()
+	pool, cert := newTestCA(t, "admin")
+	certFile, keyFile := writeCertFiles(t, dir, cert)
+
+	c := newAuthController(t,
+		controls.WithAdminAPI("127.0.0.1:0"),
+		controls.WithAdminCertFiles(certFile, keyFile),
+	)
+
+	ctx := context.Background()
+	status := func(pool *x509.CertPool) error {
+		_, err := client.New(c.AdminAddr(), client.WithTLSConfig(&tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		})).Status(ctx)
+
+		return err
+	}
+
+	require.NoError(t, status(pool))
+
+	renewedPool, renewed := newTestCA(t, "admin")
+	writeCertFiles(t, dir, renewed)
+	require.Error(t, status(renewedPool), "still serving the old certificate")
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	require.Eventually(t, func() bool { return status(renewedPool) == nil }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0o600))
+	require.Error(t, c.ReloadAdminCertificate())
+	require.NoError(t, status(renewedPool), "a failed reload keeps the current certificate")
+}
+
+func TestAdminAPI_TLSClientCerts(t *testing.T) {
+	pool, cert := newTestCA(t, "operator")
+
+	c := newAuthController(t,
+		controls.WithAdminAPI("127.0.0.1:0"),
+		controls.WithAdminTLS(&tls.Config{
+			Certificates: []tls.Certificate{cert},
+			ClientCAs:    pool,
+			MinVersion:   tls.VersionTLS12,
+		}),
+		controls.WithAdminClientCerts(),
+	)
+
+	ctx := context.Background()
+	cfg := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+
+	_, err := client.New(c.AdminAddr(), client.WithTLSConfig(cfg)).Status(ctx)
+	require.ErrorIs(t, err, client.ErrRequestFailed)
+	assert.Contains(t, err.Error(), "401")
+
+	cfg = cfg.Clone()
+	cfg.Certificates = []tls.Certificate{cert}
+
+	require.NoError(t, client.New(c.AdminAddr(), client.WithTLSConfig(cfg)).StopService(ctx, "api"))
+	assert.Equal(t, "operator", c.AuditLog()[0].Actor)
+
+	plain := controls.NewController(ctx, controls.WithLogger(discardLogger()), controls.WithoutSignals())
+	assert.ErrorIs(t, plain.ReloadAdminCertificate(), controls.ErrNoAdminCertificate)
+}
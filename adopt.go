@@ -0,0 +1,154 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrServiceExists is returned when a service name is already registered.
+var ErrServiceExists = errors.New("service already registered")
+
+// Adopt moves every service registered with other under this controller, along
+// with what other has recorded about them, leaving other empty. Adopted
+// services that aren't running are wrapped in this controller's middleware.
+// If this controller is already up, those that have never run are started
+// straight away.
+//
+// Services other is running keep running, and this controller stops them
+// from then on. They still run on the context other started them with, which
+// is cancelled when this controller begins stopping, as its own is. Stop this
+// controller rather than other: stopping other first cancels their contexts
+// while they are still registered here. Adopt returns
+// ErrServiceExists, and adopts nothing, if a service name is registered with
+// both controllers.
+func (c *Controller) Adopt(other *Controller) error {
+	if other == c {
+		return nil
+	}
+
+	adopted, live, err := c.services.adopt(&other.services, c.middleware)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range adopted {
+		c.attach(s.handle)
+	}
+
+	if live > 0 {
+		other.releaseWaitGroup(live)
+		c.addToWaitGroup(live)
+		c.adoptRun(other.cancelRun)
+	}
+
+	if c.isUp() {
+		c.addToWaitGroup(c.services.launchAll(c.runCtx, adopted))
+		c.assess()
+	}
+
+	if other.isUp() {
+		other.assess()
+	}
+
+	return nil
+}
+
+// adoptions serialises adopting, the only time two controllers' services are
+// locked together, so two controllers adopting each other can't deadlock.
+var adoptions sync.Mutex
+
+// adopt moves other's services to q, wrapping those that aren't running in
+// mw, and returns them along with how many of them are running.
+func (q *Services) adopt(other *Services, mw []Middleware) ([]*service, int, error) {
+	adoptions.Lock()
+	defer adoptions.Unlock()
+
+	q.ops.Lock()
+	defer q.ops.Unlock()
+
+	other.ops.Lock()
+	defer other.ops.Unlock()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	other.mu.Lock()
+	defer other.mu.Unlock()
+
+	for _, s := range other.services {
+		if slices.ContainsFunc(q.services, func(t *service) bool { return t.Name == s.Name }) {
+			return nil, 0, fmt.Errorf("%w: %s", ErrServiceExists, s.Name)
+		}
+	}
+
+	live := 0
+
+	for _, s := range other.services {
+		if s.live() {
+			live++
+		} else {
+			s.wrap(mw)
+		}
+
+		s.owner.Store(q)
+		other.unfinished.forget(s)
+		q.unfinished.track(s)
+	}
+
+	adopted := other.services
+	other.services = nil
+	q.services = append(q.services, adopted...)
+
+	return adopted, live, nil
+}
+
+// adoptRun records the cancel function of the run context of a controller
+// whose running services were adopted, for beginStopping to call.
+func (c *Controller) adoptRun(cancel context.CancelCauseFunc) {
+	c.adopted.mu.Lock()
+	defer c.adopted.mu.Unlock()
+
+	c.adopted.runs = append(c.adopted.runs, cancel)
+}
+
+// cancelAdopted cancels the run contexts adopted services were started with.
+func (c *Controller) cancelAdopted(cause error) {
+	c.adopted.mu.Lock()
+	defer c.adopted.mu.Unlock()
+
+	for _, cancel := range c.adopted.runs {
+		cancel(cause)
+	}
+}
+
+// launchAll launches those of services that have never run, other than
+// disabled ones, and returns how many it launched.
+func (q *Services) launchAll(ctx context.Context, services []*service) int {
+	q.ops.Lock()
+	defer q.ops.Unlock()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	launched := 0
+
+	for _, s := range services {
+		if s.state != Unknown {
+			continue
+		}
+
+		if s.disabled() {
+			q.setState(s, Disabled)
+
+			continue
+		}
+
+		q.launch(ctx, s, func() {})
+		launched++
+	}
+
+	return launched
+}
@@ -0,0 +1,126 @@
+package controls_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_Adopt(t *testing.T) {
+	newController := func() *controls.Controller {
+		return controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+		)
+	}
+
+	var stops atomic.Int64
+
+	register := func(c *controls.Controller, name string) {
+		c.Register(name,
+			controls.WithStart(noopStart),
+			controls.WithStop(func(_ context.Context) { stops.Add(1) }),
+		)
+	}
+
+	parent := newController()
+	register(parent, "api")
+
+	billing := newController()
+	register(billing, "invoices")
+
+	require.NoError(t, parent.Adopt(billing))
+	assert.Empty(t, billing.Report().Services)
+
+	parent.Start()
+	assert.Equal(t, controls.Running, parent.Report().Services[1].State)
+
+	late := newController()
+	register(late, "mailer")
+	require.NoError(t, parent.Adopt(late))
+	assert.Eventually(t, func() bool {
+		return len(parent.Report().Services) == 3 && parent.Report().Services[2].State == controls.Running
+	}, time.Second, time.Millisecond)
+
+	clash := newController()
+	register(clash, "api")
+	require.ErrorIs(t, parent.Adopt(clash), controls.ErrServiceExists)
+	assert.Len(t, clash.Report().Services, 1)
+
+	require.NoError(t, parent.Stop())
+	assert.Equal(t, int64(3), stops.Load())
+}
+
+func TestController_AdoptRunning(t *testing.T) {
+	newController := func() *controls.Controller {
+		return controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+		)
+	}
+
+	var starts, stops atomic.Int64
+
+	cause := make(chan error, 1)
+
+	child := newController()
+	worker := child.Register("worker",
+		controls.WithStart(func(ctx context.Context) error {
+			starts.Add(1)
+			controls.Ready(ctx)
+			<-ctx.Done()
+			cause <- context.Cause(ctx)
+
+			return ctx.Err()
+		}),
+		controls.WithStop(func(_ context.Context) { stops.Add(1) }),
+	)
+	require.NoError(t, child.Start())
+
+	parent := newController()
+	parent.Register("api", controls.WithStart(noopStart))
+	require.NoError(t, parent.Start())
+
+	require.NoError(t, parent.Adopt(child))
+	assert.Empty(t, child.Report().Services)
+	assert.Equal(t, controls.Running, worker.State())
+	assert.Equal(t, int64(1), starts.Load(), "a running service keeps running")
+
+	require.NoError(t, parent.Stop())
+	assert.Equal(t, int64(1), stops.Load())
+	assert.Equal(t, controls.Stopped, worker.State())
+	require.ErrorIs(t, <-cause, controls.ErrStopping)
+	assert.Equal(t, int64(1), starts.Load())
+
+	require.NoError(t, child.Stop())
+}
+
+func TestController_AdoptEachOther(t *testing.T) {
+	a := controls.NewController(context.Background(), controls.WithLogger(discardLogger()), controls.WithoutSignals())
+	a.Register("a", controls.WithStart(noopStart))
+
+	b := controls.NewController(context.Background(), controls.WithLogger(discardLogger()), controls.WithoutSignals())
+	b.Register("b", controls.WithStart(noopStart))
+
+	done := make(chan error, 2)
+
+	go func() { done <- a.Adopt(b) }()
+	go func() { done <- b.Adopt(a) }()
+
+	for range 2 {
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("adopting each other deadlocked")
+		}
+	}
+
+	assert.Len(t, append(a.Report().Services, b.Report().Services...), 2)
+}
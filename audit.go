@@ -0,0 +1,112 @@
+package controls
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultAuditHistory is how many entries AuditLog keeps.
+const DefaultAuditHistory = 256
+
+// AuditSource says where a control command came from.
+type AuditSource string
+
+const (
+	AuditSignal       AuditSource = "signal"
+	AuditAdmin        AuditSource = "admin"
+	AuditProgrammatic AuditSource = "programmatic"
+	// AuditRemote marks commands received through a RemoteTransport.
+	AuditRemote AuditSource = "remote"
+	// AuditController marks commands the controller issued itself, such as
+	// stopping when its context is cancelled or a service fails.
+	AuditController AuditSource = "controller"
+)
+
+// AuditEntry records a control command: a message processed by the control
+// loop, or an operation on the services such as StopService. Actor
+// identifies who issued it where that is known: the signal, or the admin
+// API's remote address. Detail adds context, such as the reason for a stop.
+type AuditEntry struct {
+	Time    time.Time   `json:"time"`
+	Source  AuditSource `json:"source"`
+	Actor   string      `json:"actor,omitempty"`
+	Command string      `json:"command"`
+	Service string      `json:"service,omitempty"`
+	Detail  string      `json:"detail,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// WithAuditHistory sets how many entries are kept for AuditLog. A size of
+// zero or less disables the audit trail.
+func WithAuditHistory(size int) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.auditLog.size = size
+		})
+	}
+}
+
+// AuditLog returns the most recent control commands, oldest first. Periodic
+// status checks the controller schedules for itself aren't recorded.
+func (c *Controller) AuditLog() []AuditEntry {
+	c.auditLog.mu.Lock()
+	defer c.auditLog.mu.Unlock()
+
+	return c.auditLog.entries.all()
+}
+
+// origin identifies who issued a command. The zero origin isn't audited.
+type origin struct {
+	source AuditSource
+	actor  string
+}
+
+var (
+	programmatic = origin{source: AuditProgrammatic}
+	internal     = origin{source: AuditController}
+)
+
+// command is a control message and who sent it.
+type command struct {
+	msg    Message
+	origin origin
+}
+
+type auditLog struct {
+	mu      sync.Mutex
+	size    int
+	entries ring[AuditEntry]
+}
+
+// audited runs fn and records it as cmd issued by o.
+func (c *Controller) audited(o origin, cmd, service, detail string, fn func() error) error {
+	err := fn()
+	c.audit(o, cmd, service, detail, err)
+
+	return err
+}
+
+// audit records command as issued by o, along with its outcome.
+func (c *Controller) audit(o origin, cmd, service, detail string, err error) {
+	if o.source == "" {
+		return
+	}
+
+	e := AuditEntry{
+		Time:    c.clock.Now(),
+		Source:  o.source,
+		Actor:   o.actor,
+		Command: cmd,
+		Service: service,
+		Detail:  detail,
+	}
+
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	c.auditLog.mu.Lock()
+	defer c.auditLog.mu.Unlock()
+
+	c.auditLog.entries.add(c.auditLog.size, e)
+}
@@ -0,0 +1,105 @@
+package controls_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_AuditLog(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithStatusInterval(time.Millisecond),
+	)
+	c.Register("api",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithLabels(map[string]string{"tier": "edge"}),
+	)
+
+	c.Start()
+
+	require.NoError(t, c.StopService("api"))
+	require.ErrorIs(t, c.RestartService("missing"), controls.ErrServiceNotFound)
+
+	h := c.AdminHandler()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/services/api/restart", nil)
+	req.RemoteAddr = "10.0.0.7:5000"
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	require.NoError(t, c.EnterMaintenance(map[string]string{"tier": "edge"}))
+	require.NoError(t, c.ExitMaintenance())
+
+	c.Messages() <- "flush"
+
+	require.NoError(t, c.Stop())
+
+	log := c.AuditLog()
+	require.Len(t, log, 7, "periodic status checks aren't recorded")
+
+	type entry struct {
+		source                    controls.AuditSource
+		actor, cmd, service, info string
+	}
+
+	got := make([]entry, len(log))
+	for i, e := range log {
+		got[i] = entry{e.Source, e.Actor, e.Command, e.Service, e.Detail}
+		assert.False(t, e.Time.IsZero())
+	}
+
+	assert.Equal(t, []entry{
+		{controls.AuditProgrammatic, "", "stop_service", "api", ""},
+		{controls.AuditProgrammatic, "", "restart_service", "missing", ""},
+		{controls.AuditAdmin, "10.0.0.7:5000", "restart_service", "api", ""},
+		{controls.AuditProgrammatic, "", "enter_maintenance", "", "tier=edge"},
+		{controls.AuditProgrammatic, "", "exit_maintenance", "", ""},
+		{controls.AuditProgrammatic, "", "flush", "", ""},
+		{controls.AuditProgrammatic, "", "stop", "", "requested"},
+	}, got)
+
+	assert.Contains(t, log[1].Error, "service not found")
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/audit", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var served []controls.AuditEntry
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&served))
+	assert.Len(t, served, 7)
+}
+
+func TestController_AuditLogSignal(t *testing.T) {
+	c := controls.NewController(context.Background(), controls.WithLogger(discardLogger()))
+	c.Register("api",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	c.Start()
+
+	var sig os.Signal = syscall.SIGTERM
+	c.Signals() <- sig
+
+	require.Eventually(t, c.IsStopped, time.Second, time.Millisecond)
+
+	log := c.AuditLog()
+	require.Len(t, log, 1)
+	assert.Equal(t, controls.AuditSignal, log[0].Source)
+	assert.Equal(t, "terminated", log[0].Actor)
+	assert.Equal(t, "stop", log[0].Command)
+	assert.Equal(t, "signal", log[0].Detail)
+}
@@ -0,0 +1,102 @@
+package controls
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidService is returned by ServiceBuilder.Build for a service that
+// can't be registered as configured.
+var ErrInvalidService = errors.New("invalid service")
+
+// ServiceBuilder assembles a Service step by step, as an alternative to a
+// long list of options, and checks it before it is registered.
+type ServiceBuilder struct {
+	s Service
+}
+
+// NewService starts building a service called name.
+func NewService(name string) *ServiceBuilder {
+	return &ServiceBuilder{s: Service{Name: name}}
+}
+
+// Start sets the start function; see WithStart.
+func (b *ServiceBuilder) Start(fn StartFunc) *ServiceBuilder {
+	return b.With(WithStart(fn))
+}
+
+// Stop sets the stop function; see WithStop.
+func (b *ServiceBuilder) Stop(fn StopFunc) *ServiceBuilder {
+	return b.With(WithStop(fn))
+}
+
+// StopErr sets a stop function that can fail; see WithStopErr.
+func (b *ServiceBuilder) StopErr(fn StopErrFunc) *ServiceBuilder {
+	return b.With(WithStopErr(fn))
+}
+
+// Status sets the status function; see WithStatus.
+func (b *ServiceBuilder) Status(fn StatusFunc) *ServiceBuilder {
+	return b.With(WithStatus(fn))
+}
+
+// HealthCheck sets the health check; see WithHealthCheck.
+func (b *ServiceBuilder) HealthCheck(fn HealthCheckFunc) *ServiceBuilder {
+	return b.With(WithHealthCheck(fn))
+}
+
+// DependsOn adds services to stop after this one; see WithDependsOn.
+func (b *ServiceBuilder) DependsOn(names ...string) *ServiceBuilder {
+	return b.With(WithDependsOn(names...))
+}
+
+// Labels adds labels; see WithLabels.
+func (b *ServiceBuilder) Labels(labels map[string]string) *ServiceBuilder {
+	return b.With(WithLabels(labels))
+}
+
+// With applies any other service options.
+func (b *ServiceBuilder) With(opts ...ServiceOption) *ServiceBuilder {
+	for _, opt := range opts {
+		opt(&b.s)
+	}
+
+	return b
+}
+
+// Build returns the service, or ErrInvalidService if it has no name, no
+// start function, more than one stop function or depends on itself.
+func (b *ServiceBuilder) Build() (Service, error) {
+	var problems []string
+
+	if b.s.Name == "" {
+		problems = append(problems, "no name")
+	}
+
+	if b.s.Start == nil {
+		problems = append(problems, "no start function")
+	}
+
+	if b.s.Stop != nil && b.s.StopErr != nil {
+		problems = append(problems, "both Stop and StopErr set")
+	}
+
+	if b.s.Name != "" && slices.Contains(b.s.DependsOn, b.s.Name) {
+		problems = append(problems, "depends on itself")
+	}
+
+	if len(problems) > 0 {
+		return Service{}, fmt.Errorf("%w %q: %s", ErrInvalidService, b.s.Name, strings.Join(problems, ", "))
+	}
+
+	return b.s, nil
+}
+
+// RegisterService registers a service built with NewService and returns its
+// handle. As with Register, a name that is already taken returns nil and makes
+// Start fail with ErrServiceExists.
+func (c *Controller) RegisterService(s Service) *ServiceHandle {
+	return c.register(s)
+}
@@ -0,0 +1,62 @@
+package controls_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceBuilder(t *testing.T) {
+	db, err := controls.NewService("db").
+		Start(noopStart).
+		Stop(func(_ context.Context) {}).
+		Build()
+	require.NoError(t, err)
+
+	api, err := controls.NewService("api").
+		Start(noopStart).
+		Stop(func(_ context.Context) {}).
+		Status(func() {}).
+		DependsOn("db").
+		Labels(map[string]string{"tier": "edge"}).
+		With(controls.WithPhase(1)).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db"}, api.DependsOn)
+	assert.Equal(t, 1, api.Phase)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	handle := c.RegisterService(api)
+	require.NotNil(t, handle)
+	c.RegisterService(db)
+
+	c.Start()
+	assert.Equal(t, 2, c.RunningCount())
+	assert.Equal(t, controls.Running, handle.State())
+	require.NoError(t, c.Stop())
+
+	assert.Nil(t, c.RegisterService(db), "the name is already taken")
+}
+
+func TestServiceBuilder_Invalid(t *testing.T) {
+	_, err := controls.NewService("").Build()
+	require.ErrorIs(t, err, controls.ErrInvalidService)
+	assert.Contains(t, err.Error(), "no name")
+	assert.Contains(t, err.Error(), "no start function")
+
+	_, err = controls.NewService("loop").
+		Start(noopStart).
+		Stop(func(_ context.Context) {}).
+		StopErr(func(_ context.Context) error { return nil }).
+		DependsOn("loop").
+		Build()
+	require.ErrorIs(t, err, controls.ErrInvalidService)
+	assert.EqualError(t, err, `invalid service "loop": both Stop and StopErr set, depends on itself`)
+}
@@ -0,0 +1,158 @@
+package controls
+
+import (
+	"context"
+	"os/signal"
+)
+
+// sendMessage sends msg from o to the control loop, reporting whether it was
+// sent. It gives up when cancel is closed or once the controller has stopped,
+// and never sends once the controller has closed its channels.
+func (c *Controller) sendMessage(msg Message, o origin, cancel <-chan struct{}) bool {
+	c.sending.RLock()
+	defer c.sending.RUnlock()
+
+	if c.channelsClosed {
+		return false
+	}
+
+	select {
+	case c.commands <- command{msg: msg, origin: o}:
+		return true
+	case <-cancel:
+		return false
+	case <-c.shutdown.done:
+		return false
+	}
+}
+
+// TrySend passes msg to the control loop only if it can take it straight
+// away, reporting whether it did. It never blocks, so a producer can skip or
+// retry a message while the loop is busy, say running a slow status check.
+func (c *Controller) TrySend(msg Message) bool {
+	c.sending.RLock()
+	defer c.sending.RUnlock()
+
+	if c.channelsClosed {
+		return false
+	}
+
+	select {
+	case c.commands <- command{msg: msg, origin: programmatic}:
+		return true
+	default:
+		return false
+	}
+}
+
+// SendContext passes msg to the control loop, waiting until it is taken. It
+// returns the cause of ctx if ctx is done first, or ErrNotRunning once the
+// controller has stopped.
+func (c *Controller) SendContext(ctx context.Context, msg Message) error {
+	if c.sendMessage(msg, programmatic, ctx.Done()) {
+		return nil
+	}
+
+	if err := context.Cause(ctx); err != nil {
+		return err
+	}
+
+	return ErrNotRunning
+}
+
+// Send passes msg to the control loop, waiting until it is taken. It returns
+// ErrNotRunning once the controller has stopped.
+func (c *Controller) Send(msg Message) error {
+	return c.SendContext(context.Background(), msg)
+}
+
+// ReportError passes err to the controller's error handler, as a service's
+// returned error would be. A nil err is ignored, as are errors reported once
+// the controller has stopped.
+func (c *Controller) ReportError(err error) {
+	if err == nil {
+		return
+	}
+
+	c.reportError(err)
+}
+
+// ReportHealth records h as the health of the service named by its
+// ServiceName, if one is registered, and publishes it on Health(). Like the
+// controller's own health messages it never blocks: h is not published when
+// the channel isn't ready for it.
+func (c *Controller) ReportHealth(h HealthMessage) {
+	if h.Timestamp.IsZero() {
+		h.Timestamp = c.clock.Now()
+	}
+
+	if h.ServiceName != "" {
+		c.RecordHealth(h.ServiceName, h)
+	}
+
+	c.publishHealth(h)
+}
+
+// reportError passes a service's error to the error handler. Errors reported
+// once the controller has stopped are dropped.
+func (c *Controller) reportError(err error) {
+	c.sending.RLock()
+	defer c.sending.RUnlock()
+
+	if c.channelsClosed {
+		return
+	}
+
+	select {
+	case c.errorChannel() <- err:
+	case <-c.shutdown.done:
+	}
+}
+
+// publishHealth passes the outcome of a status check to the health channel.
+// It never blocks: the message is dropped when the channel isn't ready for it,
+// or once the controller has stopped.
+func (c *Controller) publishHealth(h HealthMessage) {
+	c.sending.RLock()
+	defer c.sending.RUnlock()
+
+	if c.channelsClosed {
+		return
+	}
+
+	select {
+	case c.healthChannel() <- h:
+	default:
+	}
+}
+
+// closeChannels closes the Health channel the controller created itself, so
+// consumers ranging over it finish, and stops relaying signals. The
+// Messages() and Errors() channels are handed out for sending and are left
+// open, so a late send doesn't panic; the controller's guarded sends drop
+// once it has stopped. Channels supplied through the setters belong to the
+// caller and are left open. It is called once nothing reads the channels any
+// more.
+func (c *Controller) closeChannels() {
+	c.sending.Lock()
+	defer c.sending.Unlock()
+
+	if c.channelsClosed {
+		return
+	}
+
+	c.channelsClosed = true
+
+	c.channelsMutex.RLock()
+	defer c.channelsMutex.RUnlock()
+
+	c.unsubscribe()
+
+	if c.owned["signals"] && c.signals != nil {
+		signal.Stop(c.signals)
+	}
+
+	if c.owned["health"] {
+		close(c.health)
+	}
+}
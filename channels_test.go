@@ -0,0 +1,182 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_ClosesOwnedHealthChannel(t *testing.T) {
+	errLate := errors.New("late") //nolint:err113
+
+	release := make(chan struct{})
+	returned := make(chan struct{})
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+	)
+	c.Register("late",
+		controls.WithStart(func(ctx context.Context) error {
+			controls.Ready(ctx)
+			<-release
+			defer close(returned)
+
+			return errLate
+		}),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	c.Start()
+
+	ranged := make(chan struct{})
+
+	go func() {
+		for range c.Health() {
+		}
+
+		close(ranged)
+	}()
+
+	require.NoError(t, c.Close())
+
+	select {
+	case <-ranged:
+	case <-time.After(time.Second):
+		t.Fatal("ranging over Health did not finish")
+	}
+
+	require.ErrorIs(t, c.Send(controls.Status), controls.ErrNotRunning)
+	assert.NotPanics(t, func() { c.ReportError(errLate) })
+	assert.NotPanics(t, func() {
+		select {
+		case c.Messages() <- controls.Status:
+		default:
+		}
+
+		select {
+		case c.Errors() <- errLate:
+		default:
+		}
+	}, "the send-side channels stay open")
+
+	close(release)
+
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("a late service error blocked")
+	}
+}
+
+func TestController_LeavesExternalChannelsOpen(t *testing.T) {
+	health := make(chan controls.HealthMessage, 1)
+	errs := make(chan error, 1)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.SetHealthChannel(health)
+	c.SetErrorsChannel(errs)
+	c.Register("svc",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	c.Start()
+	require.NoError(t, c.Close())
+
+	assert.NotPanics(t, func() {
+		health <- controls.HealthMessage{}
+		errs <- errors.New("after close") //nolint:err113
+	})
+}
+
+func TestController_TrySendAndSendContext(t *testing.T) {
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("slow",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithStatus(func() {
+			entered <- struct{}{}
+			<-release
+		}),
+	)
+
+	assert.False(t, c.TrySend(controls.Status), "the control loop isn't running yet")
+
+	c.Start()
+
+	require.Eventually(t, func() bool { return c.TrySend(controls.Status) }, time.Second, time.Millisecond)
+	<-entered
+
+	assert.False(t, c.TrySend(controls.Reload), "the loop is busy with the status check")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, c.SendContext(ctx, controls.Reload), context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, c.SendContext(context.Background(), controls.Reload))
+
+	require.NoError(t, c.Stop())
+
+	assert.False(t, c.TrySend(controls.Reload))
+	require.ErrorIs(t, c.SendContext(context.Background(), controls.Reload), controls.ErrNotRunning)
+}
+
+func TestController_SendReportErrorAndReportHealth(t *testing.T) {
+	errBoom := errors.New("boom") //nolint:err113
+
+	handled := make(chan controls.ServiceError, 1)
+	reloads := make(chan controls.Message, 1)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithErrorHandler(func(err controls.ServiceError) { handled <- err }),
+	)
+	c.Register("api",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithMessageHandler(func(msg controls.Message) error {
+			reloads <- msg
+
+			return nil
+		}),
+	)
+	c.Start()
+
+	require.NoError(t, c.Send(controls.Reload))
+	assert.Equal(t, controls.Reload, <-reloads)
+
+	c.ReportError(nil)
+	c.ReportError(controls.ServiceError{Name: "api", Err: errBoom})
+	require.ErrorIs(t, <-handled, errBoom)
+
+	c.ReportHealth(controls.HealthMessage{ServiceName: "api", Status: controls.HealthDegraded, Message: "slow"})
+
+	h := <-c.Health()
+	assert.Equal(t, controls.HealthDegraded, h.Status)
+
+	last := c.Report().Services[0].LastHealth
+	require.NotNil(t, last)
+	assert.Equal(t, "slow", last.Message)
+	assert.Equal(t, h.Timestamp, last.Timestamp)
+
+	require.NoError(t, c.Stop())
+	require.ErrorIs(t, c.Send(controls.Reload), controls.ErrNotRunning)
+}
@@ -0,0 +1,60 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+)
+
+// cleanups holds functions releasing resources that aren't services.
+type cleanups struct {
+	mu  sync.Mutex
+	fns []func(context.Context) error
+}
+
+// RegisterCleanup adds fn to release a resource that isn't a service, such as
+// a temporary directory or a lock file. Cleanups run once every service has
+// stopped, after the OnAfterStop hooks, last registered first. Their errors
+// are joined and returned from Stop.
+func (c *Controller) RegisterCleanup(fn func(context.Context) error) {
+	c.cleanups.mu.Lock()
+	defer c.cleanups.mu.Unlock()
+
+	c.cleanups.fns = append(c.cleanups.fns, fn)
+}
+
+// RegisterCloser registers a cleanup closing closer, such as a database or
+// message queue client.
+func (c *Controller) RegisterCloser(closer io.Closer) {
+	c.RegisterCleanup(func(_ context.Context) error {
+		return closer.Close()
+	})
+}
+
+// cleanUpWithin runs the cleanups under the shutdown timeout.
+func (c *Controller) cleanUpWithin() error {
+	ctx, cancel := c.withTimeout(context.Background(), c.shutdownTimeout)
+	defer cancel()
+
+	return c.cleanUp(ctx)
+}
+
+// cleanUp runs the cleanups in reverse order of registration, each once.
+func (c *Controller) cleanUp(ctx context.Context) error {
+	c.cleanups.mu.Lock()
+	fns := c.cleanups.fns
+	c.cleanups.fns = nil
+	c.cleanups.mu.Unlock()
+
+	var errs []error
+
+	for _, fn := range slices.Backward(fns) {
+		if err := fn(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
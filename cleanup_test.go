@@ -0,0 +1,83 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+func TestController_RegisterCleanup(t *testing.T) {
+	errLock := errors.New("lock file busy")        //nolint:err113
+	errClient := errors.New("client close failed") //nolint:err113
+
+	var order []string
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("api",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) { order = append(order, "api") }),
+	)
+
+	c.RegisterCleanup(func(ctx context.Context) error {
+		require.NoError(t, ctx.Err())
+		order = append(order, "tmpdir")
+
+		return nil
+	})
+	c.RegisterCleanup(func(_ context.Context) error {
+		order = append(order, "lock")
+
+		return errLock
+	})
+	c.RegisterCloser(closerFunc(func() error {
+		order = append(order, "client")
+
+		return errClient
+	}))
+	c.OnAfterStop(func(_ context.Context) error {
+		order = append(order, "after stop")
+
+		return nil
+	})
+
+	require.NoError(t, c.Start())
+
+	err := c.Stop()
+	require.ErrorIs(t, err, errLock)
+	require.ErrorIs(t, err, errClient)
+	assert.Equal(t, []string{"api", "after stop", "client", "lock", "tmpdir"}, order)
+
+	assert.Equal(t, err, c.Close())
+	assert.Len(t, order, 5, "cleanups run once")
+}
+
+func TestController_CleanupWhenNeverStarted(t *testing.T) {
+	closed := false
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.RegisterCloser(closerFunc(func() error {
+		closed = true
+
+		return nil
+	}))
+
+	require.NoError(t, c.Close())
+	assert.True(t, closed)
+}
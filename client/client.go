@@ -0,0 +1,278 @@
+// Package client talks to the admin API served by a controls.Controller
+// configured with controls.WithAdminAPI.
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTimeout = 10 * time.Second
+	unixHost       = "controls"
+)
+
+// ErrRequestFailed is returned when the admin API responds with an error status.
+var ErrRequestFailed = errors.New("admin request failed")
+
+type Option func(*Client)
+
+// Client issues commands against a running controller's admin API.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	token   string
+	tls     *tls.Config
+}
+
+// Status mirrors the JSON form of controls.Report.
+type Status struct {
+	Name        string          `json:"name,omitempty"`
+	State       string          `json:"state"`
+	Maintenance bool            `json:"maintenance,omitempty"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	Uptime      string          `json:"uptime"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Services    []ServiceStatus `json:"services"`
+}
+
+// ServiceStatus mirrors the JSON form of controls.ServiceReport.
+type ServiceStatus struct {
+	Name           string            `json:"name"`
+	State          string            `json:"state"`
+	StartedAt      *time.Time        `json:"started_at,omitempty"`
+	Uptime         string            `json:"uptime"`
+	Restarts       int               `json:"restarts"`
+	Ready          bool              `json:"ready"`
+	HealthFailures int               `json:"health_failures"`
+	Stalled        bool              `json:"stalled,omitempty"`
+	TotalUptime    string            `json:"total_uptime"`
+	LastRestart    *time.Time        `json:"last_restart,omitempty"`
+	StartDuration  string            `json:"start_duration"`
+	StopDuration   string            `json:"stop_duration"`
+	LastError      string            `json:"last_error,omitempty"`
+	LastHealth     *Health           `json:"last_health,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Details        map[string]string `json:"details,omitempty"`
+}
+
+// Health mirrors controls.HealthMessage.
+type Health struct {
+	ServiceName string    `json:"service_name,omitempty"`
+	Host        string    `json:"host"`
+	Port        int       `json:"port"`
+	Status      string    `json:"status"`
+	Message     string    `json:"message"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// AuditEntry mirrors controls.AuditEntry.
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Actor   string    `json:"actor,omitempty"`
+	Command string    `json:"command"`
+	Service string    `json:"service,omitempty"`
+	Detail  string    `json:"detail,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+type response struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// WithHTTPClient replaces the http.Client used for requests.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.http = hc
+	}
+}
+
+// WithToken sends token as a bearer token with every request, for an admin
+// API protected by controls.WithAdminToken.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithTLSConfig connects with cfg, for an admin API served over TLS. A bare
+// host:port address is then reached over https.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Client) {
+		c.tls = cfg
+	}
+}
+
+// New returns a client for the admin API at addr. addr may be a host:port, a
+// full http(s) URL or "unix:/path/to.sock" for an admin API on a unix socket.
+func New(addr string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: "http://" + addr,
+		http:    &http.Client{Timeout: DefaultTimeout},
+	}
+
+	switch {
+	case strings.HasPrefix(addr, "http://"), strings.HasPrefix(addr, "https://"):
+		c.baseURL = strings.TrimSuffix(addr, "/")
+	case strings.HasPrefix(addr, "unix:"):
+		path := strings.TrimPrefix(addr, "unix:")
+		c.baseURL = "http://" + unixHost
+		c.http.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		}
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.tls != nil {
+		c.useTLS(addr)
+	}
+
+	return c
+}
+
+// useTLS switches a bare address to https and connects with the TLS config,
+// on a copy of the http.Client so one passed to WithHTTPClient isn't changed.
+func (c *Client) useTLS(addr string) {
+	if !strings.Contains(addr, "://") && !strings.HasPrefix(addr, "unix:") {
+		c.baseURL = "https://" + addr
+	}
+
+	hc := *c.http
+
+	switch t := hc.Transport.(type) {
+	case nil:
+		hc.Transport = &http.Transport{TLSClientConfig: c.tls}
+	case *http.Transport:
+		t = t.Clone()
+		t.TLSClientConfig = c.tls
+		hc.Transport = t
+	}
+
+	c.http = &hc
+}
+
+// Status returns the controller's full status report.
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	status := &Status{}
+	if err := c.do(ctx, http.MethodGet, "/status", status); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// Services lists the registered services.
+func (c *Client) Services(ctx context.Context) ([]ServiceStatus, error) {
+	var services []ServiceStatus
+	if err := c.do(ctx, http.MethodGet, "/services", &services); err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+// Audit returns the controller's audit trail of control commands, oldest
+// first.
+func (c *Client) Audit(ctx context.Context) ([]AuditEntry, error) {
+	var entries []AuditEntry
+	if err := c.do(ctx, http.MethodGet, "/audit", &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// StopService stops a single service.
+func (c *Client) StopService(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPost, "/services/"+url.PathEscape(name)+"/stop", nil)
+}
+
+// RestartService restarts a single service.
+func (c *Client) RestartService(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPost, "/services/"+url.PathEscape(name)+"/restart", nil)
+}
+
+// Reload asks the controller to send a reload message to its services.
+func (c *Client) Reload(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/reload", nil)
+}
+
+// EnterMaintenance puts the controller into maintenance, pausing the
+// services whose labels match selector. An empty selector pauses nothing.
+func (c *Client) EnterMaintenance(ctx context.Context, selector map[string]string) error {
+	path := "/maintenance"
+
+	if len(selector) > 0 {
+		q := url.Values{}
+		for k, v := range selector {
+			q.Add("label", k+"="+v)
+		}
+
+		path += "?" + q.Encode()
+	}
+
+	return c.do(ctx, http.MethodPost, path, nil)
+}
+
+// ExitMaintenance takes the controller out of maintenance.
+func (c *Client) ExitMaintenance(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/maintenance", nil)
+}
+
+// Shutdown asks the controller to stop gracefully.
+func (c *Client) Shutdown(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/shutdown", nil)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		r := response{}
+		if json.Unmarshal(body, &r) == nil && r.Error != "" {
+			return fmt.Errorf("%w: %s: %s", ErrRequestFailed, resp.Status, r.Error)
+		}
+
+		return fmt.Errorf("%w: %s", ErrRequestFailed, resp.Status)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.Unmarshal(body, out)
+}
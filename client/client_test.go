@@ -0,0 +1,125 @@
+package client_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newController(t *testing.T, opts ...controls.ControllerOpt) (*controls.Controller, *atomic.Int64) {
+	t.Helper()
+
+	var reloads atomic.Int64
+
+	opts = append([]controls.ControllerOpt{
+		controls.WithLogger(slog.New(slog.DiscardHandler)),
+		controls.WithoutSignals(),
+	}, opts...)
+
+	c := controls.NewController(context.Background(), opts...)
+	c.Register("api",
+		controls.WithStart(func(_ context.Context) error { return nil }),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithMessageHandler(func(msg controls.Message) error {
+			if msg == controls.Reload {
+				reloads.Add(1)
+			}
+
+			return nil
+		}),
+	)
+	c.Start()
+
+	return c, &reloads
+}
+
+func TestClient(t *testing.T) {
+	c, reloads := newController(t)
+
+	srv := httptest.NewServer(c.AdminHandler())
+	defer srv.Close()
+
+	cl := client.New(srv.URL)
+	ctx := context.Background()
+
+	status, err := cl.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "running", status.State)
+	require.Len(t, status.Services, 1)
+	assert.Equal(t, "api", status.Services[0].Name)
+
+	require.NoError(t, cl.StopService(ctx, "api"))
+
+	services, err := cl.Services(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "stopped", services[0].State)
+
+	require.NoError(t, cl.RestartService(ctx, "api"))
+
+	err = cl.StopService(ctx, "missing")
+	require.ErrorIs(t, err, client.ErrRequestFailed)
+	assert.Contains(t, err.Error(), "service not found")
+
+	audit, err := cl.Audit(ctx)
+	require.NoError(t, err)
+	require.Len(t, audit, 3)
+	assert.Equal(t, "admin", audit[0].Source)
+	assert.Equal(t, "stop_service", audit[0].Command)
+	assert.Equal(t, "api", audit[0].Service)
+	assert.Equal(t, "missing", audit[2].Service)
+	assert.Contains(t, audit[2].Error, "service not found")
+
+	require.NoError(t, cl.Reload(ctx))
+	assert.Eventually(t, func() bool { return reloads.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, cl.EnterMaintenance(ctx, map[string]string{"tier": "none"}))
+
+	status, err = cl.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, status.Maintenance)
+	assert.Equal(t, "running", status.Services[0].State, "no service matched the selector")
+
+	require.NoError(t, cl.ExitMaintenance(ctx))
+
+	require.NoError(t, cl.Shutdown(ctx))
+	assert.Eventually(t, c.IsStopped, time.Second, 5*time.Millisecond)
+}
+
+func TestClient_UnixSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "admin.sock")
+	c, _ := newController(t, controls.WithAdminAPI("unix:"+sock))
+
+	status, err := client.New("unix:" + sock).Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "running", status.State)
+
+	c.Stop()
+}
+
+func TestClient_WithToken(t *testing.T) {
+	c, _ := newController(t, controls.WithAdminToken("ops", "s3cret"))
+	defer c.Stop()
+
+	srv := httptest.NewServer(c.AdminHandler())
+	defer srv.Close()
+
+	ctx := context.Background()
+
+	_, err := client.New(srv.URL).Status(ctx)
+	require.ErrorIs(t, err, client.ErrRequestFailed)
+	assert.Contains(t, err.Error(), "401")
+
+	status, err := client.New(srv.URL, client.WithToken("s3cret")).Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "running", status.State)
+}
@@ -0,0 +1,85 @@
+package controls
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time for timeouts, intervals and backoff. Tests can
+// supply their own implementation with WithClock to avoid real sleeps.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker is the subset of *time.Ticker used by the controller.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// WithClock replaces the wall clock used by the controller.
+func WithClock(clock Clock) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.clock = clock
+		})
+	}
+}
+
+type clockKey struct{}
+
+// clockFrom returns the controller's clock from a service's context, or the
+// wall clock outside one.
+func clockFrom(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return clock
+	}
+
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// withTimeout behaves like context.WithTimeout but measures d with the
+// controller's clock. With a custom clock the expiry is reported through
+// context.Cause as context.DeadlineExceeded.
+func (c *Controller) withTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := c.clock.(realClock); ok {
+		return context.WithTimeout(parent, d)
+	}
+
+	ctx, cancel := context.WithCancelCause(parent)
+	expired := c.clock.After(d)
+
+	go func() {
+		select {
+		case <-expired:
+			cancel(context.DeadlineExceeded)
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() { cancel(context.Canceled) }
+}
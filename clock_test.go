@@ -0,0 +1,56 @@
+package controls_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/controlstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// epoch is when the fake clocks in these tests start.
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestController_WithClock(t *testing.T) {
+	clock := controlstest.NewFakeClock(epoch)
+	stopped := make(chan error, 1)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithClock(clock),
+		controls.WithShutdownTimeout(time.Hour),
+	)
+	c.Register("slow",
+		controls.WithStart(func(_ context.Context) error { return nil }),
+		controls.WithStop(func(ctx context.Context) {
+			<-ctx.Done()
+			stopped <- context.Cause(ctx)
+		}),
+	)
+
+	c.Start()
+	assert.Equal(t, clock.Now(), c.Report().StartedAt)
+
+	clock.Advance(5 * time.Minute)
+	assert.Equal(t, 5*time.Minute, c.Report().Uptime)
+	assert.Equal(t, 5*time.Minute, c.Report().Services[0].Uptime)
+
+	go c.Stop()
+
+	require.Eventually(t, func() bool { return clock.Waiters() > 0 }, time.Second, time.Millisecond)
+	clock.Advance(time.Hour)
+
+	select {
+	case err := <-stopped:
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("shutdown timeout did not use the supplied clock")
+	}
+
+	assert.Eventually(t, c.IsStopped, time.Second, time.Millisecond)
+}
@@ -0,0 +1,69 @@
+package controls_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_Close(t *testing.T) {
+	t.Run("control goroutines exit", func(t *testing.T) {
+		c := controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithStatusInterval(time.Millisecond),
+		)
+
+		// measured after NewController, whose signal.Notify starts the
+		// runtime's process-wide signal goroutine
+		before := runtime.NumGoroutine()
+		c.Register("svc",
+			controls.WithStart(noopStart),
+			controls.WithStop(func(_ context.Context) {}),
+		)
+
+		c.Start()
+		require.NoError(t, c.Close())
+
+		assert.True(t, c.IsStopped())
+		// polled by hand, as Eventually runs its condition on another goroutine
+		for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > before && time.Now().Before(deadline); {
+			time.Sleep(time.Millisecond)
+		}
+
+		assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+	})
+
+	t.Run("never started", func(t *testing.T) {
+		c := controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+		)
+
+		require.NoError(t, c.Close())
+		assert.True(t, c.IsStopped())
+
+		require.ErrorIs(t, c.Start(), controls.ErrAlreadyRunning)
+		assert.True(t, c.IsStopped(), "a closed controller can't be started")
+	})
+
+	t.Run("close after stop", func(t *testing.T) {
+		c := controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+		)
+		c.Register("svc",
+			controls.WithStart(noopStart),
+			controls.WithStop(func(_ context.Context) {}),
+		)
+
+		c.Start()
+		require.NoError(t, c.Stop())
+		require.NoError(t, c.Close())
+	})
+}
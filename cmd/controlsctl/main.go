@@ -0,0 +1,218 @@
+// Command controlsctl issues commands against the admin API of a running
+// controls.Controller.
+//
+//	controlsctl [-addr host:port|unix:/path] status|services|audit|stop NAME|restart NAME|reload|shutdown
+//
+// It exits 0 on success, 1 when the command fails and 2 when it is misused.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/phpboyscout/controls/client"
+)
+
+const defaultAddr = "127.0.0.1:8081"
+
+var (
+	errUsage          = errors.New("usage")
+	errNoCertificates = errors.New("no certificates found")
+)
+
+const (
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+func main() {
+	os.Exit(execute(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// execute runs the command and returns the process's exit code, reporting
+// any failure on errOut.
+func execute(ctx context.Context, args []string, out, errOut io.Writer) int {
+	err := run(ctx, args, out, errOut)
+
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage):
+		return exitUsage
+	default:
+		fmt.Fprintln(errOut, "controlsctl:", err)
+
+		return exitFailure
+	}
+}
+
+func run(ctx context.Context, args []string, out, errOut io.Writer) error {
+	fs := flag.NewFlagSet("controlsctl", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	addr := fs.String("addr", envOr("CONTROLSCTL_ADDR", defaultAddr), "admin API address (host:port, URL or unix:/path)")
+	token := fs.String("token", os.Getenv("CONTROLSCTL_TOKEN"), "admin API bearer token")
+	caCert := fs.String("cacert", "", "PEM CA certificate to verify a TLS admin API with")
+	cert := fs.String("cert", "", "PEM client certificate for a TLS admin API")
+	key := fs.String("key", "", "PEM client key for a TLS admin API")
+	asJSON := fs.Bool("json", false, "print raw JSON")
+
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: controlsctl [flags] status|services|audit|stop NAME|restart NAME|reload|shutdown")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+
+		return errUsage
+	}
+
+	opts := []client.Option{client.WithToken(*token)}
+
+	if *caCert != "" || *cert != "" {
+		cfg, err := tlsConfig(*caCert, *cert, *key)
+		if err != nil {
+			return err
+		}
+
+		opts = append(opts, client.WithTLSConfig(cfg))
+	}
+
+	c := client.New(*addr, opts...)
+
+	switch cmd := fs.Arg(0); cmd {
+	case "status":
+		status, err := c.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		if *asJSON {
+			return printJSON(out, status)
+		}
+
+		fmt.Fprintf(out, "state: %s\nuptime: %s\n\n", status.State, status.Uptime)
+
+		return printServices(out, status.Services)
+	case "services":
+		services, err := c.Services(ctx)
+		if err != nil {
+			return err
+		}
+
+		if *asJSON {
+			return printJSON(out, services)
+		}
+
+		return printServices(out, services)
+	case "audit":
+		entries, err := c.Audit(ctx)
+		if err != nil {
+			return err
+		}
+
+		if *asJSON {
+			return printJSON(out, entries)
+		}
+
+		return printAudit(out, entries)
+	case "stop", "restart":
+		if fs.NArg() != 2 { //nolint:mnd
+			fs.Usage()
+
+			return errUsage
+		}
+
+		if cmd == "stop" {
+			return c.StopService(ctx, fs.Arg(1))
+		}
+
+		return c.RestartService(ctx, fs.Arg(1))
+	case "reload":
+		return c.Reload(ctx)
+	case "shutdown":
+		return c.Shutdown(ctx)
+	default:
+		fs.Usage()
+
+		return errUsage
+	}
+}
+
+func tlsConfig(caCert, cert, key string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caCert != "" {
+		pem, err := os.ReadFile(caCert)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s: %w", caCert, errNoCertificates)
+		}
+	}
+
+	if cert != "" {
+		pair, err := tls.LoadX509KeyPair(cert, key)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+
+	return cfg, nil
+}
+
+func printServices(out io.Writer, services []client.ServiceStatus) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0) //nolint:mnd
+	fmt.Fprintln(w, "NAME\tSTATE\tUPTIME\tLAST ERROR")
+
+	for _, s := range services {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, s.State, s.Uptime, s.LastError)
+	}
+
+	return w.Flush()
+}
+
+func printAudit(out io.Writer, entries []client.AuditEntry) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0) //nolint:mnd
+	fmt.Fprintln(w, "TIME\tSOURCE\tACTOR\tCOMMAND\tSERVICE\tERROR")
+
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Time.Format(time.RFC3339), e.Source, e.Actor, e.Command, e.Service, e.Error)
+	}
+
+	return w.Flush()
+}
+
+func printJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+func envOr(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+
+	return fallback
+}
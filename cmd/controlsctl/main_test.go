@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAdmin serves the admin API of a running controller with one service,
+// "api", counting the Reload messages it receives.
+func newAdmin(t *testing.T, opts ...controls.ControllerOpt) (*controls.Controller, *httptest.Server, *atomic.Int64) {
+	t.Helper()
+
+	var reloads atomic.Int64
+
+	c := controls.NewController(context.Background(), append([]controls.ControllerOpt{
+		controls.WithLogger(slog.New(slog.DiscardHandler)),
+		controls.WithoutSignals(),
+	}, opts...)...)
+	c.Register("api",
+		controls.WithStart(func(_ context.Context) error { return nil }),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithMessageHandler(func(msg controls.Message) error {
+			if msg == controls.Reload {
+				reloads.Add(1)
+			}
+
+			return nil
+		}),
+	)
+	require.NoError(t, c.Start())
+
+	srv := httptest.NewServer(c.AdminHandler())
+
+	t.Cleanup(func() {
+		srv.Close()
+		_ = c.Close()
+	})
+
+	return c, srv, &reloads
+}
+
+func TestRun(t *testing.T) {
+	t.Setenv("CONTROLSCTL_ADDR", "")
+	t.Setenv("CONTROLSCTL_TOKEN", "")
+
+	tests := []struct {
+		name    string
+		args    []string
+		code    int
+		out     []string
+		errOut  string
+		prepare func(t *testing.T, c *controls.Controller)
+		check   func(t *testing.T, c *controls.Controller, reloads *atomic.Int64)
+	}{
+		{
+			name: "status",
+			args: []string{"status"},
+			out:  []string{"state: running", "NAME", "api"},
+		},
+		{
+			name: "status as json",
+			args: []string{"-json", "status"},
+			out:  []string{`"state": "running"`, `"name": "api"`},
+		},
+		{
+			name: "services",
+			args: []string{"services"},
+			out:  []string{"NAME", "STATE", "api", "running"},
+		},
+		{
+			name: "services as json",
+			args: []string{"-json", "services"},
+			out:  []string{`"name": "api"`},
+		},
+		{
+			name: "audit",
+			args: []string{"audit"},
+			out:  []string{"COMMAND", "stop_service", "api"},
+			prepare: func(t *testing.T, c *controls.Controller) {
+				t.Helper()
+				require.NoError(t, c.StopService("api"))
+			},
+		},
+		{
+			name: "audit as json",
+			args: []string{"-json", "audit"},
+			out:  []string{`"command": "stop_service"`},
+			prepare: func(t *testing.T, c *controls.Controller) {
+				t.Helper()
+				require.NoError(t, c.StopService("api"))
+			},
+		},
+		{
+			name: "stop",
+			args: []string{"stop", "api"},
+			check: func(t *testing.T, c *controls.Controller, _ *atomic.Int64) {
+				t.Helper()
+				assert.Equal(t, controls.Stopped, c.Report().Services[0].State)
+			},
+		},
+		{
+			name: "restart",
+			args: []string{"restart", "api"},
+			prepare: func(t *testing.T, c *controls.Controller) {
+				t.Helper()
+				require.NoError(t, c.StopService("api"))
+			},
+			check: func(t *testing.T, c *controls.Controller, _ *atomic.Int64) {
+				t.Helper()
+				assert.Equal(t, controls.Running, c.Report().Services[0].State)
+			},
+		},
+		{
+			name: "reload",
+			args: []string{"reload"},
+			check: func(t *testing.T, _ *controls.Controller, reloads *atomic.Int64) {
+				t.Helper()
+				assert.Eventually(t, func() bool { return reloads.Load() == 1 }, time.Second, 5*time.Millisecond)
+			},
+		},
+		{
+			name: "shutdown",
+			args: []string{"shutdown"},
+			check: func(t *testing.T, c *controls.Controller, _ *atomic.Int64) {
+				t.Helper()
+				assert.Eventually(t, c.IsStopped, time.Second, 5*time.Millisecond)
+			},
+		},
+		{
+			name:   "stop unknown service",
+			args:   []string{"stop", "missing"},
+			code:   exitFailure,
+			errOut: "controlsctl: admin request failed: 404 Not Found: service not found: missing",
+		},
+		{
+			name:   "restart unknown service",
+			args:   []string{"restart", "missing"},
+			code:   exitFailure,
+			errOut: "service not found",
+		},
+		{
+			name:   "shutdown twice",
+			args:   []string{"shutdown"},
+			code:   exitFailure,
+			errOut: "409 Conflict",
+			prepare: func(t *testing.T, c *controls.Controller) {
+				t.Helper()
+				require.NoError(t, c.Stop())
+			},
+		},
+		{
+			name:   "no command",
+			code:   exitUsage,
+			errOut: "usage: controlsctl",
+		},
+		{
+			name:   "unknown command",
+			args:   []string{"frobnicate"},
+			code:   exitUsage,
+			errOut: "usage: controlsctl",
+		},
+		{
+			name:   "stop without a name",
+			args:   []string{"stop"},
+			code:   exitUsage,
+			errOut: "usage: controlsctl",
+		},
+		{
+			name:   "restart with extra arguments",
+			args:   []string{"restart", "api", "web"},
+			code:   exitUsage,
+			errOut: "usage: controlsctl",
+		},
+		{
+			name:   "unknown flag",
+			args:   []string{"-verbose", "status"},
+			code:   exitUsage,
+			errOut: "flag provided but not defined: -verbose",
+		},
+		{
+			name:   "missing CA certificate",
+			args:   []string{"-cacert", filepath.Join(t.TempDir(), "missing.pem"), "status"},
+			code:   exitFailure,
+			errOut: "missing.pem",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, srv, reloads := newAdmin(t)
+
+			if tt.prepare != nil {
+				tt.prepare(t, c)
+			}
+
+			var out, errOut bytes.Buffer
+
+			code := execute(context.Background(), append([]string{"-addr", srv.URL}, tt.args...), &out, &errOut)
+			assert.Equal(t, tt.code, code, errOut.String())
+
+			for _, want := range tt.out {
+				assert.Contains(t, out.String(), want)
+			}
+
+			if tt.errOut == "" {
+				assert.Empty(t, errOut.String())
+			} else {
+				assert.Contains(t, errOut.String(), tt.errOut)
+			}
+
+			if tt.check != nil {
+				tt.check(t, c, reloads)
+			}
+		})
+	}
+}
+
+func TestRun_Token(t *testing.T) {
+	_, srv, _ := newAdmin(t, controls.WithAdminToken("ops", "s3cret"))
+
+	tests := []struct {
+		name   string
+		env    string
+		args   []string
+		code   int
+		errOut string
+	}{
+		{name: "missing", code: exitFailure, errOut: "401 Unauthorized"},
+		{name: "wrong", args: []string{"-token", "guess"}, code: exitFailure, errOut: "401 Unauthorized"},
+		{name: "flag", args: []string{"-token", "s3cret"}},
+		{name: "environment", env: "s3cret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CONTROLSCTL_TOKEN", tt.env)
+			t.Setenv("CONTROLSCTL_ADDR", srv.URL)
+
+			var out, errOut bytes.Buffer
+
+			code := execute(context.Background(), append(tt.args, "status"), &out, &errOut)
+			assert.Equal(t, tt.code, code, errOut.String())
+
+			if tt.errOut != "" {
+				assert.Contains(t, errOut.String(), tt.errOut)
+			} else {
+				assert.Contains(t, out.String(), "state: running")
+			}
+		})
+	}
+}
+
+func TestRun_Unreachable(t *testing.T) {
+	srv := httptest.NewServer(nil)
+	addr := srv.URL
+	srv.Close()
+
+	var out, errOut bytes.Buffer
+
+	assert.Equal(t, exitFailure, execute(context.Background(), []string{"-addr", addr, "status"}, &out, &errOut))
+	assert.Contains(t, errOut.String(), "controlsctl: ")
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,112 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// ExitError carries the exit code a command should terminate with.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit %d: %v", e.Code, e.Err)
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code for an error returned by RunCommand, or by a
+// command that called it: 0 for nil, the code of an ExitError, and 1 for
+// anything else.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exit *ExitError
+	if errors.As(err, &exit) {
+		return exit.Code
+	}
+
+	return 1
+}
+
+// CommandFlags holds the settings a daemon command exposes as flags.
+type CommandFlags struct {
+	ShutdownTimeout time.Duration
+	AdminAddr       string
+	LogLevel        slog.Level
+}
+
+// NewCommandFlags returns CommandFlags holding the defaults.
+func NewCommandFlags() *CommandFlags {
+	return &CommandFlags{ShutdownTimeout: DefaultShutdownTimeout, LogLevel: slog.LevelInfo}
+}
+
+// Register defines the -shutdown-timeout, -admin-addr and -log-level flags
+// on fs. A cobra command can add them with cmd.Flags().AddGoFlagSet(fs).
+func (f *CommandFlags) Register(fs *flag.FlagSet) {
+	fs.DurationVar(&f.ShutdownTimeout, "shutdown-timeout", f.ShutdownTimeout, "how long to wait for services to stop")
+	fs.StringVar(&f.AdminAddr, "admin-addr", f.AdminAddr, "admin API address (host:port or unix:/path), disabled if empty")
+	fs.TextVar(&f.LogLevel, "log-level", f.LogLevel, "log level (debug, info, warn or error)")
+}
+
+// Options returns the controller options for the flags' settings, logging
+// as text to stderr.
+func (f *CommandFlags) Options() []ControllerOpt {
+	opts := []ControllerOpt{
+		WithShutdownTimeout(f.ShutdownTimeout),
+		WithLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: f.LogLevel}))),
+	}
+
+	if f.AdminAddr != "" {
+		opts = append(opts, WithAdminAPI(f.AdminAddr))
+	}
+
+	return opts
+}
+
+// RunCommand runs a daemon the way a command's RunE would: it builds a
+// controller from the flags and opts, stopping as soon as a service fails,
+// lets setup register the services, then starts it and waits for the
+// shutdown. It returns nil after a clean shutdown, and otherwise an ExitError
+// with the controller's ExitCode holding the failure that caused the shutdown
+// joined with any errors from stopping the services.
+//
+//	RunE: func(cmd *cobra.Command, _ []string) error {
+//		return controls.RunCommand(cmd.Context(), flags, setup)
+//	},
+func RunCommand(ctx context.Context, f *CommandFlags, setup func(*Controller) error, opts ...ControllerOpt) error {
+	opts = append(append(f.Options(), WithStopOnError()), opts...)
+	c := NewController(ctx, opts...)
+
+	if err := setup(c); err != nil {
+		return errors.Join(err, c.Close())
+	}
+
+	if err := c.Start(); err != nil {
+		return errors.Join(err, c.Close())
+	}
+
+	failure := c.Wait()
+	err := c.Close()
+
+	if c.StopReason() == StopServiceFailed {
+		err = errors.Join(failure, err)
+	}
+
+	if code := c.ExitCode(); code != 0 {
+		return &ExitError{Code: code, Err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandFlags(t *testing.T) {
+	f := controls.NewCommandFlags()
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	f.Register(fs)
+
+	require.NoError(t, fs.Parse([]string{"-shutdown-timeout", "30s", "-admin-addr", "127.0.0.1:0", "-log-level", "debug"}))
+
+	assert.Equal(t, 30*time.Second, f.ShutdownTimeout)
+	assert.Equal(t, "127.0.0.1:0", f.AdminAddr)
+	assert.Equal(t, slog.LevelDebug, f.LogLevel)
+}
+
+func TestRunCommand(t *testing.T) {
+	errBoom := errors.New("boom") //nolint:err113
+
+	quiet := []controls.ControllerOpt{controls.WithLogger(discardLogger()), controls.WithoutSignals()}
+
+	tests := []struct {
+		name  string
+		setup func(cancel context.CancelFunc) func(*controls.Controller) error
+		code  int
+	}{
+		{
+			name: "clean shutdown",
+			setup: func(cancel context.CancelFunc) func(*controls.Controller) error {
+				return func(c *controls.Controller) error {
+					c.Register("api",
+						controls.WithStart(func(_ context.Context) error { cancel(); return nil }),
+						controls.WithStop(func(_ context.Context) {}),
+					)
+
+					return nil
+				}
+			},
+			code: 0,
+		},
+		{
+			name: "service failed",
+			setup: func(context.CancelFunc) func(*controls.Controller) error {
+				return func(c *controls.Controller) error {
+					c.Register("api", controls.WithStart(func(_ context.Context) error { return errBoom }))
+
+					return nil
+				}
+			},
+			code: 1,
+		},
+		{
+			name: "stop failed",
+			setup: func(cancel context.CancelFunc) func(*controls.Controller) error {
+				return func(c *controls.Controller) error {
+					c.Register("api",
+						controls.WithStart(func(_ context.Context) error { cancel(); return nil }),
+						controls.WithStopErr(func(_ context.Context) error { return errBoom }),
+					)
+
+					return nil
+				}
+			},
+			code: 1,
+		},
+		{
+			name: "setup failed",
+			setup: func(context.CancelFunc) func(*controls.Controller) error {
+				return func(*controls.Controller) error { return errBoom }
+			},
+			code: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			err := controls.RunCommand(ctx, controls.NewCommandFlags(), tt.setup(cancel), quiet...)
+
+			assert.Equal(t, tt.code, controls.ExitCode(err))
+
+			if tt.code != 0 {
+				require.ErrorIs(t, err, errBoom)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+// Package consul registers a controls.Controller with a Consul agent through
+// its HTTP API, for use with controls.WithRegistrar.
+package consul
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/phpboyscout/controls"
+)
+
+const (
+	// DefaultAddr is the local Consul agent.
+	DefaultAddr = "http://127.0.0.1:8500"
+	// DefaultCheckInterval is how often Consul polls a registration's
+	// HealthURL.
+	DefaultCheckInterval = 10 * time.Second
+)
+
+// ErrRequestFailed is returned when the agent responds with an error status.
+var ErrRequestFailed = errors.New("consul request failed")
+
+type Option func(*Registrar)
+
+// Registrar registers services with a Consul agent.
+type Registrar struct {
+	addr  string
+	token string
+	http  *http.Client
+}
+
+// WithToken sets the ACL token sent with each request.
+func WithToken(token string) Option {
+	return func(r *Registrar) {
+		r.token = token
+	}
+}
+
+// WithHTTPClient replaces the http.Client used for requests.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(r *Registrar) {
+		r.http = hc
+	}
+}
+
+// New returns a Registrar for the agent at addr, DefaultAddr when empty.
+func New(addr string, opts ...Option) *Registrar {
+	if addr == "" {
+		addr = DefaultAddr
+	}
+
+	r := &Registrar{addr: strings.TrimSuffix(addr, "/"), http: http.DefaultClient}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+type check struct {
+	CheckID  string `json:"CheckID,omitempty"`
+	Name     string `json:"Name,omitempty"`
+	TTL      string `json:"TTL,omitempty"`
+	HTTP     string `json:"HTTP,omitempty"`
+	Interval string `json:"Interval,omitempty"`
+}
+
+type service struct {
+	ID      string   `json:"ID"`
+	Name    string   `json:"Name"`
+	Address string   `json:"Address,omitempty"`
+	Port    int      `json:"Port,omitempty"`
+	Tags    []string `json:"Tags,omitempty"`
+	Checks  []check  `json:"Checks,omitempty"`
+}
+
+// Register registers the service with a TTL check when reg.TTL is set and an
+// HTTP check when reg.HealthURL is.
+func (r *Registrar) Register(ctx context.Context, reg controls.Registration) error {
+	svc := service{ID: reg.ID, Name: reg.Name, Address: reg.Host, Port: reg.Port, Tags: reg.Tags}
+
+	if reg.TTL > 0 {
+		svc.Checks = append(svc.Checks, check{CheckID: ttlCheckID(reg), Name: reg.Name + " TTL", TTL: reg.TTL.String()})
+	}
+
+	if reg.HealthURL != "" {
+		svc.Checks = append(svc.Checks, check{Name: reg.Name + " HTTP", HTTP: reg.HealthURL, Interval: DefaultCheckInterval.String()})
+	}
+
+	return r.put(ctx, "/v1/agent/service/register", svc)
+}
+
+// Deregister removes the service from the agent.
+func (r *Registrar) Deregister(ctx context.Context, reg controls.Registration) error {
+	return r.put(ctx, "/v1/agent/service/deregister/"+url.PathEscape(reg.ID), nil)
+}
+
+// ReportHealth updates the TTL check: passing when healthy, warning when
+// degraded and critical otherwise.
+func (r *Registrar) ReportHealth(
+	ctx context.Context, reg controls.Registration, status controls.HealthStatus, output string,
+) error {
+	state := "critical"
+
+	switch status {
+	case controls.Healthy:
+		state = "passing"
+	case controls.HealthDegraded:
+		state = "warning"
+	case controls.HealthUnknown, controls.Unhealthy:
+	}
+
+	return r.put(ctx, "/v1/agent/check/update/"+url.PathEscape(ttlCheckID(reg)), map[string]string{
+		"Status": state,
+		"Output": output,
+	})
+}
+
+func ttlCheckID(reg controls.Registration) string {
+	return "service:" + reg.ID + ":ttl"
+}
+
+func (r *Registrar) put(ctx context.Context, path string, body any) error {
+	var payload io.Reader = http.NoBody
+
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		payload = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, r.addr+path, payload)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if r.token != "" {
+		req.Header.Set("X-Consul-Token", r.token)
+	}
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return fmt.Errorf("%w: %s: %s", ErrRequestFailed, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
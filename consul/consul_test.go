@@ -0,0 +1,102 @@
+package consul_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/consul"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type request struct {
+	method, path, token string
+	body                map[string]any
+}
+
+func newAgent(t *testing.T, status int) (*httptest.Server, func() []request) {
+	t.Helper()
+
+	var (
+		mu   sync.Mutex
+		reqs []request
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{method: r.Method, path: r.URL.Path, token: r.Header.Get("X-Consul-Token")}
+		_ = json.NewDecoder(r.Body).Decode(&req.body)
+
+		mu.Lock()
+		reqs = append(reqs, req)
+		mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() []request {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]request(nil), reqs...)
+	}
+}
+
+func TestRegistrar(t *testing.T) {
+	srv, requests := newAgent(t, http.StatusOK)
+	r := consul.New(srv.URL, consul.WithToken("secret"))
+	ctx := context.Background()
+
+	reg := controls.Registration{
+		ID: "api-1", Name: "api", Host: "10.0.0.1", Port: 8080,
+		Tags: []string{"v1"}, HealthURL: "http://10.0.0.1:9090/readyz", TTL: 10 * time.Second,
+	}
+
+	require.NoError(t, r.Register(ctx, reg))
+	require.NoError(t, r.ReportHealth(ctx, reg, controls.Healthy, ""))
+	require.NoError(t, r.ReportHealth(ctx, reg, controls.HealthDegraded, "degraded"))
+	require.NoError(t, r.ReportHealth(ctx, reg, controls.Unhealthy, "not ready"))
+	require.NoError(t, r.Deregister(ctx, reg))
+
+	reqs := requests()
+	require.Len(t, reqs, 5)
+
+	for _, req := range reqs {
+		assert.Equal(t, http.MethodPut, req.method)
+		assert.Equal(t, "secret", req.token)
+	}
+
+	assert.Equal(t, "/v1/agent/service/register", reqs[0].path)
+	assert.Equal(t, "api-1", reqs[0].body["ID"])
+	assert.Equal(t, "api", reqs[0].body["Name"])
+	assert.Equal(t, "10.0.0.1", reqs[0].body["Address"])
+	assert.InDelta(t, 8080, reqs[0].body["Port"], 0)
+	assert.Equal(t, []any{
+		map[string]any{"CheckID": "service:api-1:ttl", "Name": "api TTL", "TTL": "10s"},
+		map[string]any{"Name": "api HTTP", "HTTP": "http://10.0.0.1:9090/readyz", "Interval": "10s"},
+	}, reqs[0].body["Checks"])
+
+	for i, want := range []string{"passing", "warning", "critical"} {
+		req := reqs[i+1]
+		assert.Equal(t, "/v1/agent/check/update/service:api-1:ttl", req.path)
+		assert.Equal(t, want, req.body["Status"])
+	}
+
+	assert.Equal(t, "not ready", reqs[3].body["Output"])
+	assert.Equal(t, "/v1/agent/service/deregister/api-1", reqs[4].path)
+}
+
+func TestRegistrar_Error(t *testing.T) {
+	srv, _ := newAgent(t, http.StatusForbidden)
+
+	err := consul.New(srv.URL).Register(context.Background(), controls.Registration{ID: "api", Name: "api"})
+	require.ErrorIs(t, err, consul.ErrRequestFailed)
+	assert.Contains(t, err.Error(), "403")
+}
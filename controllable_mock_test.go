@@ -42,19 +42,19 @@ func (_m *MockControllable) EXPECT() *MockControllable_Expecter {
 }
 
 // Errors provides a mock function for the type MockControllable
-func (_mock *MockControllable) Errors() chan error {
+func (_mock *MockControllable) Errors() chan<- error {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
 		panic("no return value specified for Errors")
 	}
 
-	var r0 chan error
-	if returnFunc, ok := ret.Get(0).(func() chan error); ok {
+	var r0 chan<- error
+	if returnFunc, ok := ret.Get(0).(func() chan<- error); ok {
 		r0 = returnFunc()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(chan error)
+			r0 = ret.Get(0).(chan<- error)
 		}
 	}
 	return r0
@@ -77,12 +77,12 @@ func (_c *MockControllable_Errors_Call) Run(run func()) *MockControllable_Errors
 	return _c
 }
 
-func (_c *MockControllable_Errors_Call) Return(errCh chan error) *MockControllable_Errors_Call {
+func (_c *MockControllable_Errors_Call) Return(errCh chan<- error) *MockControllable_Errors_Call {
 	_c.Call.Return(errCh)
 	return _c
 }
 
-func (_c *MockControllable_Errors_Call) RunAndReturn(run func() chan error) *MockControllable_Errors_Call {
+func (_c *MockControllable_Errors_Call) RunAndReturn(run func() chan<- error) *MockControllable_Errors_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -224,19 +224,19 @@ func (_c *MockControllable_GetState_Call) RunAndReturn(run func() State) *MockCo
 }
 
 // Health provides a mock function for the type MockControllable
-func (_mock *MockControllable) Health() chan HealthMessage {
+func (_mock *MockControllable) Health() <-chan HealthMessage {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
 		panic("no return value specified for Health")
 	}
 
-	var r0 chan HealthMessage
-	if returnFunc, ok := ret.Get(0).(func() chan HealthMessage); ok {
+	var r0 <-chan HealthMessage
+	if returnFunc, ok := ret.Get(0).(func() <-chan HealthMessage); ok {
 		r0 = returnFunc()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(chan HealthMessage)
+			r0 = ret.Get(0).(<-chan HealthMessage)
 		}
 	}
 	return r0
@@ -259,12 +259,100 @@ func (_c *MockControllable_Health_Call) Run(run func()) *MockControllable_Health
 	return _c
 }
 
-func (_c *MockControllable_Health_Call) Return(healthMessageCh chan HealthMessage) *MockControllable_Health_Call {
+func (_c *MockControllable_Health_Call) Return(healthMessageCh <-chan HealthMessage) *MockControllable_Health_Call {
 	_c.Call.Return(healthMessageCh)
 	return _c
 }
 
-func (_c *MockControllable_Health_Call) RunAndReturn(run func() chan HealthMessage) *MockControllable_Health_Call {
+func (_c *MockControllable_Health_Call) RunAndReturn(run func() <-chan HealthMessage) *MockControllable_Health_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IsDegraded provides a mock function for the type MockControllable
+func (_mock *MockControllable) IsDegraded() bool {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsDegraded")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// MockControllable_IsDegraded_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsDegraded'
+type MockControllable_IsDegraded_Call struct {
+	*mock.Call
+}
+
+// IsDegraded is a helper method to define mock.On call
+func (_e *MockControllable_Expecter) IsDegraded() *MockControllable_IsDegraded_Call {
+	return &MockControllable_IsDegraded_Call{Call: _e.mock.On("IsDegraded")}
+}
+
+func (_c *MockControllable_IsDegraded_Call) Run(run func()) *MockControllable_IsDegraded_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockControllable_IsDegraded_Call) Return(b bool) *MockControllable_IsDegraded_Call {
+	_c.Call.Return(b)
+	return _c
+}
+
+func (_c *MockControllable_IsDegraded_Call) RunAndReturn(run func() bool) *MockControllable_IsDegraded_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IsFailed provides a mock function for the type MockControllable
+func (_mock *MockControllable) IsFailed() bool {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsFailed")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// MockControllable_IsFailed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsFailed'
+type MockControllable_IsFailed_Call struct {
+	*mock.Call
+}
+
+// IsFailed is a helper method to define mock.On call
+func (_e *MockControllable_Expecter) IsFailed() *MockControllable_IsFailed_Call {
+	return &MockControllable_IsFailed_Call{Call: _e.mock.On("IsFailed")}
+}
+
+func (_c *MockControllable_IsFailed_Call) Run(run func()) *MockControllable_IsFailed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockControllable_IsFailed_Call) Return(b bool) *MockControllable_IsFailed_Call {
+	_c.Call.Return(b)
+	return _c
+}
+
+func (_c *MockControllable_IsFailed_Call) RunAndReturn(run func() bool) *MockControllable_IsFailed_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -313,6 +401,50 @@ func (_c *MockControllable_IsRunning_Call) RunAndReturn(run func() bool) *MockCo
 	return _c
 }
 
+// IsStarting provides a mock function for the type MockControllable
+func (_mock *MockControllable) IsStarting() bool {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsStarting")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// MockControllable_IsStarting_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsStarting'
+type MockControllable_IsStarting_Call struct {
+	*mock.Call
+}
+
+// IsStarting is a helper method to define mock.On call
+func (_e *MockControllable_Expecter) IsStarting() *MockControllable_IsStarting_Call {
+	return &MockControllable_IsStarting_Call{Call: _e.mock.On("IsStarting")}
+}
+
+func (_c *MockControllable_IsStarting_Call) Run(run func()) *MockControllable_IsStarting_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockControllable_IsStarting_Call) Return(b bool) *MockControllable_IsStarting_Call {
+	_c.Call.Return(b)
+	return _c
+}
+
+func (_c *MockControllable_IsStarting_Call) RunAndReturn(run func() bool) *MockControllable_IsStarting_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // IsStopped provides a mock function for the type MockControllable
 func (_mock *MockControllable) IsStopped() bool {
 	ret := _mock.Called()
@@ -402,19 +534,19 @@ func (_c *MockControllable_IsStopping_Call) RunAndReturn(run func() bool) *MockC
 }
 
 // Messages provides a mock function for the type MockControllable
-func (_mock *MockControllable) Messages() chan Message {
+func (_mock *MockControllable) Messages() chan<- Message {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
 		panic("no return value specified for Messages")
 	}
 
-	var r0 chan Message
-	if returnFunc, ok := ret.Get(0).(func() chan Message); ok {
+	var r0 chan<- Message
+	if returnFunc, ok := ret.Get(0).(func() chan<- Message); ok {
 		r0 = returnFunc()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(chan Message)
+			r0 = ret.Get(0).(chan<- Message)
 		}
 	}
 	return r0
@@ -437,25 +569,39 @@ func (_c *MockControllable_Messages_Call) Run(run func()) *MockControllable_Mess
 	return _c
 }
 
-func (_c *MockControllable_Messages_Call) Return(messageCh chan Message) *MockControllable_Messages_Call {
+func (_c *MockControllable_Messages_Call) Return(messageCh chan<- Message) *MockControllable_Messages_Call {
 	_c.Call.Return(messageCh)
 	return _c
 }
 
-func (_c *MockControllable_Messages_Call) RunAndReturn(run func() chan Message) *MockControllable_Messages_Call {
+func (_c *MockControllable_Messages_Call) RunAndReturn(run func() chan<- Message) *MockControllable_Messages_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // Register provides a mock function for the type MockControllable
-func (_mock *MockControllable) Register(id string, opts ...ServiceOption) {
+func (_mock *MockControllable) Register(id string, opts ...ServiceOption) *ServiceHandle {
+	var tmpRet mock.Arguments
 	if len(opts) > 0 {
-		_mock.Called(id, opts)
+		tmpRet = _mock.Called(id, opts)
 	} else {
-		_mock.Called(id)
+		tmpRet = _mock.Called(id)
 	}
+	ret := tmpRet
 
-	return
+	if len(ret) == 0 {
+		panic("no return value specified for Register")
+	}
+
+	var r0 *ServiceHandle
+	if returnFunc, ok := ret.Get(0).(func(string, ...ServiceOption) *ServiceHandle); ok {
+		r0 = returnFunc(id, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ServiceHandle)
+		}
+	}
+	return r0
 }
 
 // MockControllable_Register_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Register'
@@ -491,13 +637,13 @@ func (_c *MockControllable_Register_Call) Run(run func(id string, opts ...Servic
 	return _c
 }
 
-func (_c *MockControllable_Register_Call) Return() *MockControllable_Register_Call {
-	_c.Call.Return()
+func (_c *MockControllable_Register_Call) Return(serviceHandle *ServiceHandle) *MockControllable_Register_Call {
+	_c.Call.Return(serviceHandle)
 	return _c
 }
 
-func (_c *MockControllable_Register_Call) RunAndReturn(run func(id string, opts ...ServiceOption)) *MockControllable_Register_Call {
-	_c.Run(run)
+func (_c *MockControllable_Register_Call) RunAndReturn(run func(id string, opts ...ServiceOption) *ServiceHandle) *MockControllable_Register_Call {
+	_c.Call.Return(run)
 	return _c
 }
 
@@ -742,9 +888,20 @@ func (_c *MockControllable_SetSignalsChannel_Call) RunAndReturn(run func(sigs ch
 }
 
 // SetState provides a mock function for the type MockControllable
-func (_mock *MockControllable) SetState(state State) {
-	_mock.Called(state)
-	return
+func (_mock *MockControllable) SetState(state State) error {
+	ret := _mock.Called(state)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetState")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(State) error); ok {
+		r0 = returnFunc(state)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
 }
 
 // MockControllable_SetState_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetState'
@@ -771,13 +928,13 @@ func (_c *MockControllable_SetState_Call) Run(run func(state State)) *MockContro
 	return _c
 }
 
-func (_c *MockControllable_SetState_Call) Return() *MockControllable_SetState_Call {
-	_c.Call.Return()
+func (_c *MockControllable_SetState_Call) Return(err error) *MockControllable_SetState_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockControllable_SetState_Call) RunAndReturn(run func(state State)) *MockControllable_SetState_Call {
-	_c.Run(run)
+func (_c *MockControllable_SetState_Call) RunAndReturn(run func(state State) error) *MockControllable_SetState_Call {
+	_c.Call.Return(run)
 	return _c
 }
 
@@ -822,19 +979,19 @@ func (_c *MockControllable_SetWaitGroup_Call) RunAndReturn(run func(wg *sync.Wai
 }
 
 // Signals provides a mock function for the type MockControllable
-func (_mock *MockControllable) Signals() chan os.Signal {
+func (_mock *MockControllable) Signals() chan<- os.Signal {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
 		panic("no return value specified for Signals")
 	}
 
-	var r0 chan os.Signal
-	if returnFunc, ok := ret.Get(0).(func() chan os.Signal); ok {
+	var r0 chan<- os.Signal
+	if returnFunc, ok := ret.Get(0).(func() chan<- os.Signal); ok {
 		r0 = returnFunc()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(chan os.Signal)
+			r0 = ret.Get(0).(chan<- os.Signal)
 		}
 	}
 	return r0
@@ -857,20 +1014,31 @@ func (_c *MockControllable_Signals_Call) Run(run func()) *MockControllable_Signa
 	return _c
 }
 
-func (_c *MockControllable_Signals_Call) Return(signalCh chan os.Signal) *MockControllable_Signals_Call {
+func (_c *MockControllable_Signals_Call) Return(signalCh chan<- os.Signal) *MockControllable_Signals_Call {
 	_c.Call.Return(signalCh)
 	return _c
 }
 
-func (_c *MockControllable_Signals_Call) RunAndReturn(run func() chan os.Signal) *MockControllable_Signals_Call {
+func (_c *MockControllable_Signals_Call) RunAndReturn(run func() chan<- os.Signal) *MockControllable_Signals_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // Start provides a mock function for the type MockControllable
-func (_mock *MockControllable) Start() {
-	_mock.Called()
-	return
+func (_mock *MockControllable) Start() error {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Start")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func() error); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
 }
 
 // MockControllable_Start_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Start'
@@ -890,20 +1058,31 @@ func (_c *MockControllable_Start_Call) Run(run func()) *MockControllable_Start_C
 	return _c
 }
 
-func (_c *MockControllable_Start_Call) Return() *MockControllable_Start_Call {
-	_c.Call.Return()
+func (_c *MockControllable_Start_Call) Return(err error) *MockControllable_Start_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockControllable_Start_Call) RunAndReturn(run func()) *MockControllable_Start_Call {
-	_c.Run(run)
+func (_c *MockControllable_Start_Call) RunAndReturn(run func() error) *MockControllable_Start_Call {
+	_c.Call.Return(run)
 	return _c
 }
 
 // Stop provides a mock function for the type MockControllable
-func (_mock *MockControllable) Stop() {
-	_mock.Called()
-	return
+func (_mock *MockControllable) Stop() error {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stop")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func() error); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
 }
 
 // MockControllable_Stop_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Stop'
@@ -923,12 +1102,12 @@ func (_c *MockControllable_Stop_Call) Run(run func()) *MockControllable_Stop_Cal
 	return _c
 }
 
-func (_c *MockControllable_Stop_Call) Return() *MockControllable_Stop_Call {
-	_c.Call.Return()
+func (_c *MockControllable_Stop_Call) Return(err error) *MockControllable_Stop_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockControllable_Stop_Call) RunAndReturn(run func()) *MockControllable_Stop_Call {
-	_c.Run(run)
+func (_c *MockControllable_Stop_Call) RunAndReturn(run func() error) *MockControllable_Stop_Call {
+	_c.Call.Return(run)
 	return _c
 }
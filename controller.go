@@ -2,8 +2,13 @@ package controls
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
@@ -13,80 +18,279 @@ import (
 
 const DefaultShutdownTimeout = 5 * time.Second
 
+var (
+	// ErrNotRunning is returned by operations that require a running controller.
+	ErrNotRunning = errors.New("controller is not running")
+	// ErrAlreadyStarted is reported when a channel setter is called after Start.
+	ErrAlreadyStarted = errors.New("controller already started")
+	// ErrAlreadyRunning is returned by Start when the controller has already
+	// been started.
+	ErrAlreadyRunning = errors.New("controller already running")
+	// ErrNotStarted is returned by Stop when the controller was never started.
+	ErrNotStarted = errors.New("controller not started")
+	// ErrNoServices is returned by Start when no services are registered.
+	ErrNoServices = errors.New("no services registered")
+	// ErrShutdownTimeout is returned by Stop when services were still
+	// stopping once the shutdown timeout elapsed.
+	ErrShutdownTimeout = errors.New("shutdown timed out")
+	// ErrUnnamedService is returned by RegisterAll for a service without a
+	// name.
+	ErrUnnamedService = errors.New("service has no name")
+	// ErrMissingStart is returned by RegisterAll for a service without a
+	// start function.
+	ErrMissingStart = errors.New("service has no start function")
+)
+
+var _ Controllable = (*Controller)(nil)
+
 type Controller struct {
 	ctx             context.Context
+	runCtx          context.Context
+	cancelRun       context.CancelCauseFunc
+	name            string
 	logger          *slog.Logger
 	messages        chan Message
 	health          chan HealthMessage
 	errs            chan error
 	signals         chan os.Signal
 	wg              *sync.WaitGroup
+	channelsMutex   sync.RWMutex
+	started         bool
+	tracker         tracker
 	shutdownTimeout time.Duration
-	state           State
+	drainTimeout    time.Duration
+	state           atomicState
 	stateMutex      sync.Mutex
+	startedAt       time.Time
 	services        Services
+	listeners       *ListenerRegistry
+	injection       bool
+	eventsMutex     sync.Mutex
+	eventHandlers   []EventHandler
+	adminAddr       string
+	adminMutex      sync.Mutex
+	adminListener   net.Listener
+	adminServer     *http.Server
+	adminAuth       adminAuth
+	adminTLS        *tls.Config
+	adminCert       *certFiles
+	remote          RemoteTransport
+	quiet           bool
+	clock           Clock
+	flags           FlagProvider
+	background      background
+	statusInterval  time.Duration
+	readyTimeout    time.Duration
+	changes         changes
+	shutdown        shutdown
+	// shutdownDeadline, stackDump and forcedExit control what happens when
+	// services outlast the whole shutdown; see WithShutdownDeadline.
+	shutdownDeadline time.Duration
+	stackDump        io.Writer
+	forcedExit       func(remaining []string)
+	forced           sync.Once
+	secondSignal     bool
+	stopOnError      bool
+	errorHandler     ErrorHandler
+	errLog           errLog
+	history          stateHistory
+	middleware       []Middleware
+	preregistered    []Service
+	queries          chan chan error
+	// commands carries the controller's own control messages, with who sent
+	// them, to the control loop.
+	commands    chan command
+	auditLog    auditLog
+	maintenance maintenance
+	// registrar announces the controller to service discovery.
+	registrar    Registrar
+	registration Registration
+	announcement announcement
+	// coordinator hands out turns to stop across a fleet; see
+	// WithShutdownCoordinator.
+	coordinator ShutdownCoordinator
+	turn        turn
+	// metrics receives lifecycle metrics; see WithMetrics.
+	metrics         MetricsSink
+	metricsInterval time.Duration
+	hooks           hooks
+	cleanups        cleanups
+	// pings is received from by the control loop to show it is responsive.
+	pings chan struct{}
+	// loops tracks the control goroutine and handlers its signal and error
+	// handlers, which all exit once the shutdown has finished.
+	loops    sync.WaitGroup
+	handlers sync.WaitGroup
+	// owned names the channels the controller created. Once it has stopped
+	// it stops delivering signals to its own signals channel and closes its
+	// own health channel; sending guards health reports against that close.
+	owned          map[string]bool
+	sending        sync.RWMutex
+	channelsClosed bool
+	// dispatcher passes signals to subscribed, the channel WithParentSignals
+	// subscribed to it, until the controller unsubscribes.
+	dispatcher *SignalDispatcher
+	subscribed chan os.Signal
+	// adopted holds the run contexts of controllers whose running services
+	// were adopted; see Adopt.
+	adopted struct {
+		mu   sync.Mutex
+		runs []context.CancelCauseFunc
+	}
+}
+
+// shutdown records the outcome of stopping the controller for Stop callers.
+type shutdown struct {
+	once sync.Once
+	done chan struct{}
+	err  error
+
+	mu     sync.Mutex
+	reason StopReason
+	// cause is the error behind the shutdown, returned by Wait.
+	cause error
+	// requested and begun coalesce stop requests arriving together from
+	// signals, context cancellation and Stop calls: only the first asks the
+	// control loop to stop, and the stop sequence runs once.
+	requested bool
+	begun     bool
+}
+
+func (s *shutdown) finish(err error) {
+	s.once.Do(func() {
+		s.err = err
+		close(s.done)
+	})
 }
 
 func (c *Controller) GetContext() context.Context {
 	return c.ctx
 }
 
-func (c *Controller) Messages() chan Message {
+// Messages returns the channel control messages are sent on. It is
+// send-only: the control loop is its only reader. Send does the same without
+// exposing the channel.
+func (c *Controller) Messages() chan<- Message {
+	return c.messageChannel()
+}
+
+func (c *Controller) messageChannel() chan Message {
+	c.channelsMutex.RLock()
+	defer c.channelsMutex.RUnlock()
+
 	return c.messages
 }
 
 func (c *Controller) SetMessageChannel(messages chan Message) {
-	c.messages = messages
+	c.setChannel("messages", func() { c.messages = messages })
+}
+
+// Health returns the channel health messages are published on, for
+// consumers to read. Services report their health with ReportHealth.
+func (c *Controller) Health() <-chan HealthMessage {
+	return c.healthChannel()
 }
 
-func (c *Controller) Health() chan HealthMessage {
+func (c *Controller) healthChannel() chan HealthMessage {
+	c.channelsMutex.RLock()
+	defer c.channelsMutex.RUnlock()
+
 	return c.health
 }
 
 func (c *Controller) SetHealthChannel(health chan HealthMessage) {
-	c.health = health
+	c.setChannel("health", func() { c.health = health })
+}
+
+// Signals returns the channel the controller receives OS signals on, or nil
+// when it doesn't handle signals. It is send-only, as for signal.Notify.
+func (c *Controller) Signals() chan<- os.Signal {
+	return c.signalChannel()
 }
 
-func (c *Controller) Signals() chan os.Signal {
+func (c *Controller) signalChannel() chan os.Signal {
+	c.channelsMutex.RLock()
+	defer c.channelsMutex.RUnlock()
+
 	return c.signals
 }
 
 func (c *Controller) SetSignalsChannel(signals chan os.Signal) {
-	c.signals = signals
+	c.setChannel("signals", func() { c.signals = signals })
 }
 
-func (c *Controller) Errors() chan error {
+// Errors returns the channel errors are reported on. It is send-only: the
+// controller's error handler is its only reader. ReportError does the same
+// without exposing the channel.
+func (c *Controller) Errors() chan<- error {
+	return c.errorChannel()
+}
+
+func (c *Controller) errorChannel() chan error {
+	c.channelsMutex.RLock()
+	defer c.channelsMutex.RUnlock()
+
 	return c.errs
 }
 
 func (c *Controller) SetErrorsChannel(errs chan error) {
-	c.errs = errs
+	c.setChannel("errors", func() { c.errs = errs })
 }
 
+// WaitGroup returns the WaitGroup the controller mirrors its services onto.
+//
+// Deprecated: use Wait, which tracks the services itself.
 func (c *Controller) WaitGroup() *sync.WaitGroup {
+	c.channelsMutex.RLock()
+	defer c.channelsMutex.RUnlock()
+
 	return c.wg
 }
 
+// SetWaitGroup attaches a caller owned WaitGroup. It must be set before Start.
+// The controller adds one per registered service and only ever removes what it
+// added; anything else the caller adds must be balanced by the caller, and a
+// WaitGroup left undrained is reported by Wait after the shutdown timeout.
+//
+// Deprecated: Wait tracks the services itself; call it instead of waiting on
+// a WaitGroup.
 func (c *Controller) SetWaitGroup(wg *sync.WaitGroup) {
-	c.wg = wg
+	c.setChannel("wait group", func() { c.wg = wg })
 }
 
+// setChannel swaps one of the controller's channels. The control goroutines
+// read the channels for as long as the controller runs, so swapping after
+// Start is rejected and reported rather than stranding them on the old one.
+func (c *Controller) setChannel(name string, set func()) {
+	c.channelsMutex.Lock()
+	defer c.channelsMutex.Unlock()
+
+	if c.started {
+		c.log().Error(ErrAlreadyStarted.Error(), "channel", name)
+
+		return
+	}
+
+	delete(c.owned, name)
+
+	set()
+}
+
+// SetShutdownTimeout bounds how long Stop waits for the services to stop,
+// DefaultShutdownTimeout unless set. Set it before Start.
 func (c *Controller) SetShutdownTimeout(d time.Duration) {
 	c.shutdownTimeout = d
 }
 
-func (c *Controller) SetState(state State) {
-	c.stateMutex.Lock()
-	defer c.stateMutex.Unlock()
-
-	c.state = state
+func (c *Controller) GetState() State {
+	return c.state.load()
 }
 
-func (c *Controller) GetState() State {
+func (c *Controller) getStartedAt() time.Time {
 	c.stateMutex.Lock()
 	defer c.stateMutex.Unlock()
 
-	return c.state
+	return c.startedAt
 }
 
 func (c *Controller) SetLogger(logger *slog.Logger) {
@@ -109,72 +313,376 @@ func (c *Controller) IsStopping() bool {
 	return c.GetState() == Stopping
 }
 
-func (c *Controller) Register(id string, opts ...ServiceOption) {
+// Register adds a service built from opts and returns its handle. Service
+// names are unique: registering a name that is already taken logs an error,
+// returns nil and makes Start fail with ErrServiceExists.
+func (c *Controller) Register(id string, opts ...ServiceOption) *ServiceHandle {
+	return c.register(newService(id, opts...))
+}
+
+func (c *Controller) register(s Service) *ServiceHandle {
+	s.wrap(c.middleware)
+
+	h, err := c.services.add(s)
+	if err != nil {
+		c.log().Error("Service not registered", "service", s.Name, "error", err)
+
+		return nil
+	}
+
+	c.attach(h)
+	c.notePhaseConflicts()
+
+	return h
+}
+
+// RegisterAll registers already built services as a set, so an application
+// can assemble its services declaratively. Every service is checked first,
+// and if any has no name or start function, or a name that is already
+// registered or repeated in services, none are registered and the problems
+// are returned joined.
+func (c *Controller) RegisterAll(services ...Service) error {
+	added, err := c.services.addAll(services)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range added {
+		s.wrap(c.middleware)
+		c.attach(s.handle)
+	}
+
+	c.notePhaseConflicts()
+
+	return nil
+}
+
+// notePhaseConflicts warns of phase conflicts among services registered after
+// Start, which rejects them before then. Such services are stopped together.
+func (c *Controller) notePhaseConflicts() {
+	if c.GetState() == Unknown {
+		return
+	}
+
+	if err := c.services.phaseConflicts(); err != nil {
+		c.log().Warn("Stopping conflicting services together", "error", err)
+	}
+}
+
+// attach makes c the controller h manages its service through, and records
+// the service's events on h.
+func (c *Controller) attach(h *ServiceHandle) {
+	h.controller.Store(c)
+	c.AddEventHandler(h.record)
+}
+
+func newService(name string, opts ...ServiceOption) Service {
 	s := Service{
-		Name: id,
+		Name: name,
 	}
 
 	for _, opt := range opts {
 		opt(&s)
 	}
 
-	c.services.add(s)
+	return s
+}
+
+// StopService stops a single running service without stopping the controller.
+func (c *Controller) StopService(name string) error {
+	return c.audited(programmatic, ActionStopService, name, "", func() error {
+		return c.stopService(context.Background(), name)
+	})
 }
 
-func (c *Controller) Start() {
+func (c *Controller) stopService(ctx context.Context, name string) error {
+	ctx, cancel := c.withTimeout(ctx, c.shutdownTimeout)
+	defer cancel()
+
+	stopped, err := c.services.stopOne(ctx, name)
+	if stopped {
+		c.log().Info("Stopped service", "service", name)
+		c.releaseWaitGroup(1)
+		c.emit(Event{Type: ServiceStopped, Service: name, Err: err})
+		c.assess()
+	}
+
+	return err
+}
+
+// RestartService stops a single service, if it is running, and starts it again.
+func (c *Controller) RestartService(name string) error {
+	return c.audited(programmatic, ActionRestartService, name, "", func() error {
+		return c.restartService(context.Background(), name)
+	})
+}
+
+func (c *Controller) restartService(ctx context.Context, name string) error {
+	if !c.isUp() {
+		return ErrNotRunning
+	}
+
+	ctx, cancel := c.withTimeout(ctx, c.shutdownTimeout)
+	defer cancel()
+
+	wasStopped, err := c.services.restart(c.runCtx, ctx, name)
+	if errors.Is(err, ErrServiceNotFound) || errors.Is(err, ErrServiceDisabled) {
+		return err
+	}
+
+	if wasStopped {
+		c.addToWaitGroup(1)
+	}
+
+	c.log().Info("Restarted service", "service", name)
+	c.emit(Event{Type: ServiceRestarted, Service: name, Err: err})
+	c.assess()
+
+	return err
+}
+
+// Start runs the registered services, moving through Starting until they are
+// ready. Channel setters are rejected from this point on. It returns
+// ErrAlreadyRunning if the controller has been started before, and
+// ErrNoServices, leaving the controller unstarted, if nothing is registered.
+// Rejected registrations and ErrPhaseConflict also leave it unstarted.
+func (c *Controller) Start() error {
+	switch state := c.GetState(); {
+	case state != Unknown:
+		return fmt.Errorf("%w: %s", ErrAlreadyRunning, state)
+	case c.services.empty():
+		return ErrNoServices
+	}
+
+	if err := c.services.rejections(); err != nil {
+		return err
+	}
+
+	if err := c.services.phaseConflicts(); err != nil {
+		return err
+	}
+
+	if err := c.runHooks(c.ctx, &c.hooks.beforeStart, true); err != nil {
+		return err
+	}
+
+	admin, err := c.listenAdmin()
+	if err != nil {
+		return err
+	}
+
+	if !c.swapState(Starting, Unknown) {
+		if admin != nil {
+			_ = admin.Close()
+		}
+
+		return fmt.Errorf("%w: %s", ErrAlreadyRunning, c.GetState())
+	}
+
+	c.channelsMutex.Lock()
+	c.started = true
+	c.channelsMutex.Unlock()
+
+	c.enroll()
+
+	c.loops.Add(1)
+
 	go c.controls()
 
-	adding := len(c.services.services)
-	c.wg.Add(adding)
-	c.services.start(c.ctx, c.errs)
-	c.SetState(Running)
+	c.stateMutex.Lock()
+	c.startedAt = c.clock.Now()
+	c.stateMutex.Unlock()
+
+	c.startAdmin(admin)
+	c.startRemote()
+
+	adding := c.services.hold(c.evaluateFlags(c.ctx))
+	c.addToWaitGroup(adding)
+	c.startServices()
+	c.swapState(c.services.condition(), Starting)
+	c.startStatusScheduler()
+	c.startWatchdog()
+	c.startMetrics()
+	c.announce()
+
+	if err := c.runHooks(c.runCtx, &c.hooks.afterStart, false); err != nil {
+		c.log().Error("After start hook failed", "error", err)
+	}
+
+	return nil
 }
 
-func (c *Controller) Wait() {
-	c.wg.Wait()
+// Wait blocks until every service the controller has started has stopped, or
+// completed, or until the controller gives up on them at shutdown. It then
+// waits for any WaitGroup attached with SetWaitGroup to drain. It returns the
+// error that caused the shutdown: the service error that stopped a controller
+// created WithStopOnError, or the cause of its context's cancellation. A
+// shutdown by signal or by Stop returns nil.
+func (c *Controller) Wait() error {
+	c.awaitServices()
+	c.waitExternal()
+
+	c.shutdown.mu.Lock()
+	defer c.shutdown.mu.Unlock()
+
+	return c.shutdown.cause
 }
 
-// Stop configured server.
-func (c *Controller) Stop() {
-	c.SetState(Stopping)
+// Stop gracefully stops the registered services and blocks until they have
+// stopped. Errors reported by services registered WithStopErr are joined and
+// returned, along with ErrShutdownTimeout if they outlived the shutdown
+// timeout; calling Stop again returns the same result. It returns
+// ErrNotStarted if the controller was never started.
+func (c *Controller) Stop() error {
+	if c.GetState() == Unknown {
+		return ErrNotStarted
+	}
+
+	if !c.IsStopped() {
+		c.requestStop(StopRequested, programmatic)
+	}
+
+	<-c.shutdown.done
 
-	c.messages <- Stop
+	return c.shutdown.err
 }
 
-// Controls sets the handlers for different control operations.
+// Close stops the controller if it is still running, waits for its control
+// goroutines to exit and stops listening for signals. It returns the same
+// result as Stop. A controller that was never started is marked stopped once
+// its cleanups have run.
+func (c *Controller) Close() error {
+	if c.swapState(Stopped, Unknown) {
+		c.beginStopping()
+		err := c.cleanUpWithin()
+		c.withdraw()
+		c.shutdown.finish(err)
+		c.closeChannels()
+	}
+
+	err := c.Stop()
+
+	c.loops.Wait()
+
+	if sigs := c.Signals(); sigs != nil {
+		signal.Stop(sigs)
+	}
+
+	return err
+}
+
+// requestStop asks the control loop to stop without waiting for it. Only the
+// first request reaches the loop; later ones are coalesced into it.
+func (c *Controller) requestStop(reason StopReason, o origin) {
+	c.shutdown.stopFor(reason, nil)
+
+	if !c.shutdown.request() {
+		c.log().Debug("Stop already requested", "reason", reason)
+
+		return
+	}
+
+	if c.coordinated(reason) {
+		c.stopInTurn(o)
+
+		return
+	}
+
+	if !c.moveTo(Stopping) {
+		return
+	}
+
+	c.sendMessage(Stop, o, nil)
+}
+
+// Controls sets the handlers for different control operations. Each handler
+// returns once the controller has stopped, after which the controller's own
+// channels are closed.
 func (c *Controller) controls() {
+	defer c.loops.Done()
+
 	c.startSignalHandler()
 	c.startErrorAndContextHandler()
 	c.processControlMessages()
+	c.handlers.Wait()
+	c.closeChannels()
 }
 
 func (c *Controller) startSignalHandler() {
 	// handle signals
-	if c.signals != nil {
+	if c.Signals() != nil {
+		c.handlers.Add(1)
+
 		go func() {
-			sig := <-c.Signals()
-			c.logger.Warn(fmt.Sprintf("Received signal: %s", sig))
-			c.Stop()
+			defer c.handlers.Done()
+
+			sig, ok := c.nextSignal()
+			if !ok {
+				return
+			}
+
+			c.log().Warn(fmt.Sprintf("Received signal: %s", sig))
+			c.emit(Event{Type: SignalReceived, Message: sig.String()})
+			c.requestStop(StopSignalled, origin{source: AuditSignal, actor: sig.String()})
+
+			for {
+				sig, ok := c.nextSignal()
+				if !ok {
+					return
+				}
+
+				c.log().Warn(fmt.Sprintf("Received signal during shutdown: %s", sig))
+				c.emit(Event{Type: SignalReceived, Message: sig.String()})
+
+				if c.skipTurn() {
+					continue
+				}
+
+				if c.secondSignal && !c.IsStopped() {
+					c.audit(origin{source: AuditSignal, actor: sig.String()}, string(Stop), "", "forced", nil)
+					c.forceStop(ErrSecondSignal)
+				}
+			}
 		}()
 	}
 }
 
+// nextSignal waits for a signal, reporting false once the controller has
+// stopped or the signal channel is closed.
+func (c *Controller) nextSignal() (os.Signal, bool) {
+	select {
+	case sig, ok := <-c.signalChannel():
+		return sig, ok
+	case <-c.shutdown.done:
+		return nil, false
+	}
+}
+
 func (c *Controller) startErrorAndContextHandler() {
 	// handle errors and context cancellation
+	c.handlers.Add(1)
+
 	go func() {
-		ctxCancelled := false
+		defer c.handlers.Done()
+
+		// done is cleared once handled; a closed channel would otherwise be
+		// selected on every iteration.
+		done := c.GetContext().Done()
 
 		for {
 			select {
-			case err := <-c.Errors():
-				c.logger.Error(err.Error())
-			case <-c.GetContext().Done():
-				if !ctxCancelled {
-					ctxCancelled = true
-
-					c.logger.Warn("Context cancelled")
-					c.Stop()
-				}
+			case <-c.shutdown.done:
+				return
+			case err := <-c.errorChannel():
+				c.handleError(err)
+			case <-done:
+				done = nil
+
+				c.log().Warn("Context cancelled")
+				cause := context.Cause(c.GetContext())
+				c.emit(Event{Type: ContextCancelled, Message: cause.Error()})
+				c.shutdown.stopFor(StopContextCancelled, cause)
+				c.requestStop(StopContextCancelled, internal)
 			}
 		}
 	}()
@@ -183,41 +691,111 @@ func (c *Controller) startErrorAndContextHandler() {
 func (c *Controller) processControlMessages() {
 	// handle the control message cases
 	for {
-		msg := <-c.Messages()
-		switch msg {
-		case Stop:
-			c.handleStopMessage()
-		case Status:
-			c.services.status()
+		select {
+		case <-c.shutdown.done:
+			return
+		case msg := <-c.messageChannel():
+			c.handleMessage(command{msg: msg, origin: programmatic})
+		case cmd := <-c.commands:
+			c.handleMessage(cmd)
+		case reply := <-c.queries:
+			reply <- c.checkStatus()
+		case <-c.pings:
 		}
 	}
 }
 
+func (c *Controller) handleMessage(cmd command) {
+	switch msg := cmd.msg; msg {
+	case Stop:
+		c.audit(cmd.origin, string(msg), "", string(c.StopReason()), nil)
+		c.handleStopMessage()
+	case Drain:
+		c.audit(cmd.origin, string(msg), "", "", nil)
+		c.handleDrainMessage()
+	case Status:
+		c.audit(cmd.origin, string(msg), "", "", c.checkStatus())
+	case Reload:
+		c.applyFlags()
+
+		err := c.services.handle(msg)
+		if err != nil {
+			c.log().Error(err.Error())
+		}
+
+		c.audit(cmd.origin, string(msg), "", "", err)
+	default:
+		err := c.services.handle(msg)
+		if err != nil {
+			c.log().Error(err.Error())
+		}
+
+		c.audit(cmd.origin, string(msg), "", "", err)
+	}
+}
+
 func (c *Controller) handleStopMessage() {
-	if c.IsRunning() {
-		c.logger.Warn("Stopping Services")
-		c.SetState(Stopping)
+	c.beginStopping()
+
+	if c.isUp() {
+		c.log().Warn("Stopping Services")
+		c.moveTo(Stopping)
+	}
+
+	if c.IsStopping() && c.shutdown.begin() {
+		c.stopWithDeadline(c.stopAll)
+	}
+}
+
+func (c *Controller) stopAll() {
+	ctx, cancel := c.withTimeout(context.Background(), c.shutdownTimeout)
+	defer cancel()
+
+	hookErr := c.runHooks(ctx, &c.hooks.beforeStop, false)
+
+	c.retract()
+
+	stopped, err := c.services.stop(ctx)
+	if ctx.Err() != nil {
+		err = errors.Join(fmt.Errorf("%w after %s", ErrShutdownTimeout, c.shutdownTimeout), err)
 	}
 
-	if c.IsStopping() {
-		ctx, cancel := context.WithTimeout(context.Background(), c.shutdownTimeout)
-		defer cancel()
+	c.releaseWaitGroup(stopped)
+	c.stopAdmin(ctx)
+	c.releaseResources()
+	c.endTurn()
+
+	err = errors.Join(hookErr, err, c.afterStop())
+	c.moveTo(Stopped)
 
-		stopping := 0 - c.services.stop(ctx)
-		c.wg.Add(stopping)
-		c.SetState(Stopped)
-		c.logger.Info("Stopped")
+	if err != nil {
+		c.log().Error("Services failed to stop cleanly", "error", err)
 	}
+
+	c.withdraw()
+	c.log().Info("Stopped")
+	c.shutdown.finish(err)
 }
 
 type ControllerOpt func(Controllable)
 
+// configure applies fn when c is a *Controller. Options that only make sense
+// for the concrete controller are ignored by other Controllable types.
+func configure(c Controllable, fn func(*Controller)) {
+	if ctrl, ok := c.(*Controller); ok {
+		fn(ctrl)
+	}
+}
+
+// WithoutSignals stops the controller handling OS signals, so they take
+// their default action.
 func WithoutSignals() ControllerOpt {
 	return func(c Controllable) {
-		c.SetSignalsChannel(nil)
+		replaceSignals(c, nil)
 	}
 }
 
+// WithShutdownTimeout sets the shutdown timeout; see SetShutdownTimeout.
 func WithShutdownTimeout(d time.Duration) ControllerOpt {
 	return func(c Controllable) {
 		c.SetShutdownTimeout(d)
@@ -236,20 +814,52 @@ func NewController(ctx context.Context, opts ...ControllerOpt) *Controller {
 		ctx:             ctx,
 		logger:          slog.New(slog.NewTextHandler(os.Stdout, nil)),
 		messages:        make(chan Message),
-		health:          make(chan HealthMessage),
+		queries:         make(chan chan error),
+		commands:        make(chan command),
+		pings:           make(chan struct{}),
+		health:          make(chan HealthMessage, DefaultHealthBuffer),
 		errs:            make(chan error),
 		wg:              &sync.WaitGroup{},
 		shutdownTimeout: DefaultShutdownTimeout,
-		state:           Unknown,
-		services:        Services{},
+		drainTimeout:    DefaultDrainTimeout,
+		clock:           realClock{},
+		shutdown:        shutdown{done: make(chan struct{})},
+		secondSignal:    true,
+		errLog:          errLog{size: DefaultErrorHistory},
+		history:         stateHistory{size: DefaultStateHistory},
+		auditLog:        auditLog{size: DefaultAuditHistory},
+		services:        Services{historySize: DefaultHealthHistory, stopConcurrency: DefaultStopConcurrency},
+		listeners:       newListenerRegistry(),
 	}
 
 	c.SetSignalsChannel(make(chan os.Signal, 1))
 	signal.Notify(c.Signals(), syscall.SIGINT, syscall.SIGTERM)
 
+	c.owned = map[string]bool{"messages": true, "health": true, "errors": true, "signals": true}
+
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	for _, s := range c.preregistered {
+		c.register(s)
+	}
+
+	c.preregistered = nil
+
+	c.runCtx, c.cancelRun = context.WithCancelCause(c.ctx)
+	c.services.now = c.clock.Now
+	c.services.withTimeout = c.withTimeout
+	c.services.after = c.clock.After
+	c.services.logger = c.GetLogger
+	c.services.controlLog = c.log
+	c.services.completed = c.serviceCompleted
+	c.services.changed = c.servicesChanged
+	c.services.report = c.reportError
+	c.services.transitioned = c.serviceTransitioned
+	c.services.listeners = c.listeners
+	c.services.timeSource = c.clock
+	c.history.now = c.clock.Now
+
 	return c
 }
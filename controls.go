@@ -11,20 +11,30 @@ import (
 const (
 	Stop   Message = "stop"
 	Status Message = "status"
+	Reload Message = "reload"
 )
 
 const (
-	Unknown  State = "unknown"
-	Running  State = "running"
-	Stopping State = "stopping"
-	Stopped  State = "stopped"
+	Unknown   State = "unknown"
+	Starting  State = "starting"
+	Running   State = "running"
+	Degraded  State = "degraded"
+	Stopping  State = "stopping"
+	Stopped   State = "stopped"
+	Failed    State = "failed"
+	Paused    State = "paused"
+	Completed State = "completed"
+	Disabled  State = "disabled"
 )
 
 type State string
 type Message string
 type StartFunc func(context.Context) error
 type StopFunc func(context.Context)
+type StopErrFunc func(context.Context) error
 type StatusFunc func()
+type MessageFunc func(Message) error
+type DetailsFunc func() map[string]string
 type ValidErrorFunc func(error) bool
 type ServiceOption func(*Service)
 
@@ -40,39 +50,82 @@ func WithStop(fn StopFunc) ServiceOption {
 	}
 }
 
+// WithStopErr sets a stop function that reports whether cleanup succeeded.
+// Errors are joined and returned from Controller.Stop. It takes precedence
+// over WithStop.
+func WithStopErr(fn StopErrFunc) ServiceOption {
+	return func(s *Service) {
+		s.StopErr = fn
+	}
+}
+
 func WithStatus(fn StatusFunc) ServiceOption {
 	return func(s *Service) {
 		s.Status = fn
 	}
 }
 
+// WithMessageHandler receives control messages the controller does not handle
+// itself, allowing services to implement their own control commands.
+func WithMessageHandler(fn MessageFunc) ServiceOption {
+	return func(s *Service) {
+		s.Message = fn
+	}
+}
+
+// WithDetails supplies extra key/value information included in the service's
+// entry in Report.
+func WithDetails(fn DetailsFunc) ServiceOption {
+	return func(s *Service) {
+		s.Details = fn
+	}
+}
+
+// HealthStatus is the outcome reported in a HealthMessage. It is marshalled
+// as its name, such as "healthy".
+type HealthStatus int
+
+const (
+	HealthUnknown HealthStatus = iota
+	Healthy
+	HealthDegraded
+	Unhealthy
+)
+
+// HealthMessage reports the health of a service. ServiceName and Timestamp are
+// filled in by the controller when it records a message that lacks them.
 type HealthMessage struct {
-	Host    string `json:"host"`
-	Port    int    `json:"port"`
-	Status  int    `json:"status"`
-	Message string `json:"message"`
+	ServiceName string       `json:"service_name,omitempty"`
+	Host        string       `json:"host"`
+	Port        int          `json:"port"`
+	Status      HealthStatus `json:"status"`
+	Message     string       `json:"message"`
+	Timestamp   time.Time    `json:"timestamp"`
 }
 
 type Controllable interface {
-	Messages() chan Message
-	Health() chan HealthMessage
-	Errors() chan error
-	Signals() chan os.Signal
+	Messages() chan<- Message
+	Health() <-chan HealthMessage
+	Errors() chan<- error
+	Signals() chan<- os.Signal
 	SetErrorsChannel(errs chan error)
 	SetMessageChannel(control chan Message)
 	SetSignalsChannel(sigs chan os.Signal)
 	SetHealthChannel(health chan HealthMessage)
 	SetWaitGroup(wg *sync.WaitGroup)
 	SetShutdownTimeout(d time.Duration)
-	Start()
-	Stop()
+	Start() error
+	Stop() error
 	GetContext() context.Context
-	SetState(state State)
+	SetState(state State) error
 	GetState() State
 	SetLogger(logger *slog.Logger)
 	GetLogger() *slog.Logger
+	IsStarting() bool
 	IsRunning() bool
+	IsDegraded() bool
+	IsFailed() bool
 	IsStopped() bool
 	IsStopping() bool
-	Register(id string, opts ...ServiceOption)
+	Register(id string, opts ...ServiceOption) *ServiceHandle
 }
@@ -3,6 +3,7 @@ package controls_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
@@ -13,6 +14,7 @@ import (
 	"github.com/phpboyscout/controls"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type StateCounters struct {
@@ -21,13 +23,38 @@ type StateCounters struct {
 	Statused atomic.Int64
 }
 
-func getNewController(ctx context.Context) (*controls.Controller, *StateCounters, *bytes.Buffer) {
+func discardLogger() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+// logBuffer collects log output that the controller may still be writing
+// while a test reads it.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func getNewController(ctx context.Context) (*controls.Controller, *StateCounters, *logBuffer) {
 	cntrs := &StateCounters{}
 	startFunc := func(_ context.Context) error { cntrs.Started.Add(1); return nil }
 	stopFunc := func(_ context.Context) { cntrs.Stopped.Add(1) }
 	statusFunc := func() { cntrs.Statused.Add(1); time.Sleep(500 * time.Microsecond) }
 
-	var buf bytes.Buffer
+	var buf logBuffer
 	logger := slog.New(slog.NewTextHandler(&buf, nil))
 
 	c := controls.NewController(ctx, controls.WithLogger(logger))
@@ -58,7 +85,8 @@ func TestController_Controls(t *testing.T) {
 		c.Start()
 
 		assert.True(t, c.IsRunning())
-		c.Messages() <- controls.Status
+		_, err := c.Status(context.Background())
+		require.NoError(t, err)
 		assert.Equal(t, int64(1), cntrs.Statused.Load())
 		assert.True(t, c.IsRunning())
 	})
@@ -69,12 +97,26 @@ func TestController_Controls(t *testing.T) {
 
 		assert.True(t, c.IsRunning())
 		for i := 1; i <= 3; i++ {
-			c.Messages() <- controls.Status
+			_, err := c.Status(context.Background())
+			require.NoError(t, err)
 			assert.Equal(t, int64(i), cntrs.Statused.Load())
 		}
 		assert.True(t, c.IsRunning())
 	})
 
+	t.Run("status message", func(t *testing.T) {
+		c, cntrs, _ := getNewController(context.Background())
+		c.Start()
+
+		c.Messages() <- controls.Status
+
+		// The control loop handles one message at a time, so once Status
+		// returns the message sent before it has been handled too.
+		_, err := c.Status(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), cntrs.Statused.Load())
+	})
+
 	t.Run("stop running controller", func(t *testing.T) {
 		c, cntrs, _ := getNewController(context.Background())
 		c.Start()
@@ -82,9 +124,9 @@ func TestController_Controls(t *testing.T) {
 		assert.True(t, c.IsRunning())
 		c.Messages() <- controls.Stop
 
-		assert.Eventually(t, func() bool {
-			return cntrs.Stopped.Load() == int64(1)
-		}, 1*time.Second, 10*time.Millisecond)
+		// Stop waits for the shutdown the message began to finish.
+		require.NoError(t, c.Stop())
+		assert.Equal(t, int64(1), cntrs.Stopped.Load())
 		assert.True(t, c.IsStopped())
 	})
 
@@ -117,15 +159,33 @@ func TestController_WaitGroup(t *testing.T) {
 }
 
 func TestController_SetState(t *testing.T) {
+	rec := &eventRecorder{}
 	c, _, _ := getNewController(context.Background())
-	c.SetState(controls.Running)
+	c.AddEventHandler(rec.handle)
+
+	require.NoError(t, c.SetState(controls.Running))
 	assert.True(t, c.IsRunning())
 
-	c.SetState(controls.Stopping)
+	require.NoError(t, c.SetState(controls.Stopping))
 	assert.True(t, c.IsStopping())
 
-	c.SetState(controls.Stopped)
+	err := c.SetState(controls.Running)
+	require.ErrorIs(t, err, controls.ErrInvalidTransition)
+	assert.EqualError(t, err, "invalid state transition: stopping to running")
+	assert.True(t, c.IsStopping())
+
+	require.NoError(t, c.SetState(controls.Stopped))
 	assert.True(t, c.IsStopped())
+
+	require.ErrorIs(t, c.SetState(controls.Running), controls.ErrInvalidTransition)
+	require.NoError(t, c.SetState(controls.Stopped))
+
+	changes := rec.ofType(controls.StateChanged)
+	require.Len(t, changes, 3)
+	assert.Equal(t, controls.Unknown, changes[0].Previous)
+	assert.Equal(t, controls.Running, changes[1].Previous)
+	assert.Equal(t, controls.Stopping, changes[2].Previous)
+	assert.Equal(t, controls.Stopped, changes[2].State)
 }
 
 func TestController_Errors(t *testing.T) {
@@ -162,26 +222,111 @@ func TestController_SetMessageChannels(t *testing.T) {
 	c, _, _ := getNewController(context.Background())
 	msgs := make(chan controls.Message)
 	c.SetMessageChannel(msgs)
-	assert.Equal(t, msgs, c.Messages())
+	assert.Equal(t, (chan<- controls.Message)(msgs), c.Messages())
 }
 
 func TestController_Health(t *testing.T) {
 	c, _, _ := getNewController(context.Background())
-	health := make(chan controls.HealthMessage)
+	health := make(chan controls.HealthMessage, 1)
 	c.SetHealthChannel(health)
 
-	go func(t *testing.T, health chan controls.HealthMessage) {
-		h := <-health
-		assert.Equal(t, "testHost", h.Host)
-		assert.Equal(t, 1, h.Port)
-		assert.Equal(t, 2, h.Status)
-		assert.Equal(t, "testMessage", h.Message)
-	}(t, health)
-
-	c.Health() <- controls.HealthMessage{
+	c.ReportHealth(controls.HealthMessage{
 		Host:    "testHost",
 		Port:    1,
-		Status:  2,
+		Status:  controls.HealthDegraded,
 		Message: "testMessage",
-	}
+	})
+
+	h := <-health
+	assert.Equal(t, "testHost", h.Host)
+	assert.Equal(t, 1, h.Port)
+	assert.Equal(t, controls.HealthDegraded, h.Status)
+	assert.Equal(t, "testMessage", h.Message)
+	assert.False(t, h.Timestamp.IsZero())
+}
+
+func TestController_SettersRejectedAfterStart(t *testing.T) {
+	c, _, output := getNewController(context.Background())
+	msgs := c.Messages()
+	errs := c.Errors()
+
+	c.Start()
+	c.SetMessageChannel(make(chan controls.Message))
+	c.SetErrorsChannel(make(chan error))
+	c.SetWaitGroup(&sync.WaitGroup{})
+
+	assert.Equal(t, msgs, c.Messages())
+	assert.Equal(t, errs, c.Errors())
+
+	require.NoError(t, c.Stop())
+	require.NoError(t, c.Close())
+	assert.Contains(t, output.String(), controls.ErrAlreadyStarted.Error())
+}
+
+func TestController_StopReturnsErrors(t *testing.T) {
+	errCleanup := errors.New("cleanup failed")
+
+	c := controls.NewController(context.Background(), controls.WithLogger(discardLogger()), controls.WithoutSignals())
+	c.Register("clean",
+		controls.WithStart(func(_ context.Context) error { return nil }),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+	c.Register("dirty",
+		controls.WithStart(func(_ context.Context) error { return nil }),
+		controls.WithStopErr(func(_ context.Context) error { return errCleanup }),
+	)
+
+	c.Start()
+
+	err := c.Stop()
+	require.ErrorIs(t, err, errCleanup)
+	assert.Contains(t, err.Error(), "dirty")
+	assert.True(t, c.IsStopped())
+
+	assert.Equal(t, err, c.Stop())
+	assert.ErrorIs(t, c.Report().Services[1].LastError, errCleanup)
+}
+
+func TestController_StartStopErrors(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithShutdownTimeout(10*time.Millisecond),
+	)
+
+	require.ErrorIs(t, c.Start(), controls.ErrNoServices)
+	assert.Equal(t, controls.Unknown, c.GetState())
+	require.ErrorIs(t, c.Stop(), controls.ErrNotStarted)
+
+	c.Register("stubborn",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(ctx context.Context) { <-ctx.Done() }),
+	)
+
+	require.NoError(t, c.Start())
+	require.ErrorIs(t, c.Start(), controls.ErrAlreadyRunning)
+
+	err := c.Stop()
+	require.ErrorIs(t, err, controls.ErrShutdownTimeout)
+	assert.True(t, c.IsStopped())
+	require.ErrorIs(t, c.Start(), controls.ErrAlreadyRunning)
+}
+
+func TestController_Controllable(t *testing.T) {
+	var c controls.Controllable = controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+
+	c.SetShutdownTimeout(10 * time.Millisecond)
+	h := c.Register("stubborn",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(ctx context.Context) { <-ctx.Done() }),
+	)
+	require.NotNil(t, h)
+
+	require.NoError(t, c.Start())
+	assert.True(t, c.IsRunning())
+	require.ErrorIs(t, c.Stop(), controls.ErrShutdownTimeout)
+	assert.True(t, c.IsStopped())
 }
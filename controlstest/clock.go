@@ -0,0 +1,133 @@
+package controlstest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/phpboyscout/controls"
+)
+
+var _ controls.Clock = (*FakeClock)(nil)
+
+// FakeClock is a controls.Clock that only moves when Advance is called, for
+// testing timeouts and intervals without real sleeps.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+	tickers []*fakeTicker
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+type fakeTicker struct {
+	clock  *FakeClock
+	every  time.Duration
+	next   time.Time
+	ch     chan time.Time
+	closed bool
+}
+
+// NewFakeClock returns a FakeClock reading now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+func (f *FakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	f.waiters = append(f.waiters, fakeWaiter{at: f.now.Add(d), ch: ch})
+
+	return ch
+}
+
+func (f *FakeClock) NewTicker(d time.Duration) controls.Ticker {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t := &fakeTicker{clock: f, every: d, next: f.now.Add(d), ch: make(chan time.Time, 1)}
+	f.tickers = append(f.tickers, t)
+
+	return t
+}
+
+// Waiters returns how many After channels have yet to fire.
+func (f *FakeClock) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.waiters)
+}
+
+// TickersStopped reports whether every ticker created by the clock has been
+// stopped.
+func (f *FakeClock) TickersStopped() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, t := range f.tickers {
+		if !t.closed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Advance moves the clock forward by d, firing any After channels and tickers
+// that fall due. Like time.Ticker, ticks are dropped if the last one hasn't
+// been received.
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+
+	waiters := f.waiters[:0]
+
+	for _, w := range f.waiters {
+		if w.at.After(f.now) {
+			waiters = append(waiters, w)
+
+			continue
+		}
+
+		w.ch <- f.now
+	}
+
+	f.waiters = waiters
+
+	for _, t := range f.tickers {
+		for !t.closed && !t.next.After(f.now) {
+			select {
+			case t.ch <- f.now:
+			default:
+			}
+
+			t.next = t.next.Add(t.every)
+		}
+	}
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.closed = true
+}
@@ -0,0 +1,300 @@
+// Package controlstest provides test doubles for applications built on the
+// controls package.
+package controlstest
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+)
+
+var _ controls.Controllable = (*FakeController)(nil)
+
+// FakeController is a controls.Controller that records what happens to the
+// services registered with it, so application wiring can be unit tested. It
+// runs services exactly as a Controller does, with the same API and errors,
+// but ignores OS signals and discards its logs unless opts say otherwise.
+// Services registered with Register, RegisterService or RegisterAll have
+// their starts, stops and status checks counted, and every event the
+// controller emits is captured.
+type FakeController struct {
+	*controls.Controller
+
+	mu       sync.Mutex
+	services []controls.Service
+	started  map[string]int
+	stopped  map[string]int
+	statuses map[string]int
+	errors   []error
+	events   []controls.Event
+}
+
+// NewFakeController returns a FakeController in the Unknown state. opts are
+// applied after its defaults, so WithClock can hand it a FakeClock.
+func NewFakeController(ctx context.Context, opts ...controls.ControllerOpt) *FakeController {
+	f := &FakeController{
+		started:  map[string]int{},
+		stopped:  map[string]int{},
+		statuses: map[string]int{},
+	}
+
+	f.Controller = controls.NewController(ctx, append([]controls.ControllerOpt{
+		controls.WithoutSignals(),
+		controls.WithLogger(slog.New(slog.DiscardHandler)),
+		controls.WithEventHandler(f.record),
+	}, opts...)...)
+
+	return f
+}
+
+// Register registers the service as Controller.Register does, counting its
+// starts, stops and status checks.
+func (f *FakeController) Register(id string, opts ...controls.ServiceOption) *controls.ServiceHandle {
+	h := f.Controller.Register(id, append(slices.Clone(opts), f.counting(id))...)
+	if h != nil {
+		s := controls.Service{Name: id}
+		for _, opt := range opts {
+			opt(&s)
+		}
+
+		f.remember(s)
+	}
+
+	return h
+}
+
+// RegisterService registers s as Controller.RegisterService does, counting
+// its starts, stops and status checks.
+func (f *FakeController) RegisterService(s controls.Service) *controls.ServiceHandle {
+	counted := s
+	f.counting(s.Name)(&counted)
+
+	h := f.Controller.RegisterService(counted)
+	if h != nil {
+		f.remember(s)
+	}
+
+	return h
+}
+
+// RegisterAll registers services as Controller.RegisterAll does, counting
+// their starts, stops and status checks.
+func (f *FakeController) RegisterAll(services ...controls.Service) error {
+	counted := make([]controls.Service, len(services))
+
+	for i, s := range services {
+		f.counting(s.Name)(&s)
+		counted[i] = s
+	}
+
+	if err := f.Controller.RegisterAll(counted...); err != nil {
+		return err
+	}
+
+	for _, s := range services {
+		f.remember(s)
+	}
+
+	return nil
+}
+
+func (f *FakeController) remember(s controls.Service) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.services = append(f.services, s)
+}
+
+// counting wraps the service's functions to count how often they are called
+// and keep the errors its StartFunc returns.
+func (f *FakeController) counting(name string) controls.ServiceOption {
+	return func(s *controls.Service) {
+		if start := s.Start; start != nil {
+			s.Start = func(ctx context.Context) error {
+				f.count(f.started, name)
+
+				err := start(ctx)
+				if err != nil {
+					f.mu.Lock()
+					f.errors = append(f.errors, err)
+					f.mu.Unlock()
+				}
+
+				return err
+			}
+		}
+
+		if stop := s.Stop; stop != nil {
+			s.Stop = func(ctx context.Context) {
+				defer f.count(f.stopped, name)
+
+				stop(ctx)
+			}
+		}
+
+		if stopErr := s.StopErr; stopErr != nil {
+			s.StopErr = func(ctx context.Context) error {
+				defer f.count(f.stopped, name)
+
+				return stopErr(ctx)
+			}
+		}
+
+		if status := s.Status; status != nil {
+			s.Status = func() {
+				defer f.count(f.statuses, name)
+
+				status()
+			}
+		}
+
+		if status := s.StatusCtx; status != nil {
+			s.StatusCtx = func(ctx context.Context) {
+				defer f.count(f.statuses, name)
+
+				status(ctx)
+			}
+		}
+
+		if check := s.HealthCheck; check != nil {
+			s.HealthCheck = func(ctx context.Context) error {
+				defer f.count(f.statuses, name)
+
+				return check(ctx)
+			}
+		}
+	}
+}
+
+func (f *FakeController) count(counts map[string]int, name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	counts[name]++
+}
+
+// Registered returns the services registered through the FakeController, in
+// registration order and as they were given to it.
+func (f *FakeController) Registered() []controls.Service {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.services)
+}
+
+// StartCount returns how many times the named service's StartFunc was called.
+func (f *FakeController) StartCount(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.started[name]
+}
+
+// StopCount returns how many times the named service's stop function
+// returned.
+func (f *FakeController) StopCount(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.stopped[name]
+}
+
+// StatusCount returns how many status checks of the named service finished.
+func (f *FakeController) StatusCount(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.statuses[name]
+}
+
+// StartErrors returns the errors returned by StartFuncs.
+func (f *FakeController) StartErrors() []error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.errors)
+}
+
+// Events returns every event the controller has emitted so far.
+func (f *FakeController) Events() []controls.Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.events)
+}
+
+// AssertRegistered fails t unless a service called name was registered.
+func (f *FakeController) AssertRegistered(t testing.TB, name string) bool {
+	t.Helper()
+
+	if !slices.ContainsFunc(f.Registered(), func(s controls.Service) bool { return s.Name == name }) {
+		t.Errorf("service %q was not registered", name)
+
+		return false
+	}
+
+	return true
+}
+
+// AssertStarted fails t unless the named service was started.
+func (f *FakeController) AssertStarted(t testing.TB, name string) bool {
+	t.Helper()
+
+	if f.StartCount(name) == 0 {
+		t.Errorf("service %q was not started", name)
+
+		return false
+	}
+
+	return true
+}
+
+// AssertNotStarted fails t if the named service was started.
+func (f *FakeController) AssertNotStarted(t testing.TB, name string) bool {
+	t.Helper()
+
+	if n := f.StartCount(name); n != 0 {
+		t.Errorf("service %q was started %d times", name, n)
+
+		return false
+	}
+
+	return true
+}
+
+// AssertStopped fails t unless the named service was stopped.
+func (f *FakeController) AssertStopped(t testing.TB, name string) bool {
+	t.Helper()
+
+	if f.StopCount(name) == 0 {
+		t.Errorf("service %q was not stopped", name)
+
+		return false
+	}
+
+	return true
+}
+
+// AssertNotStopped fails t if the named service was stopped.
+func (f *FakeController) AssertNotStopped(t testing.TB, name string) bool {
+	t.Helper()
+
+	if n := f.StopCount(name); n != 0 {
+		t.Errorf("service %q was stopped %d times", name, n)
+
+		return false
+	}
+
+	return true
+}
+
+func (f *FakeController) record(event controls.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.events = append(f.events, event)
+}
@@ -0,0 +1,169 @@
+package controlstest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/controlstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errBoom = errors.New("boom")
+
+// calls records what the wired services did, from whichever goroutine the
+// controller runs them on.
+type calls struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (c *calls) add(call string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.list = append(c.list, call)
+}
+
+func (c *calls) all() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.list...)
+}
+
+// wire is the kind of application code FakeController is meant to test.
+func wire(c controls.Controllable, calls *calls) *controls.ServiceHandle {
+	c.Register("db",
+		controls.WithStart(func(_ context.Context) error { calls.add("start db"); return nil }),
+		controls.WithStop(func(_ context.Context) { calls.add("stop db") }),
+		controls.WithStatus(func() { calls.add("status db") }),
+		controls.WithMessageHandler(func(msg controls.Message) error {
+			calls.add("message " + string(msg))
+
+			return nil
+		}),
+	)
+
+	return c.Register("api",
+		controls.WithStart(func(ctx context.Context) error {
+			calls.add("start api")
+			controls.Ready(ctx)
+			<-ctx.Done()
+
+			return nil
+		}),
+		controls.WithStop(func(_ context.Context) { calls.add("stop api") }),
+		controls.WithDependsOn("db"),
+	)
+}
+
+func TestFakeController(t *testing.T) {
+	var calls calls
+
+	f := controlstest.NewFakeController(context.Background())
+	api := wire(f, &calls)
+	require.NotNil(t, api)
+
+	f.AssertRegistered(t, "db")
+	f.AssertNotStarted(t, "api")
+
+	require.NoError(t, f.Start(), "a blocking StartFunc that is ready doesn't hold up Start")
+	assert.True(t, f.IsRunning())
+	assert.Equal(t, controls.Running, api.State())
+	f.AssertStarted(t, "db")
+	f.AssertStarted(t, "api")
+	f.AssertNotStopped(t, "db")
+	assert.Empty(t, f.StartErrors())
+
+	_, err := f.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.StatusCount("db"))
+
+	f.Messages() <- "flush"
+	require.NoError(t, f.Send("purge"))
+	require.NoError(t, f.Send(controls.Status))
+
+	require.NoError(t, f.Stop())
+	assert.True(t, f.IsStopped())
+	f.AssertStopped(t, "db")
+	f.AssertStopped(t, "api")
+
+	assert.ElementsMatch(t, []string{"start db", "start api"}, calls.all()[:2])
+	assert.Equal(t, []string{
+		"status db", "message flush", "message purge", "status db", "stop api", "stop db",
+	}, calls.all()[2:])
+	assert.Equal(t, 2, f.StatusCount("db"))
+
+	events := f.Events()
+	require.NotEmpty(t, events)
+	assert.Equal(t, controls.StateChanged, events[len(events)-1].Type)
+	assert.Equal(t, controls.Stopped, events[len(events)-1].State)
+	require.ErrorIs(t, f.Send(controls.Status), controls.ErrNotRunning)
+}
+
+func TestFakeController_StartErrors(t *testing.T) {
+	f := controlstest.NewFakeController(context.Background())
+	f.Register("api", controls.WithStart(func(_ context.Context) error { return errBoom }))
+
+	require.NoError(t, f.Start())
+	require.Len(t, f.StartErrors(), 1)
+	require.ErrorIs(t, f.StartErrors()[0], errBoom)
+	require.NoError(t, f.Close())
+}
+
+func TestFakeController_Parity(t *testing.T) {
+	f := controlstest.NewFakeController(context.Background())
+	require.ErrorIs(t, f.Stop(), controls.ErrNotStarted)
+	require.ErrorIs(t, f.Start(), controls.ErrNoServices)
+
+	f.RegisterService(controls.Service{Name: "svc", Start: func(_ context.Context) error { return nil }})
+	assert.Nil(t, f.Register("svc"), "the name is already taken")
+	assert.Len(t, f.Registered(), 1)
+}
+
+func TestFakeController_Clock(t *testing.T) {
+	clock := controlstest.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	f := controlstest.NewFakeController(context.Background(), controls.WithClock(clock))
+	f.Register("svc", controls.WithStart(func(_ context.Context) error { return nil }))
+
+	require.NoError(t, f.Start())
+	require.NoError(t, f.Stop())
+
+	require.NotEmpty(t, f.Events())
+
+	for _, e := range f.Events() {
+		assert.Equal(t, clock.Now(), e.Time, e.Type)
+	}
+}
+
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestFakeController_Assertions(t *testing.T) {
+	f := controlstest.NewFakeController(context.Background())
+	f.Register("svc")
+
+	rec := &recordingT{TB: t}
+	assert.False(t, f.AssertStarted(rec, "svc"))
+	assert.False(t, f.AssertRegistered(rec, "missing"))
+	assert.Equal(t, []string{
+		`service "svc" was not started`,
+		`service "missing" was not registered`,
+	}, rec.errors)
+}
@@ -0,0 +1,145 @@
+package controls
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultShutdownTurnTimeout bounds how long a controller waits for its
+	// turn to stop before stopping anyway.
+	DefaultShutdownTurnTimeout = 5 * time.Minute
+	// turnReleaseTimeout bounds giving a turn back once stopped.
+	turnReleaseTimeout = 5 * time.Second
+)
+
+// ShutdownCoordinator hands out turns to stop among the instances of an
+// application, so a fleet asked to restart together stops a few at a time.
+// The redis package provides an implementation.
+type ShutdownCoordinator interface {
+	// Acquire blocks until this instance may stop, or ctx is done, and
+	// returns a function giving the turn back.
+	Acquire(ctx context.Context) (release func(context.Context) error, err error)
+}
+
+// WithShutdownCoordinator makes the controller wait for a turn from sc before
+// stopping when it is signalled, asked to stop or upgraded. It keeps running,
+// and ready, while it waits, and gives the turn back once its services have
+// stopped. If no turn comes within timeout, DefaultShutdownTurnTimeout when
+// zero, or sc fails, it stops anyway. A further signal or the cancellation of
+// its context while waiting stops it straight away. A failed service and a
+// drain stop without waiting.
+func WithShutdownCoordinator(sc ShutdownCoordinator, timeout time.Duration) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			if timeout <= 0 {
+				timeout = DefaultShutdownTurnTimeout
+			}
+
+			ctrl.coordinator = sc
+			ctrl.turn.timeout = timeout
+		})
+	}
+}
+
+// turn is the controller's place in a coordinated shutdown.
+type turn struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	skip    context.CancelFunc
+	release func(context.Context) error
+}
+
+// coordinated reports whether a stop for reason waits for a turn.
+func (c *Controller) coordinated(reason StopReason) bool {
+	if c.coordinator == nil || !c.isUp() {
+		return false
+	}
+
+	switch reason {
+	case StopSignalled, StopRequested, StopUpgraded:
+		return true
+	default:
+		return false
+	}
+}
+
+// stopInTurn waits in the background for a turn and then asks the control
+// loop to stop.
+func (c *Controller) stopInTurn(o origin) {
+	ctx, cancel := c.withTimeout(c.ctx, c.turn.timeout)
+
+	c.turn.mu.Lock()
+	c.turn.skip = cancel
+	c.turn.mu.Unlock()
+
+	c.log().Info("Waiting for a turn to stop", "timeout", c.turn.timeout)
+
+	go func() {
+		defer cancel()
+
+		c.awaitTurn(ctx)
+
+		if !c.moveTo(Stopping) {
+			c.endTurn()
+
+			return
+		}
+
+		c.sendMessage(Stop, o, nil)
+	}()
+}
+
+func (c *Controller) awaitTurn(ctx context.Context) {
+	release, err := c.coordinator.Acquire(ctx)
+
+	c.turn.mu.Lock()
+	defer c.turn.mu.Unlock()
+
+	c.turn.skip = nil
+
+	if err != nil {
+		c.log().Warn("Stopping without a turn", "error", err)
+
+		return
+	}
+
+	c.turn.release = release
+}
+
+// skipTurn stops waiting for a turn, reporting whether the controller was.
+func (c *Controller) skipTurn() bool {
+	c.turn.mu.Lock()
+	defer c.turn.mu.Unlock()
+
+	if c.turn.skip == nil {
+		return false
+	}
+
+	c.log().Warn("Stopping without waiting for a turn")
+	c.turn.skip()
+	c.turn.skip = nil
+
+	return true
+}
+
+// endTurn gives back the turn the controller stopped in.
+func (c *Controller) endTurn() {
+	c.turn.mu.Lock()
+	release := c.turn.release
+	c.turn.release = nil
+	c.turn.mu.Unlock()
+
+	if release == nil {
+		return
+	}
+
+	ctx, cancel := c.withTimeout(context.Background(), turnReleaseTimeout)
+	defer cancel()
+
+	if err := release(ctx); err != nil {
+		c.log().Error("Failed to give back the turn to stop", "error", err)
+	}
+}
@@ -0,0 +1,132 @@
+package controls_test
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// turns is a ShutdownCoordinator handing out one turn at a time.
+type turns struct {
+	held     chan struct{}
+	released atomic.Int32
+}
+
+func newTurns() *turns {
+	return &turns{held: make(chan struct{}, 1)}
+}
+
+func (tr *turns) Acquire(ctx context.Context) (func(context.Context) error, error) {
+	select {
+	case tr.held <- struct{}{}:
+		return func(_ context.Context) error {
+			tr.released.Add(1)
+			<-tr.held
+
+			return nil
+		}, nil
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+}
+
+func coordinatedController(t *testing.T, sc controls.ShutdownCoordinator, timeout time.Duration) *controls.Controller {
+	t.Helper()
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithShutdownCoordinator(sc, timeout),
+	)
+	c.Register("svc", controls.WithStart(noopStart))
+	require.NoError(t, c.Start())
+
+	return c
+}
+
+func TestController_ShutdownCoordinator(t *testing.T) {
+	tr := newTurns()
+	tr.held <- struct{}{}
+
+	c := coordinatedController(t, tr, time.Minute)
+
+	stopped := make(chan error, 1)
+
+	go func() { stopped <- c.Stop() }()
+
+	time.Sleep(20 * time.Millisecond)
+	assert.True(t, c.IsRunning(), "stopped before its turn")
+	require.NoError(t, c.CheckReady())
+
+	<-tr.held
+
+	select {
+	case err := <-stopped:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Stop did not return after the turn was given")
+	}
+
+	assert.Equal(t, int32(1), tr.released.Load())
+	assert.Empty(t, tr.held, "turn not given back")
+}
+
+func TestController_ShutdownCoordinator_Timeout(t *testing.T) {
+	tr := newTurns()
+	tr.held <- struct{}{}
+
+	c := coordinatedController(t, tr, 20*time.Millisecond)
+
+	require.NoError(t, c.Stop())
+	assert.Zero(t, tr.released.Load())
+}
+
+func TestController_ShutdownCoordinator_SecondSignal(t *testing.T) {
+	tr := newTurns()
+	tr.held <- struct{}{}
+
+	signals := make(chan os.Signal, 2)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithShutdownCoordinator(tr, time.Minute),
+	)
+	c.SetSignalsChannel(signals)
+	c.Register("svc", controls.WithStart(noopStart))
+	require.NoError(t, c.Start())
+
+	signals <- syscall.SIGTERM
+
+	time.Sleep(20 * time.Millisecond)
+	assert.True(t, c.IsRunning(), "stopped before its turn")
+
+	signals <- syscall.SIGTERM
+
+	require.NoError(t, c.Wait())
+	assert.Eventually(t, c.IsStopped, time.Second, time.Millisecond)
+	assert.Equal(t, controls.StopSignalled, c.StopReason())
+}
+
+func TestController_ShutdownCoordinator_ServiceFailure(t *testing.T) {
+	tr := newTurns()
+	tr.held <- struct{}{}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithStopOnError(),
+		controls.WithShutdownCoordinator(tr, time.Minute),
+	)
+	c.Register("svc", controls.WithStart(func(_ context.Context) error { return assert.AnError }))
+	require.NoError(t, c.Start())
+
+	assert.Eventually(t, c.IsStopped, time.Second, time.Millisecond)
+}
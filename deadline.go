@@ -0,0 +1,163 @@
+package controls
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrShutdownDeadline is returned by Stop when services were still stopping
+// once the shutdown deadline passed.
+var ErrShutdownDeadline = errors.New("shutdown deadline exceeded")
+
+// ErrSecondSignal is returned by Stop when a second signal cut a graceful
+// shutdown short.
+var ErrSecondSignal = errors.New("second signal received")
+
+// WithShutdownDeadline bounds the whole shutdown. Unlike the shutdown timeout,
+// which only cancels the context given to StopFuncs, once d passes the
+// controller gives up on services that ignore their context: it logs them,
+// marks itself Stopped and releases Wait, leaving them to finish in the
+// background.
+func WithShutdownDeadline(d time.Duration) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.shutdownDeadline = d
+		})
+	}
+}
+
+// WithStackDump writes every goroutine's stack to w when the shutdown deadline
+// is exceeded, to show where stuck services are blocked.
+func WithStackDump(w io.Writer) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.stackDump = w
+		})
+	}
+}
+
+// WithForcedExit calls fn with the names of the services still stopping when
+// the shutdown deadline is exceeded, for example to call os.Exit.
+func WithForcedExit(fn func(remaining []string)) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.forcedExit = fn
+		})
+	}
+}
+
+// WithoutSecondSignalTermination keeps a graceful shutdown going when another
+// signal arrives. By default a second SIGINT or SIGTERM abandons it the same
+// way an exceeded shutdown deadline does.
+func WithoutSecondSignalTermination() ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.secondSignal = false
+		})
+	}
+}
+
+// stopWithDeadline runs stop, abandoning it with forceStop if the shutdown
+// deadline passes first.
+func (c *Controller) stopWithDeadline(stop func()) {
+	if c.shutdownDeadline <= 0 {
+		stop()
+
+		return
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		stop()
+	}()
+
+	select {
+	case <-done:
+	case <-c.clock.After(c.shutdownDeadline):
+		c.forceStop(ErrShutdownDeadline)
+	}
+}
+
+// forceStop abandons the services still stopping, reporting cause from Stop.
+// Only the first call has any effect.
+func (c *Controller) forceStop(cause error) {
+	c.forced.Do(func() {
+		c.abandon(cause)
+	})
+}
+
+func (c *Controller) abandon(cause error) {
+	remaining := c.services.halting.names()
+
+	c.log().Error(cause.Error(), "remaining", remaining)
+
+	if c.stackDump != nil {
+		if err := pprof.Lookup("goroutine").WriteTo(c.stackDump, 2); err != nil {
+			c.log().Error("Failed to dump goroutine stacks", "error", err)
+		}
+	}
+
+	if c.forcedExit != nil {
+		c.forcedExit(remaining)
+	}
+
+	c.releaseWaitGroup(c.tracker.outstanding())
+	c.moveTo(Stopped)
+	if len(remaining) > 0 {
+		cause = fmt.Errorf("%w: still stopping: %s", cause, strings.Join(remaining, ", "))
+	}
+
+	c.endTurn()
+	c.withdraw()
+	c.shutdown.finish(cause)
+}
+
+// halting is the set of services whose StopFunc is running. It has its own
+// lock because Services.ops is held for the whole of a shutdown.
+type halting struct {
+	mu       sync.Mutex
+	services map[string]int
+}
+
+func (h *halting) add(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.services == nil {
+		h.services = map[string]int{}
+	}
+
+	h.services[name]++
+}
+
+func (h *halting) remove(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.services[name]--; h.services[name] <= 0 {
+		delete(h.services, name)
+	}
+}
+
+func (h *halting) names() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	names := make([]string, 0, len(h.services))
+	for name := range h.services {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
@@ -0,0 +1,116 @@
+package controls_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_ShutdownDeadline(t *testing.T) {
+	var (
+		stacks    bytes.Buffer
+		remaining []string
+	)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithShutdownTimeout(10*time.Millisecond),
+		controls.WithShutdownDeadline(50*time.Millisecond),
+		controls.WithStackDump(&stacks),
+		controls.WithForcedExit(func(names []string) { remaining = names }),
+	)
+	c.Register("stuck",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) { <-release }),
+	)
+	c.Register("clean",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	c.Start()
+
+	err := c.Stop()
+	require.ErrorIs(t, err, controls.ErrShutdownDeadline)
+	assert.Contains(t, err.Error(), "stuck")
+	assert.NotContains(t, err.Error(), "clean")
+
+	assert.Equal(t, []string{"stuck"}, remaining)
+	assert.Contains(t, stacks.String(), "goroutine")
+	assert.True(t, c.IsStopped())
+
+	waited := make(chan struct{})
+
+	go func() {
+		c.Wait()
+		close(waited)
+	}()
+
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("Wait blocked on a service past the shutdown deadline")
+	}
+}
+
+func TestController_SecondSignal(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	newController := func(opts ...controls.ControllerOpt) (*controls.Controller, chan os.Signal, chan struct{}) {
+		signals := make(chan os.Signal, 1)
+		stopping := make(chan struct{})
+
+		c := controls.NewController(context.Background(), append([]controls.ControllerOpt{
+			controls.WithLogger(discardLogger()),
+			controls.WithShutdownTimeout(time.Hour),
+		}, opts...)...)
+		c.SetSignalsChannel(signals)
+		c.Register("slow",
+			controls.WithStart(noopStart),
+			controls.WithStop(func(_ context.Context) {
+				close(stopping)
+				<-release
+			}),
+		)
+		c.Start()
+
+		return c, signals, stopping
+	}
+
+	t.Run("terminates immediately", func(t *testing.T) {
+		c, signals, stopping := newController()
+
+		signals <- syscall.SIGTERM
+		<-stopping
+		signals <- syscall.SIGINT
+
+		assert.Eventually(t, c.IsStopped, time.Second, time.Millisecond)
+
+		err := c.Stop()
+		require.ErrorIs(t, err, controls.ErrSecondSignal)
+		assert.Contains(t, err.Error(), "slow")
+	})
+
+	t.Run("can be disabled", func(t *testing.T) {
+		c, signals, stopping := newController(controls.WithoutSecondSignalTermination())
+
+		signals <- syscall.SIGTERM
+		<-stopping
+		signals <- syscall.SIGINT
+
+		assert.Never(t, c.IsStopped, 50*time.Millisecond, time.Millisecond)
+	})
+}
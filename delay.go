@@ -0,0 +1,59 @@
+package controls
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// WithStartDelay postpones calling the service's StartFunc by d after it is
+// launched. A delayed service doesn't hold up Controller.Start.
+func WithStartDelay(d time.Duration) ServiceOption {
+	return func(s *Service) {
+		s.StartDelay = d
+	}
+}
+
+// WithStartJitter adds a random delay of up to d before the service's
+// StartFunc is called, on top of any WithStartDelay, to stagger services that
+// would otherwise all reconnect at once.
+func WithStartJitter(d time.Duration) ServiceOption {
+	return func(s *Service) {
+		s.StartJitter = d
+	}
+}
+
+// startDelay returns how long to wait before starting s.
+func (s *service) startDelay() time.Duration {
+	d := s.StartDelay
+	if s.StartJitter > 0 {
+		d += rand.N(s.StartJitter) //nolint:gosec
+	}
+
+	return d
+}
+
+// delay waits out the service's start delay, calling release first so the
+// wait doesn't hold up Services.start. It returns errHalted if s was stopped
+// while waiting.
+func (q *Services) delay(ctx context.Context, s *service, release func()) error {
+	d := s.startDelay()
+	if d <= 0 {
+		return nil
+	}
+
+	release()
+
+	after := time.After
+	if q.after != nil {
+		after = q.after
+	}
+
+	select {
+	case <-after(d):
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+
+	return q.stillRunning(s)
+}
@@ -0,0 +1,55 @@
+package controls_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/controlstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_StartDelay(t *testing.T) {
+	clock := controlstest.NewFakeClock(epoch)
+
+	var delayed, jittered, halted atomic.Int64
+
+	count := func(n *atomic.Int64) controls.StartFunc {
+		return func(_ context.Context) error {
+			n.Add(1)
+
+			return nil
+		}
+	}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithClock(clock),
+	)
+	c.Register("delayed", controls.WithStart(count(&delayed)), controls.WithStop(func(_ context.Context) {}),
+		controls.WithStartDelay(time.Minute))
+	c.Register("jittered", controls.WithStart(count(&jittered)), controls.WithStop(func(_ context.Context) {}),
+		controls.WithStartDelay(time.Minute), controls.WithStartJitter(30*time.Second))
+	c.Register("halted", controls.WithStart(count(&halted)), controls.WithStop(func(_ context.Context) {}),
+		controls.WithStartDelay(time.Minute))
+
+	c.Start()
+	require.Eventually(t, func() bool { return clock.Waiters() == 3 }, time.Second, time.Millisecond)
+	assert.Zero(t, delayed.Load()+jittered.Load()+halted.Load())
+
+	require.NoError(t, c.StopService("halted"))
+
+	clock.Advance(time.Minute)
+	require.Eventually(t, func() bool { return delayed.Load() == 1 }, time.Second, time.Millisecond)
+
+	clock.Advance(30 * time.Second)
+	require.Eventually(t, func() bool { return jittered.Load() == 1 }, time.Second, time.Millisecond)
+
+	require.NoError(t, c.Stop())
+	assert.Zero(t, halted.Load())
+}
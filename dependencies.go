@@ -0,0 +1,116 @@
+package controls
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// DefaultStopConcurrency bounds how many independent services are stopped at
+// the same time.
+const DefaultStopConcurrency = 8
+
+// ErrPhaseConflict is returned by Start when a service depends on a service
+// in a higher phase. Higher phases stop first but dependents must stop before
+// what they depend on, so no stop order satisfies both.
+var ErrPhaseConflict = errors.New("service depends on a higher phase")
+
+// WithStopConcurrency replaces DefaultStopConcurrency as the number of
+// independent services stopped at the same time. Phases and dependencies are
+// still respected, and a limit of one stops services one at a time. A limit of
+// zero or less stops each wave of independent services all at once.
+func WithStopConcurrency(n int) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.services.stopConcurrency = n
+		})
+	}
+}
+
+// WithDependsOn declares that the service relies on the named services. A
+// service is always stopped before the services it depends on. Dependencies
+// only order shutdown: services are started together.
+func WithDependsOn(names ...string) ServiceOption {
+	return func(s *Service) {
+		s.DependsOn = append(s.DependsOn, names...)
+	}
+}
+
+// WithPhase places the service in a shutdown phase. Services in higher phases
+// are stopped before those in lower phases, so a service may only depend on
+// services in its own phase or lower ones; Start fails with ErrPhaseConflict
+// otherwise. Phases don't order startup: services are started together.
+func WithPhase(phase int) ServiceOption {
+	return func(s *Service) {
+		s.Phase = phase
+	}
+}
+
+// phaseConflicts joins an ErrPhaseConflict for every dependency on a service
+// in a higher phase.
+func (q *Services) phaseConflicts() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var errs []error
+
+	for _, s := range q.services {
+		for _, name := range s.DependsOn {
+			i := slices.IndexFunc(q.services, func(d *service) bool { return d.Name == name })
+			if i >= 0 && q.services[i].Phase > s.Phase {
+				errs = append(errs, fmt.Errorf("%w: %s (phase %d) depends on %s (phase %d)",
+					ErrPhaseConflict, s.Name, s.Phase, name, q.services[i].Phase))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// stopWaves groups services into waves that can each be stopped
+// concurrently. A service only appears once everything depending on it, and
+// every service in a higher phase, is in an earlier wave. Services caught in a
+// dependency cycle, or in a phase conflict registered after Start, are placed
+// together in the final wave.
+func stopWaves(services []*service) [][]*service {
+	remaining := slices.Clone(services)
+
+	var waves [][]*service
+
+	for len(remaining) > 0 {
+		maxPhase := remaining[0].Phase
+		for _, s := range remaining {
+			maxPhase = max(maxPhase, s.Phase)
+		}
+
+		var wave, rest []*service
+
+		for _, s := range remaining {
+			if s.Phase == maxPhase && !hasDependent(s, remaining) {
+				wave = append(wave, s)
+			} else {
+				rest = append(rest, s)
+			}
+		}
+
+		if len(wave) == 0 {
+			return append(waves, remaining)
+		}
+
+		waves = append(waves, wave)
+		remaining = rest
+	}
+
+	return waves
+}
+
+// hasDependent reports whether any service in candidates depends on s.
+func hasDependent(s *service, candidates []*service) bool {
+	for _, c := range candidates {
+		if c != s && slices.Contains(c.DependsOn, s.Name) {
+			return true
+		}
+	}
+
+	return false
+}
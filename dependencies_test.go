@@ -0,0 +1,171 @@
+package controls_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stopLog struct {
+	mu       sync.Mutex
+	started  map[string]time.Time
+	finished map[string]time.Time
+	inFlight int
+	peak     int
+}
+
+func newStopLog() *stopLog {
+	return &stopLog{started: map[string]time.Time{}, finished: map[string]time.Time{}}
+}
+
+func (l *stopLog) stop(name string) controls.StopFunc {
+	return func(_ context.Context) {
+		l.mu.Lock()
+		l.started[name] = time.Now()
+		l.inFlight++
+		l.peak = max(l.peak, l.inFlight)
+		l.mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		l.mu.Lock()
+		l.finished[name] = time.Now()
+		l.inFlight--
+		l.mu.Unlock()
+	}
+}
+
+func (l *stopLog) before(t *testing.T, first, second string) {
+	t.Helper()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	require.Contains(t, l.finished, first)
+	require.Contains(t, l.started, second)
+	assert.False(t, l.started[second].Before(l.finished[first]),
+		"%s started stopping before %s finished", second, first)
+}
+
+func stopController(t *testing.T, register func(*controls.Controller), opts ...controls.ControllerOpt) {
+	t.Helper()
+
+	c := controls.NewController(context.Background(), append([]controls.ControllerOpt{
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	}, opts...)...)
+	register(c)
+
+	c.Start()
+	c.Stop()
+	require.Eventually(t, c.IsStopped, 2*time.Second, 5*time.Millisecond)
+}
+
+func noopStart(_ context.Context) error { return nil }
+
+func TestController_StopOrdering(t *testing.T) {
+	t.Run("dependents stop first and independents stop concurrently", func(t *testing.T) {
+		log := newStopLog()
+
+		stopController(t, func(c *controls.Controller) {
+			c.Register("db", controls.WithStart(noopStart), controls.WithStop(log.stop("db")))
+			c.Register("cache", controls.WithStart(noopStart), controls.WithStop(log.stop("cache")))
+			c.Register("api", controls.WithStart(noopStart), controls.WithStop(log.stop("api")),
+				controls.WithDependsOn("db", "cache"))
+			c.Register("worker", controls.WithStart(noopStart), controls.WithStop(log.stop("worker")),
+				controls.WithDependsOn("db"))
+		})
+
+		log.before(t, "api", "db")
+		log.before(t, "worker", "db")
+		log.before(t, "api", "cache")
+		assert.GreaterOrEqual(t, log.peak, 2)
+	})
+
+	t.Run("higher phases stop first", func(t *testing.T) {
+		log := newStopLog()
+
+		stopController(t, func(c *controls.Controller) {
+			c.Register("infra", controls.WithStart(noopStart), controls.WithStop(log.stop("infra")),
+				controls.WithPhase(0))
+			c.Register("app", controls.WithStart(noopStart), controls.WithStop(log.stop("app")),
+				controls.WithPhase(1))
+		})
+
+		log.before(t, "app", "infra")
+	})
+
+	t.Run("dependency cycles still stop", func(t *testing.T) {
+		log := newStopLog()
+
+		stopController(t, func(c *controls.Controller) {
+			c.Register("a", controls.WithStart(noopStart), controls.WithStop(log.stop("a")),
+				controls.WithDependsOn("b"))
+			c.Register("b", controls.WithStart(noopStart), controls.WithStop(log.stop("b")),
+				controls.WithDependsOn("a"))
+		})
+
+		assert.Len(t, log.finished, 2)
+	})
+	t.Run("stop concurrency bounds each wave", func(t *testing.T) {
+		log := newStopLog()
+
+		stopController(t, func(c *controls.Controller) {
+			for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+				c.Register(name, controls.WithStart(noopStart), controls.WithStop(log.stop(name)))
+			}
+
+			c.Register("api", controls.WithStart(noopStart), controls.WithStop(log.stop("api")),
+				controls.WithDependsOn("a"))
+		}, controls.WithStopConcurrency(2))
+
+		assert.Len(t, log.finished, 7)
+		assert.Equal(t, 2, log.peak)
+		log.before(t, "api", "a")
+	})
+
+	t.Run("a stop concurrency of one stops services in turn", func(t *testing.T) {
+		log := newStopLog()
+
+		stopController(t, func(c *controls.Controller) {
+			for _, name := range []string{"a", "b", "c"} {
+				c.Register(name, controls.WithStart(noopStart), controls.WithStop(log.stop(name)))
+			}
+		}, controls.WithStopConcurrency(1))
+
+		assert.Len(t, log.finished, 3)
+		assert.Equal(t, 1, log.peak)
+	})
+
+	t.Run("no stop concurrency limit", func(t *testing.T) {
+		log := newStopLog()
+
+		stopController(t, func(c *controls.Controller) {
+			for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+				c.Register(name, controls.WithStart(noopStart), controls.WithStop(log.stop(name)))
+			}
+		}, controls.WithStopConcurrency(0))
+
+		assert.Equal(t, 10, log.peak)
+	})
+}
+
+func TestController_PhaseConflict(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("db", controls.WithStart(noopStart), controls.WithPhase(1))
+	c.Register("api", controls.WithStart(noopStart), controls.WithDependsOn("db"))
+
+	err := c.Start()
+	require.ErrorIs(t, err, controls.ErrPhaseConflict)
+	assert.EqualError(t, err, "service depends on a higher phase: api (phase 0) depends on db (phase 1)")
+	assert.Equal(t, controls.Unknown, c.GetState())
+}
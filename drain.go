@@ -0,0 +1,131 @@
+package controls
+
+import (
+	"context"
+	"time"
+)
+
+// Drain asks the controller to drain its services and then stop. Send it on
+// Messages or call Controller.Drain.
+const Drain Message = "drain"
+
+// Draining is the controller's state while its services drain before
+// stopping.
+const Draining State = "draining"
+
+const (
+	// DefaultDrainTimeout bounds how long draining waits for in-flight work
+	// before stopping anyway.
+	DefaultDrainTimeout = 30 * time.Second
+	// DrainPollInterval is how often in-flight counts are collected while
+	// draining.
+	DrainPollInterval = 100 * time.Millisecond
+)
+
+// DrainFunc is called when the controller starts draining and then polled
+// until it returns zero. It should stop the service accepting new work and
+// return how much work is still in flight, so it must be safe to call again.
+type DrainFunc func(ctx context.Context) int
+
+// WithDrain sets the service's drain function.
+func WithDrain(fn DrainFunc) ServiceOption {
+	return func(s *Service) {
+		s.Drain = fn
+	}
+}
+
+// WithDrainTimeout overrides DefaultDrainTimeout.
+func WithDrainTimeout(d time.Duration) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.drainTimeout = d
+		})
+	}
+}
+
+// Drain asks a running controller to drain and stop, without waiting for it.
+// Services with a drain function stop accepting new work, and the controller
+// moves to Draining until their in-flight work reaches zero or the drain
+// timeout expires. It then stops with StopDrained.
+func (c *Controller) Drain() error {
+	if !c.isUp() {
+		return ErrNotRunning
+	}
+
+	if !c.sendMessage(Drain, programmatic, nil) {
+		return ErrNotRunning
+	}
+
+	return nil
+}
+
+// handleDrainMessage drains the services from the control loop, then stops
+// the controller unless something else stopped it first.
+func (c *Controller) handleDrainMessage() {
+	if !c.isUp() || !c.moveTo(Draining) {
+		return
+	}
+
+	c.log().Warn("Draining services", "timeout", c.drainTimeout)
+
+	ctx, cancel := c.withTimeout(context.Background(), c.drainTimeout)
+	defer cancel()
+
+	if remaining := c.drainServices(ctx); remaining > 0 {
+		c.log().Warn("Drain timed out", "in_flight", remaining)
+	}
+
+	c.shutdown.stopFor(StopDrained, nil)
+
+	if c.swapState(Stopping, Draining) {
+		c.log().Warn("Stopping Services")
+	}
+
+	c.handleStopMessage()
+}
+
+// drainServices polls the drain functions until no work is in flight, ctx is
+// done or the controller stops draining, and returns the work left in
+// flight.
+func (c *Controller) drainServices(ctx context.Context) int {
+	drains := c.services.drains()
+
+	for {
+		remaining := 0
+
+		for _, drain := range drains {
+			remaining += drain(ctx)
+		}
+
+		if remaining == 0 || c.GetState() != Draining {
+			return remaining
+		}
+
+		select {
+		case <-ctx.Done():
+			return remaining
+		case <-c.clock.After(DrainPollInterval):
+		}
+	}
+}
+
+// drains returns the drain functions of the launched services, each scoped to
+// its service.
+func (q *Services) drains() []func(context.Context) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var drains []func(context.Context) int
+
+	for _, s := range q.services {
+		if s.Drain == nil || !s.launched() {
+			continue
+		}
+
+		drains = append(drains, func(ctx context.Context) int {
+			return s.Drain(q.scoped(ctx, s))
+		})
+	}
+
+	return drains
+}
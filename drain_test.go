@@ -0,0 +1,101 @@
+package controls_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_Drain(t *testing.T) {
+	var (
+		inFlight atomic.Int64
+		draining atomic.Bool
+	)
+
+	inFlight.Store(3)
+
+	stopped := make(chan struct{})
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("api",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {
+			assert.Zero(t, inFlight.Load(), "stopped before in-flight work finished")
+			close(stopped)
+		}),
+		controls.WithDrain(func(_ context.Context) int {
+			draining.Store(true)
+
+			return int(inFlight.Load())
+		}),
+	)
+
+	require.ErrorIs(t, c.Drain(), controls.ErrNotRunning)
+
+	c.Start()
+	require.NoError(t, c.Drain())
+
+	require.Eventually(t, draining.Load, time.Second, time.Millisecond)
+	assert.Equal(t, controls.Draining, c.GetState())
+	require.ErrorIs(t, c.CheckReady(), controls.ErrNotReady)
+
+	for inFlight.Load() > 0 {
+		inFlight.Add(-1)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("controller did not stop once drained")
+	}
+
+	assert.Eventually(t, c.IsStopped, time.Second, time.Millisecond)
+	assert.Equal(t, controls.StopDrained, c.StopReason())
+}
+
+func TestController_DrainTimeout(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithDrainTimeout(20*time.Millisecond),
+	)
+	c.Register("api",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithDrain(func(_ context.Context) int { return 1 }),
+	)
+
+	c.Start()
+	require.NoError(t, c.Drain())
+
+	assert.Eventually(t, c.IsStopped, time.Second, time.Millisecond)
+	assert.Equal(t, controls.StopDrained, c.StopReason())
+}
+
+func TestController_StopWhileDraining(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("api",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithDrain(func(_ context.Context) int { return 1 }),
+	)
+
+	c.Start()
+	require.NoError(t, c.Drain())
+	require.Eventually(t, func() bool { return c.GetState() == controls.Draining }, time.Second, time.Millisecond)
+
+	require.NoError(t, c.Stop())
+	assert.Equal(t, controls.StopRequested, c.StopReason())
+}
@@ -0,0 +1,26 @@
+package controls
+
+import "errors"
+
+// ErrServiceDisabled is returned when restarting a service that was disabled
+// at Start.
+var ErrServiceDisabled = errors.New("service is disabled")
+
+// WithEnabled decides at Start whether the service runs. A service for which
+// fn returns false is never started and is reported as Disabled.
+func WithEnabled(fn func() bool) ServiceOption {
+	return func(s *Service) {
+		s.Enabled = fn
+	}
+}
+
+// WithEnabledIf is WithEnabled for a decision already made, typically from
+// configuration.
+func WithEnabledIf(enabled bool) ServiceOption {
+	return WithEnabled(func() bool { return enabled })
+}
+
+// disabled reports whether the service's Enabled function turns it off.
+func (s *service) disabled() bool {
+	return s.Enabled != nil && !s.Enabled()
+}
@@ -0,0 +1,42 @@
+package controls_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_WithEnabled(t *testing.T) {
+	var started atomic.Int64
+
+	start := func(_ context.Context) error {
+		started.Add(1)
+
+		return nil
+	}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("on", controls.WithStart(start), controls.WithStop(func(_ context.Context) {}),
+		controls.WithEnabled(func() bool { return true }))
+	c.Register("off", controls.WithStart(start), controls.WithStop(func(_ context.Context) {}),
+		controls.WithEnabledIf(false))
+
+	c.Start()
+	assert.Equal(t, int64(1), started.Load())
+	assert.True(t, c.IsRunning())
+	assert.Equal(t, map[string]controls.State{"on": controls.Running, "off": controls.Disabled}, states(c))
+
+	require.ErrorIs(t, c.RestartService("off"), controls.ErrServiceDisabled)
+	assert.Equal(t, int64(1), started.Load())
+
+	require.NoError(t, c.Stop())
+	assert.Equal(t, controls.Disabled, states(c)["off"])
+}
@@ -0,0 +1,122 @@
+package controls
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultErrorHistory is how many received errors Errs keeps.
+const DefaultErrorHistory = 32
+
+// ServiceError is an error received from the errors channel, as passed to an
+// ErrorHandler. Errors returned by services are sent wrapped in one, so they
+// can be attributed. Name is empty when the error can't be attributed to a
+// service.
+type ServiceError struct {
+	Name string
+	Err  error
+	Time time.Time
+}
+
+// Error returns the service's name and the error it reported, leaving out
+// whichever of them is missing.
+func (e ServiceError) Error() string {
+	msg := "service error"
+	if e.Err != nil {
+		msg = e.Err.Error()
+	}
+
+	if e.Name == "" {
+		return msg
+	}
+
+	return e.Name + ": " + msg
+}
+
+func (e ServiceError) Unwrap() error {
+	return e.Err
+}
+
+type ErrorHandler func(ServiceError)
+
+// WithErrorHandler replaces the default handling of errors received on the
+// errors channel, which is to log them. ServiceFailed events are still
+// emitted, and WithStopOnError still applies.
+func WithErrorHandler(fn ErrorHandler) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.errorHandler = fn
+		})
+	}
+}
+
+// WithErrorHistory sets how many received errors are kept for Errs. A size of
+// zero or less keeps none, though LastError still reports the latest.
+func WithErrorHistory(size int) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.errLog.size = size
+		})
+	}
+}
+
+// Errs returns the most recent errors received on the errors channel, oldest
+// first, so they can be inspected once Wait returns.
+func (c *Controller) Errs() []error {
+	c.errLog.mu.Lock()
+	defer c.errLog.mu.Unlock()
+
+	return c.errLog.errs.all()
+}
+
+// LastError returns the most recent error received on the errors channel, or
+// nil if there has been none.
+func (c *Controller) LastError() error {
+	c.errLog.mu.Lock()
+	defer c.errLog.mu.Unlock()
+
+	return c.errLog.last
+}
+
+// errLog records the errors received on the errors channel.
+type errLog struct {
+	mu   sync.Mutex
+	size int
+	errs ring[error]
+	last error
+}
+
+func (l *errLog) add(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.errs.add(l.size, err)
+	l.last = err
+}
+
+// handleError records err and passes it to the error handler, or logs it.
+func (c *Controller) handleError(err error) {
+	c.errLog.add(err)
+
+	var se ServiceError
+	if !errors.As(err, &se) {
+		se = ServiceError{Err: err}
+	}
+
+	if se.Time.IsZero() {
+		se.Time = c.clock.Now()
+	}
+
+	if c.errorHandler == nil {
+		c.log().Error(err.Error())
+	} else {
+		c.errorHandler(se)
+	}
+
+	c.emit(Event{Type: ServiceFailed, Service: se.Name, Err: err, Message: err.Error()})
+
+	if c.stopOnError {
+		c.stopForFailure(err)
+	}
+}
@@ -0,0 +1,107 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/controlstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_WithErrorHandler(t *testing.T) {
+	errBoom := errors.New("boom") //nolint:err113
+	handled := make(chan controls.ServiceError, 2)
+
+	clock := controlstest.NewFakeClock(epoch)
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithClock(clock),
+		controls.WithErrorHandler(func(err controls.ServiceError) { handled <- err }),
+	)
+	c.Register("broken",
+		controls.WithStart(func(_ context.Context) error { return errBoom }),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+	c.Start()
+
+	select {
+	case err := <-handled:
+		require.ErrorIs(t, err, errBoom)
+		assert.Equal(t, clock.Now(), err.Time)
+	case <-time.After(time.Second):
+		t.Fatal("error handler was not called")
+	}
+
+	at := clock.Now().Add(-time.Minute)
+	c.Errors() <- controls.ServiceError{Name: "worker", Err: errBoom, Time: at}
+
+	err := <-handled
+	assert.Equal(t, "worker", err.Name)
+	assert.Equal(t, at, err.Time)
+	assert.EqualError(t, err, "worker: boom")
+
+	require.NoError(t, c.Stop())
+}
+
+func TestController_Errs(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithErrorHistory(2),
+	)
+	c.Register("svc",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+	c.Start()
+
+	assert.Empty(t, c.Errs())
+	require.NoError(t, c.LastError())
+
+	for _, msg := range []string{"one", "two", "three"} {
+		c.Errors() <- errors.New(msg) //nolint:err113
+	}
+
+	require.NoError(t, c.Stop())
+
+	// The last send returns once the error is received, not recorded.
+	assert.Eventually(t, func() bool {
+		err := c.LastError()
+
+		return err != nil && err.Error() == "three"
+	}, time.Second, time.Millisecond)
+
+	errs := c.Errs()
+	require.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], "two")
+	assert.EqualError(t, errs[1], "three")
+}
+
+func TestServiceError_Error(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name string
+		err  controls.ServiceError
+		want string
+	}{
+		{name: "named", err: controls.ServiceError{Name: "worker", Err: errBoom}, want: "worker: boom"},
+		{name: "unnamed", err: controls.ServiceError{Err: errBoom}, want: "boom"},
+		{name: "named without cause", err: controls.ServiceError{Name: "worker"}, want: "worker: service error"},
+		{name: "empty", err: controls.ServiceError{}, want: "service error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.err.Error())
+		})
+	}
+
+	assert.NoError(t, controls.ServiceError{Name: "worker"}.Unwrap())
+}
@@ -0,0 +1,214 @@
+// Package etcd registers a controls.Controller in etcd through its v3 JSON
+// gateway, for use with controls.WithRegistrar.
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/phpboyscout/controls"
+)
+
+const (
+	// DefaultAddr is a local etcd member.
+	DefaultAddr = "http://127.0.0.1:2379"
+	// DefaultPrefix is the key prefix registrations are written under.
+	DefaultPrefix = "/services"
+)
+
+// ErrRequestFailed is returned when etcd responds with an error status.
+var ErrRequestFailed = errors.New("etcd request failed")
+
+type Option func(*Registrar)
+
+// Registrar writes registrations to etcd as JSON under
+// <prefix>/<name>/<id>. A registration with a TTL is attached to a lease
+// that healthy reports keep alive; an unhealthy report revokes the lease,
+// removing the key until the next healthy report writes it again.
+type Registrar struct {
+	addr   string
+	prefix string
+	http   *http.Client
+
+	mu     sync.Mutex
+	leases map[string]string
+}
+
+// WithPrefix overrides DefaultPrefix.
+func WithPrefix(prefix string) Option {
+	return func(r *Registrar) {
+		r.prefix = strings.TrimSuffix(prefix, "/")
+	}
+}
+
+// WithHTTPClient replaces the http.Client used for requests.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(r *Registrar) {
+		r.http = hc
+	}
+}
+
+// New returns a Registrar for the etcd member at addr, DefaultAddr when
+// empty.
+func New(addr string, opts ...Option) *Registrar {
+	if addr == "" {
+		addr = DefaultAddr
+	}
+
+	r := &Registrar{
+		addr:   strings.TrimSuffix(addr, "/"),
+		prefix: DefaultPrefix,
+		http:   http.DefaultClient,
+		leases: map[string]string{},
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Key returns the key reg is written under.
+func (r *Registrar) Key(reg controls.Registration) string {
+	return r.prefix + "/" + reg.Name + "/" + reg.ID
+}
+
+type record struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Host      string   `json:"host,omitempty"`
+	Port      int      `json:"port,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	HealthURL string   `json:"health_url,omitempty"`
+}
+
+// Register writes the registration, under a lease when reg.TTL is set.
+func (r *Registrar) Register(ctx context.Context, reg controls.Registration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.register(ctx, reg)
+}
+
+func (r *Registrar) register(ctx context.Context, reg controls.Registration) error {
+	value, err := json.Marshal(record{
+		ID: reg.ID, Name: reg.Name, Host: reg.Host, Port: reg.Port, Tags: reg.Tags, HealthURL: reg.HealthURL,
+	})
+	if err != nil {
+		return err
+	}
+
+	put := map[string]string{
+		"key":   encode(r.Key(reg)),
+		"value": base64.StdEncoding.EncodeToString(value),
+	}
+
+	if reg.TTL > 0 {
+		var grant struct {
+			ID string `json:"ID"`
+		}
+
+		secs := max(int64(reg.TTL.Seconds()), 1)
+		if err := r.post(ctx, "/v3/lease/grant", map[string]string{"TTL": strconv.FormatInt(secs, 10)}, &grant); err != nil {
+			return err
+		}
+
+		put["lease"] = grant.ID
+		r.leases[reg.ID] = grant.ID
+	}
+
+	return r.post(ctx, "/v3/kv/put", put, nil)
+}
+
+// Deregister revokes the registration's lease, or deletes its key when it has
+// none.
+func (r *Registrar) Deregister(ctx context.Context, reg controls.Registration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.leases[reg.ID]; ok {
+		return r.revoke(ctx, reg)
+	}
+
+	return r.post(ctx, "/v3/kv/deleterange", map[string]string{"key": encode(r.Key(reg))}, nil)
+}
+
+// ReportHealth keeps the lease alive while the controller is healthy or
+// degraded, and revokes it otherwise.
+func (r *Registrar) ReportHealth(
+	ctx context.Context, reg controls.Registration, status controls.HealthStatus, _ string,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lease, ok := r.leases[reg.ID]
+
+	switch status {
+	case controls.Healthy, controls.HealthDegraded:
+		if !ok {
+			return r.register(ctx, reg)
+		}
+
+		return r.post(ctx, "/v3/lease/keepalive", map[string]string{"ID": lease}, nil)
+	case controls.HealthUnknown, controls.Unhealthy:
+	}
+
+	if !ok {
+		return nil
+	}
+
+	return r.revoke(ctx, reg)
+}
+
+func (r *Registrar) revoke(ctx context.Context, reg controls.Registration) error {
+	lease := r.leases[reg.ID]
+	delete(r.leases, reg.ID)
+
+	return r.post(ctx, "/v3/lease/revoke", map[string]string{"ID": lease}, nil)
+}
+
+func encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func (r *Registrar) post(ctx context.Context, path string, body, out any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.addr+path, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return fmt.Errorf("%w: %s: %s", ErrRequestFailed, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,123 @@
+package etcd_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/etcd"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type request struct {
+	path string
+	body map[string]string
+}
+
+func newServer(t *testing.T) (*httptest.Server, func() []request) {
+	t.Helper()
+
+	var (
+		mu   sync.Mutex
+		reqs []request
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{path: r.URL.Path}
+		_ = json.NewDecoder(r.Body).Decode(&req.body)
+
+		mu.Lock()
+		reqs = append(reqs, req)
+		mu.Unlock()
+
+		if r.URL.Path == "/v3/lease/grant" {
+			_, _ = w.Write([]byte(`{"ID":"42","TTL":"10"}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() []request {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]request(nil), reqs...)
+	}
+}
+
+func decode(t *testing.T, s string) string {
+	t.Helper()
+
+	b, err := base64.StdEncoding.DecodeString(s)
+	require.NoError(t, err)
+
+	return string(b)
+}
+
+func paths(reqs []request) []string {
+	out := make([]string, len(reqs))
+	for i, r := range reqs {
+		out[i] = r.path
+	}
+
+	return out
+}
+
+func TestRegistrar_Lease(t *testing.T) {
+	srv, requests := newServer(t)
+	r := etcd.New(srv.URL)
+	ctx := context.Background()
+
+	reg := controls.Registration{ID: "api-1", Name: "api", Host: "10.0.0.1", Port: 8080, TTL: 10 * time.Second}
+
+	require.NoError(t, r.Register(ctx, reg))
+	require.NoError(t, r.ReportHealth(ctx, reg, controls.Healthy, ""))
+	require.NoError(t, r.ReportHealth(ctx, reg, controls.Unhealthy, "not ready"))
+	require.NoError(t, r.ReportHealth(ctx, reg, controls.Unhealthy, "not ready"))
+	require.NoError(t, r.ReportHealth(ctx, reg, controls.HealthDegraded, ""))
+	require.NoError(t, r.Deregister(ctx, reg))
+
+	reqs := requests()
+	assert.Equal(t, []string{
+		"/v3/lease/grant", "/v3/kv/put",
+		"/v3/lease/keepalive",
+		"/v3/lease/revoke",
+		"/v3/lease/grant", "/v3/kv/put",
+		"/v3/lease/revoke",
+	}, paths(reqs))
+
+	assert.Equal(t, "10", reqs[0].body["TTL"])
+	assert.Equal(t, "/services/api/api-1", decode(t, reqs[1].body["key"]))
+	assert.JSONEq(t, `{"id":"api-1","name":"api","host":"10.0.0.1","port":8080}`, decode(t, reqs[1].body["value"]))
+	assert.Equal(t, "42", reqs[1].body["lease"])
+	assert.Equal(t, "42", reqs[2].body["ID"])
+	assert.Equal(t, "42", reqs[3].body["ID"])
+}
+
+func TestRegistrar_WithoutTTL(t *testing.T) {
+	srv, requests := newServer(t)
+	r := etcd.New(srv.URL, etcd.WithPrefix("/discovery/"))
+	ctx := context.Background()
+
+	reg := controls.Registration{ID: "api", Name: "api"}
+	assert.Equal(t, "/discovery/api/api", r.Key(reg))
+
+	require.NoError(t, r.Register(ctx, reg))
+	require.NoError(t, r.Deregister(ctx, reg))
+
+	reqs := requests()
+	assert.Equal(t, []string{"/v3/kv/put", "/v3/kv/deleterange"}, paths(reqs))
+	assert.NotContains(t, reqs[0].body, "lease")
+	assert.Equal(t, "/discovery/api/api", decode(t, reqs[1].body["key"]))
+}
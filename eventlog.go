@@ -0,0 +1,139 @@
+package controls
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+const (
+	// DefaultEventLogMaxSize is the size at which an event log is rotated.
+	DefaultEventLogMaxSize = 10 << 20
+	// DefaultEventLogBackups is how many rotated event logs are kept.
+	DefaultEventLogBackups = 3
+)
+
+type EventLogOption func(*eventLog)
+
+// WithEventLogMaxSize sets the size in bytes at which the event log is
+// rotated. Zero or less disables rotation.
+func WithEventLogMaxSize(n int64) EventLogOption {
+	return func(l *eventLog) {
+		l.maxSize = n
+	}
+}
+
+// WithEventLogBackups sets how many rotated files are kept.
+func WithEventLogBackups(n int) EventLogOption {
+	return func(l *eventLog) {
+		l.backups = n
+	}
+}
+
+// WithEventLog appends every event, such as state changes, failures, signals
+// and restarts, to path as a line of JSON, independently of the logger. The
+// file is opened for each event, so it can be moved or removed at any time.
+// Once a write would take it past DefaultEventLogMaxSize, it is renamed to
+// path.1, older files shifting up to path.<backups>, and a new file begun.
+// Failures to write are logged.
+func WithEventLog(path string, opts ...EventLogOption) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			l := &eventLog{path: path, maxSize: DefaultEventLogMaxSize, backups: DefaultEventLogBackups}
+
+			for _, opt := range opts {
+				opt(l)
+			}
+
+			ctrl.AddEventHandler(func(e Event) {
+				if err := l.write(ctrl.name, e); err != nil {
+					ctrl.log().Error("Failed to write event log", "path", path, "error", err)
+				}
+			})
+		})
+	}
+}
+
+// eventRecord is an Event as written to the event log.
+type eventRecord struct {
+	Controller string `json:"controller,omitempty"`
+	Event
+	Error string `json:"error,omitempty"`
+}
+
+type eventLog struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	backups int
+}
+
+func (l *eventLog) write(controller string, e Event) error {
+	r := eventRecord{Controller: controller, Event: e}
+	if e.Err != nil {
+		r.Error = e.Err.Error()
+	}
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.rotate(int64(len(line))); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644) //nolint:gosec // the log is not secret
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(line)
+
+	return errors.Join(err, f.Close())
+}
+
+// rotate moves the log aside when writing n more bytes would take it past the
+// maximum size. A log that is still empty is never rotated.
+func (l *eventLog) rotate(n int64) error {
+	if l.maxSize <= 0 {
+		return nil
+	}
+
+	info, err := os.Stat(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if info.Size() == 0 || info.Size()+n <= l.maxSize {
+		return nil
+	}
+
+	if l.backups <= 0 {
+		return os.Remove(l.path)
+	}
+
+	for i := l.backups - 1; i > 0; i-- {
+		err := os.Rename(l.backup(i), l.backup(i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return os.Rename(l.path, l.backup(1))
+}
+
+func (l *eventLog) backup(i int) string {
+	return fmt.Sprintf("%s.%d", l.path, i)
+}
@@ -0,0 +1,102 @@
+package controls_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readEventLog(t *testing.T, path string) []map[string]any {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var records []map[string]any
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+
+	require.NoError(t, scanner.Err())
+
+	return records
+}
+
+func TestController_WithEventLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithName("eventlog"),
+		controls.WithEventLog(path),
+	)
+	c.Register("api",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	c.Start()
+	require.NoError(t, c.RestartService("api"))
+	c.Stop()
+
+	records := readEventLog(t, path)
+	require.NotEmpty(t, records)
+
+	assert.Equal(t, "eventlog", records[0]["controller"])
+	assert.Equal(t, "state_changed", records[0]["type"])
+	assert.Equal(t, "starting", records[0]["state"])
+
+	var types []string
+	for _, r := range records {
+		types = append(types, r["type"].(string))
+	}
+
+	assert.Contains(t, types, "service_restarted")
+	assert.Contains(t, types, "resources_released")
+	assert.Equal(t, "stopped", records[len(records)-1]["state"])
+}
+
+func TestController_WithEventLogRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithEventInjection(),
+		controls.WithEventLog(path, controls.WithEventLogMaxSize(200), controls.WithEventLogBackups(2)),
+	)
+
+	for range 8 {
+		require.NoError(t, c.Inject(controls.Event{
+			Type: controls.ServiceFailed, Service: "api", Err: errors.New("boom"), //nolint:err113
+		}))
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		records := readEventLog(t, name)
+		require.NotEmpty(t, records, name)
+		assert.Equal(t, "boom", records[0]["error"])
+		assert.Equal(t, true, records[0]["synthetic"])
+
+		info, err := os.Stat(name)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(200))
+	}
+
+	assert.NoFileExists(t, path+".3")
+}
@@ -0,0 +1,98 @@
+package controls
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+const (
+	ServiceFailed     EventType = "service_failed"
+	ServiceDegraded   EventType = "service_degraded"
+	ServiceStopped    EventType = "service_stopped"
+	ServiceRestarted  EventType = "service_restarted"
+	ServiceCompleted  EventType = "service_completed"
+	ServiceUnhealthy  EventType = "service_unhealthy"
+	ServiceStalled    EventType = "service_stalled"
+	StateChanged      EventType = "state_changed"
+	SignalReceived    EventType = "signal_received"
+	ContextCancelled  EventType = "context_cancelled"
+	ResourcesReleased EventType = "resources_released"
+)
+
+// ErrInjectionDisabled is returned by Inject when the controller was not
+// created with WithEventInjection.
+var ErrInjectionDisabled = errors.New("event injection is disabled")
+
+type EventType string
+type EventHandler func(Event)
+
+// Event describes something that happened to the controller or one of its
+// services. Synthetic events are those created through Inject.
+type Event struct {
+	Type      EventType `json:"type"`
+	Service   string    `json:"service,omitempty"`
+	State     State     `json:"state,omitempty"`
+	Previous  State     `json:"previous,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	Err       error     `json:"-"`
+	Time      time.Time `json:"time"`
+	Synthetic bool      `json:"synthetic,omitempty"`
+}
+
+// WithEventHandler subscribes fn to every event emitted by the controller.
+// Handlers are called synchronously in the order they were added.
+func WithEventHandler(fn EventHandler) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.AddEventHandler(fn)
+		})
+	}
+}
+
+// WithEventInjection allows synthetic events to be pushed through the
+// event pipeline with Inject.
+func WithEventInjection() ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.injection = true
+		})
+	}
+}
+
+// AddEventHandler subscribes fn to every event emitted by the controller.
+func (c *Controller) AddEventHandler(fn EventHandler) {
+	c.eventsMutex.Lock()
+	defer c.eventsMutex.Unlock()
+
+	c.eventHandlers = append(c.eventHandlers, fn)
+}
+
+// Inject delivers a synthetic event to the registered event handlers so
+// alerting and automation can be rehearsed without touching the services.
+func (c *Controller) Inject(event Event) error {
+	if !c.injection {
+		return ErrInjectionDisabled
+	}
+
+	event.Synthetic = true
+
+	c.log().Warn("Injecting synthetic event", "type", event.Type, "service", event.Service)
+	c.emit(event)
+
+	return nil
+}
+
+func (c *Controller) emit(event Event) {
+	if event.Time.IsZero() {
+		event.Time = c.clock.Now()
+	}
+
+	c.eventsMutex.Lock()
+	handlers := slices.Clone(c.eventHandlers)
+	c.eventsMutex.Unlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
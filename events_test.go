@@ -0,0 +1,100 @@
+package controls_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []controls.Event
+}
+
+func (r *eventRecorder) handle(e controls.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, e)
+}
+
+func (r *eventRecorder) all() []controls.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]controls.Event(nil), r.events...)
+}
+
+func (r *eventRecorder) ofType(t controls.EventType) []controls.Event {
+	var out []controls.Event
+
+	for _, e := range r.all() {
+		if e.Type == t {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
+
+func TestController_Inject(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		c, _, _ := getNewController(context.Background())
+
+		err := c.Inject(controls.Event{Type: controls.ServiceFailed})
+		assert.ErrorIs(t, err, controls.ErrInjectionDisabled)
+	})
+
+	t.Run("delivers synthetic events", func(t *testing.T) {
+		rec := &eventRecorder{}
+		c := controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithEventInjection(),
+			controls.WithEventHandler(rec.handle),
+		)
+
+		require.NoError(t, c.Inject(controls.Event{Type: controls.ServiceDegraded, Service: "db"}))
+
+		events := rec.all()
+		require.Len(t, events, 1)
+		assert.Equal(t, controls.ServiceDegraded, events[0].Type)
+		assert.Equal(t, "db", events[0].Service)
+		assert.True(t, events[0].Synthetic)
+		assert.False(t, events[0].Time.IsZero())
+	})
+}
+
+func TestController_ErrorEvents(t *testing.T) {
+	rec := &eventRecorder{}
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithEventHandler(rec.handle),
+	)
+	c.Register("broken", controls.WithStart(func(_ context.Context) error {
+		return fmt.Errorf("boom") //nolint:err113
+	}), controls.WithStop(func(_ context.Context) {}))
+
+	c.Start()
+
+	assert.Eventually(t, func() bool {
+		return len(rec.ofType(controls.ServiceFailed)) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	failed := rec.ofType(controls.ServiceFailed)[0]
+	assert.False(t, failed.Synthetic)
+	assert.Equal(t, "broken", failed.Service)
+	assert.EqualError(t, failed.Err, "broken: boom")
+	assert.Equal(t, "broken: boom", failed.Message)
+
+	var se controls.ServiceError
+	require.ErrorAs(t, failed.Err, &se)
+	assert.Equal(t, "broken", se.Name)
+	assert.False(t, se.Time.IsZero())
+}
@@ -0,0 +1,92 @@
+package controls
+
+import (
+	"expvar"
+)
+
+// ExpvarPrefix namespaces the variables published by WithExpvar.
+const ExpvarPrefix = "controls."
+
+// WithExpvar publishes the controller's state, per-service states and restart
+// counts, and channel queue depths as the expvar "controls.<name>", so they
+// appear on /debug/vars. expvar offers no way to unpublish, so the controller
+// stays reachable for the life of the process; name must be unique.
+func WithExpvar(name string) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.publishExpvar(ExpvarPrefix + name)
+		})
+	}
+}
+
+type expvarService struct {
+	State          State   `json:"state"`
+	Restarts       int     `json:"restarts"`
+	HealthFailures int     `json:"health_failures"`
+	Uptime         float64 `json:"uptime_seconds"`
+	TotalUptime    float64 `json:"total_uptime_seconds"`
+	StartDuration  float64 `json:"start_seconds"`
+	StopDuration   float64 `json:"stop_seconds"`
+	LastError      string  `json:"last_error,omitempty"`
+}
+
+type expvarQueues struct {
+	Messages int `json:"messages"`
+	Health   int `json:"health"`
+	Errors   int `json:"errors"`
+	Signals  int `json:"signals"`
+}
+
+type expvarController struct {
+	State    State                    `json:"state"`
+	Uptime   float64                  `json:"uptime_seconds"`
+	Services map[string]expvarService `json:"services"`
+	Queues   expvarQueues             `json:"queues"`
+}
+
+func (c *Controller) publishExpvar(name string) {
+	if expvar.Get(name) != nil {
+		c.log().Error("Expvar already published", "name", name)
+
+		return
+	}
+
+	expvar.Publish(name, expvar.Func(func() any {
+		return c.expvar()
+	}))
+}
+
+func (c *Controller) expvar() expvarController {
+	r := c.Report()
+
+	v := expvarController{
+		State:    r.State,
+		Uptime:   r.Uptime.Seconds(),
+		Services: make(map[string]expvarService, len(r.Services)),
+		Queues: expvarQueues{
+			Messages: len(c.Messages()),
+			Health:   len(c.Health()),
+			Errors:   len(c.Errors()),
+			Signals:  len(c.Signals()),
+		},
+	}
+
+	for _, s := range r.Services {
+		es := expvarService{
+			State:          s.State,
+			Restarts:       s.Restarts,
+			HealthFailures: s.HealthFailures,
+			Uptime:         s.Uptime.Seconds(),
+			TotalUptime:    s.TotalUptime.Seconds(),
+			StartDuration:  s.StartDuration.Seconds(),
+			StopDuration:   s.StopDuration.Seconds(),
+		}
+		if s.LastError != nil {
+			es.LastError = s.LastError.Error()
+		}
+
+		v.Services[s.Name] = es
+	}
+
+	return v
+}
@@ -0,0 +1,58 @@
+package controls_test
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_WithExpvar(t *testing.T) {
+	// expvar can't unpublish, so each run needs its own name.
+	name := fmt.Sprintf("expvar-test-%d", time.Now().UnixNano())
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithExpvar(name),
+	)
+	c.Register("api", controls.WithStart(noopStart), controls.WithStop(func(_ context.Context) {}))
+
+	c.Start()
+	require.NoError(t, c.RestartService("api"))
+
+	v := expvar.Get(controls.ExpvarPrefix + name)
+	require.NotNil(t, v)
+
+	var vars struct {
+		State    string `json:"state"`
+		Services map[string]struct {
+			State    string `json:"state"`
+			Restarts int    `json:"restarts"`
+		} `json:"services"`
+		Queues map[string]int `json:"queues"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(v.String()), &vars))
+
+	assert.Equal(t, "running", vars.State)
+	assert.Equal(t, "running", vars.Services["api"].State)
+	assert.Equal(t, 1, vars.Services["api"].Restarts)
+	assert.Contains(t, vars.Queues, "messages")
+
+	assert.NotPanics(t, func() {
+		controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+			controls.WithExpvar(name),
+		)
+	})
+
+	require.NoError(t, c.Stop())
+}
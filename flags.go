@@ -0,0 +1,159 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	FlagRun   FlagState = "run"
+	FlagPause FlagState = "pause"
+	FlagStop  FlagState = "stop"
+)
+
+// ErrInvalidFlagState is returned when a flag value can't be mapped to a FlagState.
+var ErrInvalidFlagState = errors.New("invalid flag state")
+
+// FlagState is the outcome of evaluating a service's feature flag.
+type FlagState string
+
+// FlagProvider decides whether flagged services run, pause or stop. It is
+// consulted when the controller starts and whenever a Reload message is
+// processed.
+type FlagProvider interface {
+	Flag(ctx context.Context, name string) (FlagState, error)
+}
+
+// StaticFlags is a FlagProvider backed by a fixed map. Unknown flags run.
+type StaticFlags map[string]FlagState
+
+func (f StaticFlags) Flag(_ context.Context, name string) (FlagState, error) {
+	if state, ok := f[name]; ok {
+		return state, nil
+	}
+
+	return FlagRun, nil
+}
+
+// OpenFeatureFunc evaluates a string flag. It matches an OpenFeature client's
+// StringValue method once the evaluation context has been bound, e.g.
+//
+//	func(ctx context.Context, flag, def string) (string, error) {
+//		return client.StringValue(ctx, flag, def, evalCtx)
+//	}
+type OpenFeatureFunc func(ctx context.Context, flag string, defaultValue string) (string, error)
+
+// OpenFeatureFlags adapts an OpenFeature string evaluation into a
+// FlagProvider. Values are parsed with ParseFlagState.
+func OpenFeatureFlags(fn OpenFeatureFunc) FlagProvider {
+	return openFeatureFlags(fn)
+}
+
+type openFeatureFlags OpenFeatureFunc
+
+func (f openFeatureFlags) Flag(ctx context.Context, name string) (FlagState, error) {
+	value, err := f(ctx, name, string(FlagRun))
+	if err != nil {
+		return "", err
+	}
+
+	return ParseFlagState(value)
+}
+
+// ParseFlagState maps "run", "pause" and "stop", or a boolean, to a FlagState.
+func ParseFlagState(value string) (FlagState, error) {
+	switch state := FlagState(strings.ToLower(strings.TrimSpace(value))); state {
+	case FlagRun, FlagPause, FlagStop:
+		return state, nil
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidFlagState, value)
+	}
+
+	if enabled {
+		return FlagRun, nil
+	}
+
+	return FlagStop, nil
+}
+
+// WithFlagProvider consults provider for services registered WithFlag.
+func WithFlagProvider(provider FlagProvider) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.flags = provider
+		})
+	}
+}
+
+// WithFlag gates the service on the named feature flag.
+func WithFlag(name string) ServiceOption {
+	return func(s *Service) {
+		s.Flag = name
+	}
+}
+
+// evaluateFlags returns the desired state of every flagged service. Services
+// whose flag can't be evaluated are left out so they keep their current state.
+func (c *Controller) evaluateFlags(ctx context.Context) map[string]FlagState {
+	if c.flags == nil {
+		return nil
+	}
+
+	states := map[string]FlagState{}
+
+	for name, flag := range c.services.flagged() {
+		state, err := c.flags.Flag(ctx, flag)
+		if err != nil {
+			c.log().Error("Failed to evaluate service flag", "service", name, "flag", flag, "error", err)
+
+			continue
+		}
+
+		states[name] = state
+	}
+
+	return states
+}
+
+// applyFlags re-evaluates the feature flags of a running controller, starting
+// services whose flag now says run and pausing or stopping the others.
+func (c *Controller) applyFlags() {
+	states := c.evaluateFlags(c.ctx)
+	if len(states) == 0 {
+		return
+	}
+
+	ctx, cancel := c.withTimeout(context.Background(), c.shutdownTimeout)
+	defer cancel()
+
+	changes, err := c.services.applyFlags(c.runCtx, ctx, states)
+	if err != nil {
+		c.log().Error("Flagged services failed to change state cleanly", "error", err)
+	}
+
+	c.addToWaitGroup(len(changes.started))
+	c.releaseWaitGroup(len(changes.stopped))
+
+	for _, name := range changes.started {
+		c.log().Info("Started flagged service", "service", name)
+	}
+
+	for _, name := range changes.resumed {
+		c.log().Info("Resumed flagged service", "service", name)
+	}
+
+	for _, name := range changes.paused {
+		c.log().Info("Paused flagged service", "service", name)
+	}
+
+	for _, name := range changes.stopped {
+		c.log().Info("Stopped flagged service", "service", name)
+		c.emit(Event{Type: ServiceStopped, Service: name})
+	}
+}
@@ -0,0 +1,182 @@
+package controls_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mutableFlags struct {
+	mu    sync.Mutex
+	flags controls.StaticFlags
+}
+
+func (m *mutableFlags) set(name string, state controls.FlagState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.flags[name] = state
+}
+
+func (m *mutableFlags) Flag(ctx context.Context, name string) (controls.FlagState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.flags.Flag(ctx, name)
+}
+
+func TestParseFlagState(t *testing.T) {
+	for value, want := range map[string]controls.FlagState{
+		"run":   controls.FlagRun,
+		"PAUSE": controls.FlagPause,
+		"stop":  controls.FlagStop,
+		"true":  controls.FlagRun,
+		"false": controls.FlagStop,
+	} {
+		got, err := controls.ParseFlagState(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, got, value)
+	}
+
+	_, err := controls.ParseFlagState("sometimes")
+	assert.ErrorIs(t, err, controls.ErrInvalidFlagState)
+}
+
+func TestOpenFeatureFlags(t *testing.T) {
+	provider := controls.OpenFeatureFlags(func(_ context.Context, flag string, def string) (string, error) {
+		if flag == "beta" {
+			return "pause", nil
+		}
+
+		return def, nil
+	})
+
+	state, err := provider.Flag(context.Background(), "beta")
+	require.NoError(t, err)
+	assert.Equal(t, controls.FlagPause, state)
+
+	state, err = provider.Flag(context.Background(), "other")
+	require.NoError(t, err)
+	assert.Equal(t, controls.FlagRun, state)
+}
+
+func TestController_FlaggedServices(t *testing.T) {
+	var starts, stops atomic.Int64
+
+	flags := &mutableFlags{flags: controls.StaticFlags{"beta": controls.FlagPause}}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithFlagProvider(flags),
+	)
+	c.Register("beta",
+		controls.WithFlag("beta"),
+		controls.WithStart(func(_ context.Context) error { starts.Add(1); return nil }),
+		controls.WithStop(func(_ context.Context) { stops.Add(1) }),
+	)
+	c.Register("core",
+		controls.WithStart(func(_ context.Context) error { return nil }),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	state := func() controls.State { return c.Report().Services[0].State }
+
+	c.Start()
+	assert.Equal(t, controls.Paused, state())
+	assert.Zero(t, starts.Load())
+
+	flags.set("beta", controls.FlagRun)
+	c.Messages() <- controls.Reload
+	assert.Eventually(t, func() bool { return state() == controls.Running }, time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool { return starts.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	flags.set("beta", controls.FlagStop)
+	c.Messages() <- controls.Reload
+	assert.Eventually(t, func() bool { return state() == controls.Stopped }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int64(1), stops.Load())
+
+	c.Stop()
+	assert.Eventually(t, c.IsStopped, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int64(1), stops.Load())
+	c.Wait()
+}
+
+func TestController_FlaggedServicePausedInPlace(t *testing.T) {
+	var starts, stops, pauses, resumes atomic.Int64
+
+	flags := &mutableFlags{flags: controls.StaticFlags{}}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithFlagProvider(flags),
+	)
+	c.Register("beta",
+		controls.WithFlag("beta"),
+		controls.WithStart(func(_ context.Context) error { starts.Add(1); return nil }),
+		controls.WithStop(func(_ context.Context) { stops.Add(1) }),
+		controls.WithPause(func(_ context.Context) error { pauses.Add(1); return nil }),
+		controls.WithResume(func(_ context.Context) error { resumes.Add(1); return nil }),
+	)
+
+	state := func() controls.State { return c.Report().Services[0].State }
+
+	require.NoError(t, c.Start())
+
+	flags.set("beta", controls.FlagPause)
+	c.Messages() <- controls.Reload
+	assert.Eventually(t, func() bool { return state() == controls.Paused }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int64(1), pauses.Load())
+	assert.Zero(t, stops.Load(), "paused in place, not stopped")
+
+	flags.set("beta", controls.FlagRun)
+	c.Messages() <- controls.Reload
+	assert.Eventually(t, func() bool { return state() == controls.Running }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int64(1), resumes.Load())
+	assert.Equal(t, int64(1), starts.Load(), "resumed, not started again")
+
+	flags.set("beta", controls.FlagPause)
+	c.Messages() <- controls.Reload
+	assert.Eventually(t, func() bool { return pauses.Load() == 2 }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, c.Stop())
+	assert.Equal(t, int64(1), stops.Load(), "a paused service is stopped with the controller")
+	require.NoError(t, c.Wait())
+}
+
+func TestController_FlaggedServicePauseFails(t *testing.T) {
+	var stops atomic.Int64
+
+	flags := &mutableFlags{flags: controls.StaticFlags{}}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithFlagProvider(flags),
+	)
+	c.Register("beta",
+		controls.WithFlag("beta"),
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) { stops.Add(1) }),
+		controls.WithPause(func(_ context.Context) error { return assert.AnError }),
+	)
+
+	require.NoError(t, c.Start())
+
+	flags.set("beta", controls.FlagPause)
+	c.Messages() <- controls.Reload
+	assert.Eventually(t, func() bool { return stops.Load() == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, controls.Paused, c.Report().Services[0].State)
+	assert.ErrorIs(t, c.Report().Services[0].LastError, assert.AnError)
+
+	require.NoError(t, c.Stop())
+	assert.Equal(t, int64(1), stops.Load())
+}
@@ -2,7 +2,10 @@ module github.com/phpboyscout/controls
 
 go 1.25.5
 
-require github.com/stretchr/testify v1.11.1
+require (
+	github.com/stretchr/testify v1.11.1
+	go.uber.org/goleak v1.3.0
+)
 
 require (
 	github.com/brunoga/deep v1.2.4 // indirect
@@ -0,0 +1,243 @@
+package controls
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// stateColors fills graph nodes by the service's state.
+var stateColors = map[State]string{
+	Unknown:   "#eeeeee",
+	Starting:  "#9ecae1",
+	Running:   "#a1d99b",
+	Degraded:  "#fdd49e",
+	Paused:    "#fdd49e",
+	Stopping:  "#bdbdbd",
+	Stopped:   "#bdbdbd",
+	Completed: "#c7e9c0",
+	Failed:    "#fc9272",
+	Disabled:  "#f0f0f0",
+}
+
+// missingColor fills the nodes of dependencies that aren't registered.
+const missingColor = "#ffffff"
+
+type graphNode struct {
+	name      string
+	state     State
+	phase     int
+	dependsOn []string
+	missing   bool
+}
+
+// graph returns the registered services, followed by any dependencies that
+// aren't registered.
+func (q *Services) graph() []graphNode {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	nodes := make([]graphNode, 0, len(q.services))
+	for _, s := range q.services {
+		nodes = append(nodes, graphNode{
+			name:      s.Name,
+			state:     s.state,
+			phase:     s.Phase,
+			dependsOn: slices.Clone(s.DependsOn),
+		})
+	}
+
+	for _, n := range nodes {
+		for _, dep := range n.dependsOn {
+			if !slices.ContainsFunc(nodes, func(m graphNode) bool { return m.name == dep }) {
+				nodes = append(nodes, graphNode{name: dep, missing: true})
+			}
+		}
+	}
+
+	return nodes
+}
+
+// phased reports whether any service is in a phase other than zero, so the
+// graph groups services by phase.
+func phased(nodes []graphNode) bool {
+	return slices.ContainsFunc(nodes, func(n graphNode) bool { return n.phase != 0 })
+}
+
+func (n graphNode) label() string {
+	if n.missing {
+		return n.name + " (not registered)"
+	}
+
+	return n.name + " (" + string(n.state) + ")"
+}
+
+func (n graphNode) color() string {
+	if n.missing {
+		return missingColor
+	}
+
+	return stateColors[n.state]
+}
+
+// GraphDOT renders the services and their dependencies in Graphviz DOT, each
+// service filled by its current state. An edge points from a service to one
+// it depends on, and services are grouped by phase when phases are used.
+func (c *Controller) GraphDOT() string {
+	nodes := c.services.graph()
+
+	var b strings.Builder
+
+	b.WriteString("digraph services {\n")
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [shape=box, style=filled];\n")
+
+	writeNode := func(indent string, n graphNode) {
+		style := ""
+		if n.missing {
+			style = `, style="filled,dashed"`
+		}
+
+		fmt.Fprintf(&b, "%s%q [label=%q, fillcolor=%q%s];\n", indent, n.name, n.label(), n.color(), style)
+	}
+
+	if phased(nodes) {
+		for _, phase := range phases(nodes) {
+			fmt.Fprintf(&b, "\tsubgraph \"cluster_phase_%d\" {\n", phase)
+			fmt.Fprintf(&b, "\t\tlabel=\"phase %d\";\n", phase)
+
+			for _, n := range nodes {
+				if !n.missing && n.phase == phase {
+					writeNode("\t\t", n)
+				}
+			}
+
+			b.WriteString("\t}\n")
+		}
+
+		for _, n := range nodes {
+			if n.missing {
+				writeNode("\t", n)
+			}
+		}
+	} else {
+		for _, n := range nodes {
+			writeNode("\t", n)
+		}
+	}
+
+	for _, n := range nodes {
+		for _, dep := range n.dependsOn {
+			fmt.Fprintf(&b, "\t%q -> %q;\n", n.name, dep)
+		}
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// GraphMermaid renders the same graph as GraphDOT as a Mermaid flowchart.
+func (c *Controller) GraphMermaid() string {
+	nodes := c.services.graph()
+
+	ids := make(map[string]string, len(nodes))
+	for i, n := range nodes {
+		ids[n.name] = fmt.Sprintf("s%d", i)
+	}
+
+	var b strings.Builder
+
+	b.WriteString("flowchart LR\n")
+
+	writeNode := func(indent string, n graphNode) {
+		class := string(n.state)
+		if n.missing {
+			class = "missing"
+		}
+
+		fmt.Fprintf(&b, "%s%s[\"%s\"]:::%s\n", indent, ids[n.name], mermaidEscape(n.label()), class)
+	}
+
+	if phased(nodes) {
+		for _, phase := range phases(nodes) {
+			fmt.Fprintf(&b, "\tsubgraph phase_%d [\"phase %d\"]\n", phase, phase)
+
+			for _, n := range nodes {
+				if !n.missing && n.phase == phase {
+					writeNode("\t\t", n)
+				}
+			}
+
+			b.WriteString("\tend\n")
+		}
+
+		for _, n := range nodes {
+			if n.missing {
+				writeNode("\t", n)
+			}
+		}
+	} else {
+		for _, n := range nodes {
+			writeNode("\t", n)
+		}
+	}
+
+	for _, n := range nodes {
+		for _, dep := range n.dependsOn {
+			fmt.Fprintf(&b, "\t%s --> %s\n", ids[n.name], ids[dep])
+		}
+	}
+
+	var classes []string
+	for _, n := range nodes {
+		if !n.missing && !slices.Contains(classes, string(n.state)) {
+			classes = append(classes, string(n.state))
+		}
+	}
+
+	for _, class := range classes {
+		fmt.Fprintf(&b, "\tclassDef %s fill:%s\n", class, stateColors[State(class)])
+	}
+
+	if slices.ContainsFunc(nodes, func(n graphNode) bool { return n.missing }) {
+		fmt.Fprintf(&b, "\tclassDef missing fill:%s,stroke-dasharray:4\n", missingColor)
+	}
+
+	return b.String()
+}
+
+// phases returns the phases in use, lowest first.
+func phases(nodes []graphNode) []int {
+	var ps []int
+
+	for _, n := range nodes {
+		if !n.missing && !slices.Contains(ps, n.phase) {
+			ps = append(ps, n.phase)
+		}
+	}
+
+	slices.Sort(ps)
+
+	return ps
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
+
+// graphHandler serves the service graph as DOT, or as Mermaid when the format
+// query parameter is "mermaid".
+func (c *Controller) graphHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Query().Get("format") {
+	case "", "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		fmt.Fprint(w, c.GraphDOT())
+	case "mermaid":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, c.GraphMermaid())
+	default:
+		http.Error(w, "unknown graph format", http.StatusBadRequest)
+	}
+}
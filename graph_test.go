@@ -0,0 +1,104 @@
+package controls_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGraphController(t *testing.T, opts ...controls.ServiceOption) *controls.Controller {
+	t.Helper()
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("db", append([]controls.ServiceOption{
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+	}, opts...)...)
+	c.Register("api",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithDependsOn("db", "cache"),
+	)
+
+	return c
+}
+
+func TestController_GraphDOT(t *testing.T) {
+	c := newGraphController(t)
+	require.NoError(t, c.Start())
+	require.NoError(t, c.StopService("db"))
+
+	assert.Equal(t, `digraph services {
+	rankdir=LR;
+	node [shape=box, style=filled];
+	"db" [label="db (stopped)", fillcolor="#bdbdbd"];
+	"api" [label="api (running)", fillcolor="#a1d99b"];
+	"cache" [label="cache (not registered)", fillcolor="#ffffff", style="filled,dashed"];
+	"api" -> "db";
+	"api" -> "cache";
+}
+`, c.GraphDOT())
+
+	require.NoError(t, c.Stop())
+}
+
+func TestController_GraphMermaid(t *testing.T) {
+	c := newGraphController(t, controls.WithPhase(1))
+
+	assert.Equal(t, `flowchart LR
+	subgraph phase_0 ["phase 0"]
+		s1["api (unknown)"]:::unknown
+	end
+	subgraph phase_1 ["phase 1"]
+		s0["db (unknown)"]:::unknown
+	end
+	s2["cache (not registered)"]:::missing
+	s1 --> s0
+	s1 --> s2
+	classDef unknown fill:#eeeeee
+	classDef missing fill:#ffffff,stroke-dasharray:4
+`, c.GraphMermaid())
+}
+
+func TestController_GraphEndpoint(t *testing.T) {
+	c := newGraphController(t)
+
+	srv := httptest.NewServer(c.AdminHandler())
+	defer srv.Close()
+
+	get := func(query string) (int, string) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+"/graph"+query, nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp.StatusCode, string(body)
+	}
+
+	code, body := get("")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, c.GraphDOT(), body)
+
+	code, body = get("?format=mermaid")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, c.GraphMermaid(), body)
+
+	code, _ = get("?format=svg")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
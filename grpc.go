@@ -0,0 +1,110 @@
+package controls
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+// GRPCServer is the subset of *grpc.Server that GRPCService drives, declared
+// here so the package doesn't depend on google.golang.org/grpc.
+type GRPCServer interface {
+	Serve(lis net.Listener) error
+	GracefulStop()
+	Stop()
+}
+
+// GRPCService returns srv, serving on ln, as the named service, for Register
+// or WithServices. Start serves in the background. Stop calls GracefulStop
+// and, if the controller's shutdown timeout expires first, falls back to Stop,
+// closing open connections and reporting the timeout. The health check dials
+// the listener's address. A gRPC server can't serve again once stopped, so
+// restarting it fails with ErrServerNotRestartable. opts are applied after
+// the service's own.
+func GRPCService(name string, srv GRPCServer, ln net.Listener, opts ...ServiceOption) Service {
+	g := &grpcServer{srv: srv, ln: ln}
+
+	return newService(name, append([]ServiceOption{
+		WithStart(g.start),
+		WithStopErr(g.stop),
+		WithHealthCheck(g.probe),
+		WithDetails(g.details),
+	}, opts...)...)
+}
+
+type grpcServer struct {
+	srv GRPCServer
+	ln  net.Listener
+
+	mu      sync.Mutex
+	serving bool
+	served  bool
+}
+
+func (g *grpcServer) start(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.served {
+		return ErrServerNotRestartable
+	}
+
+	g.serving = true
+	g.served = true
+
+	logger := LoggerFrom(ctx)
+	logger.Info("Serving gRPC", "addr", g.ln.Addr().String())
+
+	go func() {
+		if err := g.srv.Serve(g.ln); err != nil {
+			logger.Error("gRPC server stopped", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+func (g *grpcServer) stop(ctx context.Context) error {
+	g.mu.Lock()
+	serving := g.serving
+	g.serving = false
+	g.mu.Unlock()
+
+	if !serving {
+		return nil
+	}
+
+	stopped := make(chan struct{})
+
+	go func() {
+		g.srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		g.srv.Stop()
+
+		return context.Cause(ctx)
+	}
+}
+
+func (g *grpcServer) probe(ctx context.Context) error {
+	g.mu.Lock()
+	serving := g.serving
+	g.mu.Unlock()
+
+	if !serving {
+		return nil
+	}
+
+	addr := g.ln.Addr()
+
+	return probe(ctx, addr.Network(), addr.String())
+}
+
+func (g *grpcServer) details() map[string]string {
+	return map[string]string{"addr": g.ln.Addr().String()}
+}
@@ -0,0 +1,134 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGRPC serves like *grpc.Server, except that GracefulStop waits for hang
+// to be closed when set. Stopping closes ln even if Serve hasn't been called.
+type fakeGRPC struct {
+	hang chan struct{}
+
+	mu       sync.Mutex
+	ln       net.Listener
+	graceful bool
+	forced   bool
+}
+
+func (f *fakeGRPC) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return err
+		}
+
+		_ = conn.Close()
+	}
+}
+
+func (f *fakeGRPC) GracefulStop() {
+	f.mu.Lock()
+	f.graceful = true
+	f.mu.Unlock()
+
+	if f.hang != nil {
+		<-f.hang
+	}
+
+	f.close()
+}
+
+func (f *fakeGRPC) Stop() {
+	f.mu.Lock()
+	f.forced = true
+	f.mu.Unlock()
+
+	f.close()
+}
+
+func (f *fakeGRPC) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_ = f.ln.Close()
+}
+
+func (f *fakeGRPC) stopped() (bool, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.graceful, f.forced
+}
+
+func newGRPCController(t *testing.T, srv *fakeGRPC) *controls.Controller {
+	t.Helper()
+
+	ln, err := (&net.ListenConfig{}).Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv.ln = ln
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithShutdownTimeout(50*time.Millisecond),
+		controls.WithServices(controls.GRPCService("grpc", srv, ln)),
+	)
+	c.Start()
+
+	return c
+}
+
+func TestController_GRPCService(t *testing.T) {
+	t.Run("graceful stop", func(t *testing.T) {
+		srv := &fakeGRPC{}
+		c := newGRPCController(t, srv)
+
+		assert.Equal(t, controls.Running, c.Report().Services[0].State)
+
+		require.NoError(t, c.Stop())
+
+		graceful, forced := srv.stopped()
+		assert.True(t, graceful)
+		assert.False(t, forced)
+	})
+
+	t.Run("forced stop after shutdown timeout", func(t *testing.T) {
+		srv := &fakeGRPC{hang: make(chan struct{})}
+		defer close(srv.hang)
+
+		c := newGRPCController(t, srv)
+
+		err := c.Stop()
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		graceful, forced := srv.stopped()
+		assert.True(t, graceful)
+		assert.True(t, forced)
+	})
+
+	t.Run("restart is rejected", func(t *testing.T) {
+		c := newGRPCController(t, &fakeGRPC{})
+
+		require.NoError(t, c.RestartService("grpc"))
+		assert.Eventually(t, func() bool {
+			return errors.Is(c.Report().Services[0].LastError, controls.ErrServerNotRestartable)
+		}, time.Second, 5*time.Millisecond)
+
+		require.NoError(t, c.Stop())
+	})
+}
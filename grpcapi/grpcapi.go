@@ -0,0 +1,300 @@
+// Package grpcapi serves the controls.v1 gRPC API described by
+// controls.proto, for use with controls.WithRemoteControl. It speaks gRPC
+// over HTTP/2 with the standard library, so it needs no gRPC dependency;
+// clients are generated from controls.proto as usual.
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phpboyscout/controls"
+)
+
+const (
+	// DefaultAddr is the address the server listens on when none is given.
+	DefaultAddr = "127.0.0.1:9090"
+	// ServiceName is the full name of the gRPC service.
+	ServiceName = "controls.v1.Controls"
+	// MaxMessageSize is the largest request message the server accepts.
+	MaxMessageSize = 1 << 20
+
+	readHeaderTimeout = 5 * time.Second
+	shutdownTimeout   = 5 * time.Second
+)
+
+// gRPC status codes.
+const (
+	codeOK                 = 0
+	codeUnknown            = 2
+	codeInvalidArgument    = 3
+	codeNotFound           = 5
+	codeResourceExhausted  = 8
+	codeFailedPrecondition = 9
+	codeUnimplemented      = 12
+	codeInternal           = 13
+)
+
+type Option func(*Server)
+
+// WithTLS serves over TLS with cfg. When cfg verifies client certificates,
+// the common name of a client's certificate is audited as the actor instead
+// of its address.
+func WithTLS(cfg *tls.Config) Option {
+	return func(s *Server) {
+		s.tls = cfg
+	}
+}
+
+// Server serves the controls.v1 API. Requests are audited with the
+// controls.AuditRemote source, and the client's address as the actor.
+type Server struct {
+	addr string
+	tls  *tls.Config
+
+	mu sync.Mutex
+	ln net.Listener
+}
+
+// NewServer returns a Server listening on addr, DefaultAddr when empty.
+func NewServer(addr string, opts ...Option) *Server {
+	if addr == "" {
+		addr = DefaultAddr
+	}
+
+	s := &Server{addr: addr}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Addr returns the address the server is listening on, or an empty string if
+// it isn't serving.
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ln == nil {
+		return ""
+	}
+
+	return s.ln.Addr().String()
+}
+
+// Serve listens on the server's address and serves the API until ctx is
+// done, then waits a few seconds for requests in flight to finish.
+func (s *Server) Serve(ctx context.Context, handle controls.RemoteHandler) error {
+	ln, err := (&net.ListenConfig{}).Listen(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Handler:           s.Handler(handle),
+		ReadHeaderTimeout: readHeaderTimeout,
+		Protocols:         new(http.Protocols),
+	}
+
+	if s.tls != nil {
+		srv.Protocols.SetHTTP2(true)
+		srv.TLSConfig = s.tls
+	} else {
+		srv.Protocols.SetUnencryptedHTTP2(true)
+	}
+
+	s.mu.Lock()
+	s.ln = ln
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.ln = nil
+		s.mu.Unlock()
+	}()
+
+	stopped := make(chan struct{})
+
+	defer context.AfterFunc(ctx, func() {
+		defer close(stopped)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		_ = srv.Shutdown(shutdownCtx)
+	})()
+
+	if s.tls != nil {
+		err = srv.ServeTLS(ln, "", "")
+	} else {
+		err = srv.Serve(ln)
+	}
+
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	<-stopped
+
+	return nil
+}
+
+// Handler returns the http.Handler serving the API with handle, for mounting
+// on an existing HTTP/2 server instead of using Serve.
+func (s *Server) Handler(handle controls.RemoteHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			http.Error(w, "gRPC requests only", http.StatusUnsupportedMediaType)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/grpc+proto")
+
+		body, code, err := s.call(r, handle)
+		if err == nil && body != nil {
+			writeMessage(w, body)
+		}
+
+		w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+
+		if err != nil {
+			w.Header().Set(http.TrailerPrefix+"Grpc-Message", encodeMessage(err.Error()))
+		}
+	})
+}
+
+// call handles one RPC, returning the response message and its status code.
+func (s *Server) call(r *http.Request, handle controls.RemoteHandler) ([]byte, int, error) {
+	method, ok := strings.CutPrefix(r.URL.Path, "/"+ServiceName+"/")
+	if !ok {
+		return nil, codeUnimplemented, fmt.Errorf("unknown service %q", strings.TrimPrefix(r.URL.Path, "/"))
+	}
+
+	msg, code, err := readMessage(r.Body)
+	if err != nil {
+		return nil, code, err
+	}
+
+	req := controls.RemoteRequest{Actor: actor(r)}
+
+	switch method {
+	case "ListServices", "GetStatus":
+		req.Command = controls.ActionStatus
+	case "StopService", "RestartService":
+		req.Command = controls.ActionStopService
+		if method == "RestartService" {
+			req.Command = controls.ActionRestartService
+		}
+
+		if req.Service, err = decodeName(msg); err != nil {
+			return nil, codeInvalidArgument, err
+		}
+
+		if req.Service == "" {
+			return nil, codeInvalidArgument, errors.New("name is required")
+		}
+	case "Shutdown":
+		req.Command = controls.ActionShutdown
+	default:
+		return nil, codeUnimplemented, fmt.Errorf("unknown method %q", method)
+	}
+
+	reply := handle(r.Context(), req)
+	if reply.Err != nil {
+		return nil, codeOf(reply.Err), reply.Err
+	}
+
+	switch {
+	case reply.Error != "":
+		return nil, codeUnknown, errors.New(reply.Error)
+	case method == "ListServices" && reply.Report != nil:
+		return encodeServices(reply.Report.Services), codeOK, nil
+	case method == "GetStatus" && reply.Report != nil:
+		return encodeStatus(reply.Report), codeOK, nil
+	default:
+		return []byte{}, codeOK, nil
+	}
+}
+
+// actor names the client: the common name of its verified certificate, or
+// else its address.
+func actor(r *http.Request) string {
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		return r.TLS.VerifiedChains[0][0].Subject.CommonName
+	}
+
+	return r.RemoteAddr
+}
+
+func codeOf(err error) int {
+	switch {
+	case errors.Is(err, controls.ErrServiceNotFound):
+		return codeNotFound
+	case errors.Is(err, controls.ErrNotRunning), errors.Is(err, controls.ErrServiceDisabled):
+		return codeFailedPrecondition
+	case errors.Is(err, controls.ErrUnknownCommand):
+		return codeUnimplemented
+	default:
+		return codeInternal
+	}
+}
+
+// readMessage reads the single length-prefixed message of a unary request.
+func readMessage(r io.Reader) ([]byte, int, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, codeInvalidArgument, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+
+	if header[0] != 0 {
+		return nil, codeUnimplemented, errors.New("compressed messages are not supported")
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > MaxMessageSize {
+		return nil, codeResourceExhausted, fmt.Errorf("message of %d bytes exceeds %d", size, MaxMessageSize)
+	}
+
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, codeInvalidArgument, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+
+	return msg, codeOK, nil
+}
+
+func writeMessage(w io.Writer, msg []byte) {
+	header := [5]byte{}
+	binary.BigEndian.PutUint32(header[1:], uint32(len(msg)))
+
+	_, _ = w.Write(append(header[:], msg...))
+}
+
+// encodeMessage percent-encodes a status message as the gRPC protocol
+// requires.
+func encodeMessage(s string) string {
+	var b strings.Builder
+
+	for i := range len(s) {
+		if c := s[i]; c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,207 @@
+package grpcapi_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/grpcapi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fields decodes a protocol buffer message into its length-delimited and
+// varint fields, keyed by field number.
+type fields map[uint64][]any
+
+func decode(t *testing.T, b []byte) fields {
+	t.Helper()
+
+	f := fields{}
+
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		require.Positive(t, n)
+		b = b[n:]
+
+		switch tag & 7 {
+		case 0:
+			v, n := binary.Uvarint(b)
+			require.Positive(t, n)
+			f[tag>>3] = append(f[tag>>3], v)
+			b = b[n:]
+		case 2:
+			size, n := binary.Uvarint(b)
+			require.Positive(t, n)
+			f[tag>>3] = append(f[tag>>3], b[n:n+int(size)])
+			b = b[n+int(size):]
+		default:
+			require.FailNow(t, "unexpected wire type", tag&7)
+		}
+	}
+
+	return f
+}
+
+func (f fields) string(field uint64) string {
+	if len(f[field]) == 0 {
+		return ""
+	}
+
+	return string(f[field][0].([]byte))
+}
+
+// call makes a unary RPC and returns the response message and gRPC status.
+func call(t *testing.T, addr, method string, msg []byte) ([]byte, string, string) {
+	t.Helper()
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}, Timeout: 5 * time.Second}
+
+	frame := binary.BigEndian.AppendUint32([]byte{0}, uint32(len(msg)))
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost,
+		"http://"+addr+"/"+grpcapi.ServiceName+"/"+method, bytes.NewReader(append(frame, msg...)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/grpc")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, resp.ProtoMajor)
+
+	if len(body) > 0 {
+		require.GreaterOrEqual(t, len(body), 5)
+		assert.Equal(t, int(binary.BigEndian.Uint32(body[1:5])), len(body)-5)
+		body = body[5:]
+	}
+
+	return body, resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+}
+
+func name(s string) []byte {
+	return append([]byte{0x0a, byte(len(s))}, s...)
+}
+
+func start(t *testing.T) (*controls.Controller, string) {
+	t.Helper()
+
+	server := grpcapi.NewServer("127.0.0.1:0")
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(slog.New(slog.DiscardHandler)),
+		controls.WithoutSignals(),
+		controls.WithName("worker-1"),
+		controls.WithRemoteControl(server),
+		controls.WithService("api",
+			controls.WithStart(func(_ context.Context) error { return nil }),
+			controls.WithLabels(map[string]string{"tier": "web"}),
+		),
+		controls.WithService("jobs", controls.WithStart(func(_ context.Context) error { return nil })),
+	)
+
+	require.NoError(t, c.Start())
+	require.Eventually(t, func() bool { return server.Addr() != "" }, time.Second, 5*time.Millisecond)
+
+	return c, server.Addr()
+}
+
+func TestServer(t *testing.T) {
+	c, addr := start(t)
+
+	body, code, _ := call(t, addr, "ListServices", nil)
+	require.Equal(t, "0", code)
+
+	services := decode(t, body)[1]
+	require.Len(t, services, 2)
+
+	api := decode(t, services[0].([]byte))
+	assert.Equal(t, "api", api.string(1))
+	assert.Equal(t, "running", api.string(2))
+
+	label := decode(t, api[7][0].([]byte))
+	assert.Equal(t, "tier", label.string(1))
+	assert.Equal(t, "web", label.string(2))
+
+	body, code, _ = call(t, addr, "GetStatus", nil)
+	require.Equal(t, "0", code)
+
+	status := decode(t, body)
+	assert.Equal(t, "worker-1", status.string(1))
+	assert.Equal(t, "running", status.string(2))
+	assert.Len(t, status[5], 2)
+
+	body, code, _ = call(t, addr, "StopService", name("jobs"))
+	require.Equal(t, "0", code)
+	assert.Empty(t, body)
+	assert.Equal(t, controls.Stopped, c.Report().Services[1].State)
+
+	_, code, _ = call(t, addr, "RestartService", name("jobs"))
+	require.Equal(t, "0", code)
+	assert.Equal(t, controls.Running, c.Report().Services[1].State)
+
+	_, code, msg := call(t, addr, "StopService", name("missing"))
+	assert.Equal(t, "5", code)
+	assert.Contains(t, msg, "not found")
+
+	_, code, _ = call(t, addr, "StopService", nil)
+	assert.Equal(t, "3", code)
+
+	_, code, _ = call(t, addr, "Dance", nil)
+	assert.Equal(t, "12", code)
+
+	_, code, _ = call(t, addr, "Shutdown", nil)
+	require.Equal(t, "0", code)
+
+	require.NoError(t, c.Wait())
+	require.NoError(t, c.Close())
+
+	var audited []controls.AuditEntry
+
+	for _, e := range c.AuditLog() {
+		if e.Source == controls.AuditRemote {
+			audited = append(audited, e)
+		}
+	}
+
+	require.NotEmpty(t, audited)
+	assert.Contains(t, audited[0].Actor, "127.0.0.1:")
+}
+
+func TestServer_StopsWithContext(t *testing.T) {
+	server := grpcapi.NewServer("127.0.0.1:0")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- server.Serve(ctx,
+			func(_ context.Context, _ controls.RemoteRequest) controls.RemoteReply { return controls.RemoteReply{} })
+	}()
+
+	require.Eventually(t, func() bool { return server.Addr() != "" }, time.Second, 5*time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Serve did not return")
+	}
+
+	assert.Empty(t, server.Addr())
+}
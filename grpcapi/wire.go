@@ -0,0 +1,159 @@
+package grpcapi
+
+import (
+	"encoding/binary"
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/phpboyscout/controls"
+)
+
+// Protocol buffer wire types.
+const (
+	wireVarint = 0
+	wireI64    = 1
+	wireBytes  = 2
+	wireI32    = 5
+)
+
+// ErrMalformed is returned for a request message that isn't valid protocol
+// buffer encoding.
+var ErrMalformed = errors.New("malformed message")
+
+func appendTag(b []byte, field, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wire))
+}
+
+func appendString(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+
+	b = appendTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(s)))
+
+	return append(b, s...)
+}
+
+func appendMessage(b []byte, field int, msg []byte) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(msg)))
+
+	return append(b, msg...)
+}
+
+func appendInt(b []byte, field int, n int64) []byte {
+	if n == 0 {
+		return b
+	}
+
+	return binary.AppendUvarint(appendTag(b, field, wireVarint), uint64(n))
+}
+
+func appendBool(b []byte, field int, v bool) []byte {
+	if !v {
+		return b
+	}
+
+	return append(appendTag(b, field, wireVarint), 1)
+}
+
+// encodeService encodes a Service message.
+func encodeService(r controls.ServiceReport) []byte {
+	var b []byte
+
+	b = appendString(b, 1, r.Name)
+	b = appendString(b, 2, string(r.State))
+	b = appendBool(b, 3, r.Ready)
+	b = appendInt(b, 4, int64(r.Restarts))
+	b = appendInt(b, 5, r.Uptime.Milliseconds())
+
+	if r.LastError != nil {
+		b = appendString(b, 6, r.LastError.Error())
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(r.Labels)) {
+		b = appendMessage(b, 7, appendString(appendString(nil, 1, k), 2, r.Labels[k]))
+	}
+
+	return b
+}
+
+// encodeServices encodes a ListServicesResponse.
+func encodeServices(services []controls.ServiceReport) []byte {
+	var b []byte
+
+	for _, s := range services {
+		b = appendMessage(b, 1, encodeService(s))
+	}
+
+	return b
+}
+
+// encodeStatus encodes a GetStatusResponse.
+func encodeStatus(r *controls.Report) []byte {
+	var b []byte
+
+	b = appendString(b, 1, r.Name)
+	b = appendString(b, 2, string(r.State))
+	b = appendBool(b, 3, r.Maintenance)
+	b = appendInt(b, 4, r.Uptime.Milliseconds())
+
+	for _, s := range r.Services {
+		b = appendMessage(b, 5, encodeService(s))
+	}
+
+	return b
+}
+
+// decodeName returns field 1 of a StopServiceRequest or
+// RestartServiceRequest, skipping any other fields.
+func decodeName(b []byte) (string, error) {
+	var name string
+
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return "", ErrMalformed
+		}
+
+		b = b[n:]
+		field, wire := tag>>3, tag&7
+
+		switch wire {
+		case wireVarint:
+			if _, n = binary.Uvarint(b); n <= 0 {
+				return "", ErrMalformed
+			}
+
+			b = b[n:]
+		case wireI64, wireI32:
+			size := 8
+			if wire == wireI32 {
+				size = 4
+			}
+
+			if len(b) < size {
+				return "", ErrMalformed
+			}
+
+			b = b[size:]
+		case wireBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < size {
+				return "", ErrMalformed
+			}
+
+			if field == 1 {
+				name = string(b[n : n+int(size)])
+			}
+
+			b = b[n+int(size):]
+		default:
+			return "", ErrMalformed
+		}
+	}
+
+	return name, nil
+}
@@ -0,0 +1,285 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// handoffListenersEnv lists the keys of the listeners a process inherited,
+	// in the order of their descriptors from 3.
+	handoffListenersEnv = "CONTROLS_HANDOFF_LISTENERS"
+	// handoffReadyEnv holds the descriptor a new process signals readiness on.
+	handoffReadyEnv  = "CONTROLS_HANDOFF_READY"
+	firstInheritedFD = 3
+)
+
+var (
+	// ErrHandoffFailed is returned when a new process doesn't become ready.
+	ErrHandoffFailed = errors.New("handoff failed")
+	// ErrNotInheritable is returned for a listener whose socket can't be
+	// passed to another process.
+	ErrNotInheritable = errors.New("listener can't be handed off")
+)
+
+// Handoff hands listening sockets to a new copy of the process, so a binary
+// can be replaced without refusing connections. Services listen through it,
+// taking the sockets the process inherited when there are any. Upgrade starts
+// the new process with every socket, and once it reports Ready the old
+// process can drain and stop.
+type Handoff struct {
+	// Path and Args start the new process, defaulting to the running
+	// executable and os.Args. Its output goes to Stdout and Stderr,
+	// defaulting to this process's.
+	Path   string
+	Args   []string
+	Stdout io.Writer
+	Stderr io.Writer
+
+	mu        sync.Mutex
+	keys      []string
+	listeners map[string]net.Listener
+	inherited map[string]*os.File
+	ready     *os.File
+}
+
+// NewHandoff returns a Handoff holding any sockets handed to this process.
+func NewHandoff() *Handoff {
+	h := &Handoff{
+		listeners: map[string]net.Listener{},
+		inherited: map[string]*os.File{},
+	}
+
+	if keys := os.Getenv(handoffListenersEnv); keys != "" {
+		for i, key := range strings.Split(keys, ",") {
+			h.inherited[key] = os.NewFile(uintptr(firstInheritedFD+i), key)
+		}
+	}
+
+	if fd, err := strconv.Atoi(os.Getenv(handoffReadyEnv)); err == nil {
+		h.ready = os.NewFile(uintptr(fd), "handoff-ready")
+	}
+
+	_ = os.Unsetenv(handoffListenersEnv)
+	_ = os.Unsetenv(handoffReadyEnv)
+
+	return h
+}
+
+// Inherited reports whether this process was started by Upgrade.
+func (h *Handoff) Inherited() bool {
+	return h.ready != nil
+}
+
+// Listen returns the listener for network and address, taking it over from
+// the previous process if it was handed one, and otherwise listening afresh.
+// Asking again for the same network and address returns the same listener.
+func (h *Handoff) Listen(network, address string) (net.Listener, error) {
+	key := network + ":" + address
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if l, ok := h.listeners[key]; ok {
+		return l, nil
+	}
+
+	var (
+		l   net.Listener
+		err error
+	)
+
+	if f, ok := h.inherited[key]; ok {
+		delete(h.inherited, key)
+
+		l, err = net.FileListener(f)
+		_ = f.Close()
+	} else {
+		l, err = net.Listen(network, address)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if u, ok := l.(*net.UnixListener); ok {
+		// The socket file must outlive this process's copy of the listener.
+		u.SetUnlinkOnClose(false)
+	}
+
+	h.keys = append(h.keys, key)
+	h.listeners[key] = l
+
+	return l, nil
+}
+
+// adopt adds a listener obtained elsewhere, so Upgrade hands it on under key.
+func (h *Handoff) adopt(key string, l net.Listener) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.listeners[key]; !ok {
+		h.keys = append(h.keys, key)
+	}
+
+	h.listeners[key] = l
+}
+
+// Ready tells the process that started this one that it has taken over, so
+// the old process can stop. It does nothing in a process that wasn't started
+// by Upgrade. Inherited sockets no service asked for are closed.
+func (h *Handoff) Ready() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for key, f := range h.inherited {
+		_ = f.Close()
+
+		delete(h.inherited, key)
+	}
+
+	if h.ready == nil {
+		return nil
+	}
+
+	_, err := h.ready.Write([]byte{1})
+	_ = h.ready.Close()
+	h.ready = nil
+
+	return err
+}
+
+// Upgrade starts a new copy of the process with every listener and waits for
+// it to call Ready, returning the process. If ctx is done or the process
+// exits first, the process is killed and ErrHandoffFailed is returned.
+func (h *Handoff) Upgrade(ctx context.Context) (*os.Process, error) {
+	cmd, err := h.command()
+	if err != nil {
+		return nil, err
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	cmd.ExtraFiles = append(cmd.ExtraFiles, w)
+	cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%d", handoffReadyEnv, firstInheritedFD+len(cmd.ExtraFiles)-1))
+
+	err = cmd.Start()
+
+	for _, f := range cmd.ExtraFiles {
+		_ = f.Close()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	ready := make(chan error, 1)
+
+	go func() {
+		_, err := r.Read(make([]byte, 1))
+		ready <- err
+	}()
+
+	select {
+	case err = <-ready:
+	case <-ctx.Done():
+		err = context.Cause(ctx)
+	}
+
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+
+		return nil, fmt.Errorf("%w: %w", ErrHandoffFailed, err)
+	}
+
+	return cmd.Process, nil
+}
+
+// command builds the new process, passing it duplicates of the listeners.
+func (h *Handoff) command() (*exec.Cmd, error) {
+	path, args := h.Path, h.Args
+	if path == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, err
+		}
+
+		path = exe
+	}
+
+	if args == nil {
+		args = os.Args[1:]
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, h.Stdout, h.Stderr
+
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fail := func(err error) (*exec.Cmd, error) {
+		for _, f := range cmd.ExtraFiles {
+			_ = f.Close()
+		}
+
+		return nil, err
+	}
+
+	for _, key := range h.keys {
+		l, ok := h.listeners[key].(interface{ File() (*os.File, error) })
+		if !ok {
+			return fail(fmt.Errorf("%w: %s", ErrNotInheritable, key))
+		}
+
+		f, err := l.File()
+		if err != nil {
+			return fail(fmt.Errorf("%w: %s: %w", ErrNotInheritable, key, err))
+		}
+
+		cmd.ExtraFiles = append(cmd.ExtraFiles, f)
+	}
+
+	cmd.Env = append(os.Environ(), handoffListenersEnv+"="+strings.Join(h.keys, ","))
+
+	return cmd, nil
+}
+
+// Upgrade hands the controller's listeners to a new copy of the process and,
+// once it is ready, stops the controller so its services drain. It returns
+// the new process. The controller keeps running if the handoff fails. A nil h
+// hands off the listener registry's sockets.
+func (c *Controller) Upgrade(ctx context.Context, h *Handoff) (*os.Process, error) {
+	if h == nil {
+		h = c.listeners.Handoff()
+	}
+
+	p, err := h.Upgrade(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.requestStop(StopUpgraded, programmatic)
+
+	return p, nil
+}
@@ -0,0 +1,96 @@
+package controls_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const handoffChildEnv = "CONTROLS_TEST_HANDOFF_CHILD"
+
+// TestHandoffChild is the new process started by TestController_Upgrade. It
+// takes over the listener, reports ready and serves one connection.
+func TestHandoffChild(t *testing.T) {
+	addr := os.Getenv(handoffChildEnv)
+	if addr == "" {
+		t.Skip("only run as the process started by TestController_Upgrade")
+	}
+
+	h := controls.NewHandoff()
+	require.True(t, h.Inherited())
+
+	l, err := h.Listen("tcp", addr)
+	require.NoError(t, err)
+	require.NoError(t, h.Ready())
+
+	conn, err := l.Accept()
+	require.NoError(t, err)
+
+	_, err = conn.Write([]byte("new"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
+
+func TestController_Upgrade(t *testing.T) {
+	h := controls.NewHandoff()
+	assert.False(t, h.Inherited())
+
+	l, err := h.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	same, err := h.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	assert.Same(t, l, same)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("server",
+		controls.WithStart(func(_ context.Context) error { return nil }),
+		controls.WithStop(func(_ context.Context) { _ = l.Close() }),
+	)
+	c.Start()
+
+	h.Args = []string{"-test.run=^TestHandoffChild$"}
+	h.Stdout, h.Stderr = io.Discard, io.Discard
+	t.Setenv(handoffChildEnv, "127.0.0.1:0")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	p, err := c.Upgrade(ctx, h)
+	require.NoError(t, err)
+
+	assert.Eventually(t, c.IsStopped, time.Second, time.Millisecond)
+	assert.Equal(t, controls.StopUpgraded, c.StopReason())
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err, "the new process accepts on the handed off socket")
+
+	got, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(got))
+
+	state, err := p.Wait()
+	require.NoError(t, err)
+	assert.True(t, state.Success())
+}
+
+func TestHandoff_UpgradeFails(t *testing.T) {
+	h := controls.NewHandoff()
+	h.Path, h.Args = os.Args[0], []string{"-test.run=^$"}
+	h.Stdout, h.Stderr = io.Discard, io.Discard
+
+	_, err := h.Upgrade(context.Background())
+	require.ErrorIs(t, err, controls.ErrHandoffFailed)
+	require.ErrorIs(t, err, io.EOF, "the process exited without becoming ready")
+}
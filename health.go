@@ -0,0 +1,95 @@
+package controls
+
+import "errors"
+
+// DefaultHealthHistory is how many health transitions are kept per service.
+const DefaultHealthHistory = 32
+
+// DefaultHealthBuffer is how many health messages the controller's own health
+// channel holds for a slow reader.
+const DefaultHealthBuffer = 64
+
+// WithHealthHistory sets how many health transitions are kept per service for
+// HealthHistory. A size of zero or less disables the history.
+func WithHealthHistory(size int) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.services.historySize = size
+		})
+	}
+}
+
+// HealthHistory returns the named service's recorded health transitions,
+// oldest first. A message is kept when its status differs from the one
+// before it, so repeated reports of the same status don't push out earlier
+// changes.
+func (c *Controller) HealthHistory(name string) ([]HealthMessage, error) {
+	return c.services.healthHistory(name)
+}
+
+// WithRestartOnUnhealthy restarts the service automatically once n consecutive
+// status checks have failed. A check fails when a WithHealthCheck function
+// returns an error or any context-aware check times out.
+func WithRestartOnUnhealthy(n int) ServiceOption {
+	return func(s *Service) {
+		s.UnhealthyThreshold = n
+	}
+}
+
+// recordHealth stores h as the latest health message, adding it to the history
+// when its status differs from the last. It must be called with Services.mu
+// held.
+func (s *service) recordHealth(historySize int, h HealthMessage) {
+	if s.lastHealth == nil || s.lastHealth.Status != h.Status {
+		s.history.add(historySize, h)
+	}
+
+	s.lastHealth = &h
+}
+
+// checkStatus runs the status checks, reporting failures as ServiceUnhealthy
+// events and restarting services that have failed too many in a row. Each
+// context-aware check's outcome is published on the health channel. It
+// returns the failures joined.
+func (c *Controller) checkStatus() error {
+	var errs []error
+
+	failures, health := c.services.status(c.withTimeout)
+
+	for _, h := range health {
+		c.publishHealth(h)
+	}
+
+	for _, f := range failures {
+		errs = append(errs, f.err)
+
+		c.log().Error(f.err.Error())
+		c.emit(Event{Type: ServiceUnhealthy, Service: f.name, Err: f.err, Message: f.err.Error()})
+
+		if !f.restart {
+			continue
+		}
+
+		c.log().Warn("Restarting unhealthy service", "service", f.name)
+
+		if err := c.RestartService(f.name); err != nil {
+			c.log().Error("Failed to restart unhealthy service", "service", f.name, "error", err)
+		}
+	}
+
+	c.assess()
+
+	return errors.Join(errs...)
+}
+
+func (q *Services) healthHistory(name string) ([]HealthMessage, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	s, err := q.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.history.all(), nil
+}
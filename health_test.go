@@ -0,0 +1,156 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_HealthHistory(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithHealthHistory(3),
+	)
+	c.Register("db", controls.WithStart(noopStart), controls.WithStop(func(_ context.Context) {}))
+
+	history, err := c.HealthHistory("db")
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	for _, status := range []controls.HealthStatus{
+		controls.Healthy, controls.Healthy, controls.HealthDegraded, controls.Unhealthy, controls.Unhealthy, controls.Healthy,
+	} {
+		c.RecordHealth("db", controls.HealthMessage{Status: status})
+	}
+
+	history, err = c.HealthHistory("db")
+	require.NoError(t, err)
+
+	var statuses []controls.HealthStatus
+	for _, h := range history {
+		statuses = append(statuses, h.Status)
+		assert.Equal(t, "db", h.ServiceName)
+	}
+
+	assert.Equal(t, []controls.HealthStatus{controls.HealthDegraded, controls.Unhealthy, controls.Healthy}, statuses)
+
+	_, err = c.HealthHistory("missing")
+	require.ErrorIs(t, err, controls.ErrServiceNotFound)
+}
+
+func TestController_RestartOnUnhealthy(t *testing.T) {
+	var (
+		starts  atomic.Int64
+		healthy atomic.Bool
+	)
+
+	rec := &eventRecorder{}
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithEventHandler(rec.handle),
+	)
+	c.Register("flaky",
+		controls.WithStart(func(_ context.Context) error {
+			starts.Add(1)
+
+			return nil
+		}),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithHealthCheck(func(_ context.Context) error {
+			if healthy.Load() {
+				return nil
+			}
+
+			return errors.New("not ok") //nolint:err113
+		}),
+		controls.WithRestartOnUnhealthy(2),
+	)
+
+	c.Start()
+	require.Equal(t, int64(1), starts.Load())
+
+	c.Messages() <- controls.Status
+
+	assert.Eventually(t, func() bool {
+		return len(rec.ofType(controls.ServiceUnhealthy)) == 1
+	}, time.Second, time.Millisecond)
+	assert.Empty(t, rec.ofType(controls.ServiceRestarted))
+
+	c.Messages() <- controls.Status
+
+	assert.Eventually(t, func() bool {
+		return len(rec.ofType(controls.ServiceRestarted)) == 1
+	}, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool { return starts.Load() == 2 }, time.Second, time.Millisecond)
+
+	unhealthy := rec.ofType(controls.ServiceUnhealthy)
+	require.Len(t, unhealthy, 2)
+	assert.Equal(t, "flaky", unhealthy[1].Service)
+	assert.EqualError(t, unhealthy[1].Err, "flaky: not ok")
+
+	healthy.Store(true)
+	c.Messages() <- controls.Status
+
+	assert.Eventually(t, func() bool {
+		history, err := c.HealthHistory("flaky")
+
+		return err == nil && len(history) == 2 && history[1].Status == controls.Healthy
+	}, time.Second, time.Millisecond)
+
+	r := c.Report()
+	assert.Equal(t, 2, r.Services[0].HealthFailures)
+	assert.Equal(t, 1, r.Services[0].Restarts)
+
+	require.NoError(t, c.Stop())
+}
+
+func TestController_StatusPublishesHealth(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("db",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithHealthCheck(func(_ context.Context) error {
+			return errors.New("replica lag") //nolint:err113
+		}),
+	)
+	c.Register("cache",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithStatusContext(func(_ context.Context) {}),
+	)
+	c.Register("quiet", controls.WithStart(noopStart), controls.WithStop(func(_ context.Context) {}))
+
+	c.Start()
+	defer c.Stop()
+
+	_, err := c.Status(context.Background())
+	require.Error(t, err)
+
+	got := map[string]controls.HealthMessage{}
+
+	for range 2 {
+		h := <-c.Health()
+		got[h.ServiceName] = h
+	}
+
+	assert.Equal(t, controls.Unhealthy, got["db"].Status)
+	assert.Equal(t, "db: replica lag", got["db"].Message)
+	assert.Equal(t, controls.Healthy, got["cache"].Status)
+	assert.Empty(t, c.Health(), "services without a context-aware check publish nothing")
+
+	history, err := c.HealthHistory("cache")
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
@@ -0,0 +1,183 @@
+package controls
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Health summarises the report as a HealthStatus: healthy while the
+// controller is running, degraded or unhealthy when it is Degraded or Failed,
+// and unknown while it is starting or stopped.
+func (r Report) Health() HealthStatus {
+	switch r.State {
+	case Running:
+		return Healthy
+	case Degraded:
+		return HealthDegraded
+	case Failed:
+		return Unhealthy
+	default:
+		return HealthUnknown
+	}
+}
+
+// Health summarises the service as a HealthStatus: unhealthy once it has
+// failed, degraded while it is stalled, and otherwise its last recorded
+// health, or healthy if it is running with none.
+func (r ServiceReport) Health() HealthStatus {
+	switch {
+	case r.State == Failed:
+		return Unhealthy
+	case r.Stalled:
+		return HealthDegraded
+	case r.LastHealth != nil:
+		return r.LastHealth.Status
+	case r.State == Running:
+		return Healthy
+	default:
+		return HealthUnknown
+	}
+}
+
+// WriteJSON writes the report as JSON.
+func (r Report) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// WriteText writes the report as plain text, a line for the controller and
+// one for each service.
+func (r Report) WriteText(w io.Writer) error {
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "%s (%s)\n", r.Health(), r.State)
+
+	for _, s := range r.Services {
+		fmt.Fprintf(b, "%s: %s (%s)", s.Name, s.Health(), s.State)
+
+		switch {
+		case s.LastError != nil:
+			fmt.Fprintf(b, ": %v", s.LastError)
+		case s.LastHealth != nil && s.LastHealth.Message != "":
+			fmt.Fprintf(b, ": %s", s.LastHealth.Message)
+		}
+
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// PrometheusContentType is the content type of WritePrometheus's output.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WritePrometheus writes the report in the Prometheus text exposition format.
+func (r Report) WritePrometheus(w io.Writer) error {
+	b := &strings.Builder{}
+
+	metric := func(name, help, kind string) {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	health := func(labels string, status HealthStatus) {
+		for _, h := range []HealthStatus{Healthy, HealthDegraded, Unhealthy, HealthUnknown} {
+			fmt.Fprintf(b, "controls_health{%sstatus=%q} %d\n", labels, h, boolValue(h == status))
+		}
+	}
+
+	metric("controls_health", "Health of the controller and each service; 1 for the current status.", "gauge")
+	health("", r.Health())
+
+	for _, s := range r.Services {
+		health(fmt.Sprintf("service=%q,", s.Name), s.Health())
+	}
+
+	metric("controls_uptime_seconds", "Seconds since the controller or service started.", "gauge")
+	fmt.Fprintf(b, "controls_uptime_seconds %s\n", formatFloat(r.Uptime.Seconds()))
+
+	for _, s := range r.Services {
+		fmt.Fprintf(b, "controls_uptime_seconds{service=%q} %s\n", s.Name, formatFloat(s.Uptime.Seconds()))
+	}
+
+	metric("controls_service_restarts_total", "Times each service has been restarted.", "counter")
+
+	for _, s := range r.Services {
+		fmt.Fprintf(b, "controls_service_restarts_total{service=%q} %d\n", s.Name, s.Restarts)
+	}
+
+	metric("controls_service_health_failures_total", "Failed status checks for each service.", "counter")
+
+	for _, s := range r.Services {
+		fmt.Fprintf(b, "controls_service_health_failures_total{service=%q} %d\n", s.Name, s.HealthFailures)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func boolValue(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// HealthHandler serves the controller's health, answering with the HTTP
+// status of Report.Health. The format follows the format query parameter,
+// "json", "text" or "prometheus", or else the Accept header: Prometheus for
+// its exposition format, plain text for text/plain and JSON otherwise.
+func (c *Controller) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Report()
+
+		write, contentType := report.WriteJSON, "application/json"
+
+		switch healthFormat(r) {
+		case "text":
+			write, contentType = report.WriteText, "text/plain; charset=utf-8"
+		case "prometheus":
+			write, contentType = report.WritePrometheus, PrometheusContentType
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(report.Health().HTTPStatus())
+
+		_ = write(w)
+	})
+}
+
+func healthFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+
+	for accept := range strings.SplitSeq(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case mediaType == "text/plain" && params["version"] == "0.0.4":
+			return "prometheus"
+		case mediaType == "text/plain":
+			return "text"
+		case mediaType == "application/json":
+			return "json"
+		}
+	}
+
+	return "json"
+}
@@ -0,0 +1,74 @@
+package controls_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_HealthHandler(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("api", controls.WithStart(noopStart), controls.WithStop(func(_ context.Context) {}))
+	c.Register("db",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithHealthCheck(func(_ context.Context) error {
+			return errors.New("replica lag") //nolint:err113
+		}),
+	)
+
+	h := c.AdminHandler()
+
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := get("/health", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "not started")
+
+	c.Start()
+	defer c.Stop()
+
+	rec = get("/health", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var report map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, "running", report["state"])
+
+	_, err := c.Status(context.Background())
+	require.Error(t, err)
+
+	rec = get("/health", "text/plain")
+	require.Equal(t, http.StatusOK, rec.Code, "a degraded controller is still serving")
+	assert.Equal(t, "degraded (degraded)\napi: healthy (running)\ndb: unhealthy (running): db: replica lag\n", rec.Body.String())
+
+	rec = get("/health", "text/plain;version=0.0.4, */*;q=0.1")
+	assert.Equal(t, controls.PrometheusContentType, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `controls_health{status="degraded"} 1`)
+	assert.Contains(t, rec.Body.String(), `controls_health{service="db",status="unhealthy"} 1`)
+	assert.Contains(t, rec.Body.String(), `controls_service_health_failures_total{service="db"} 1`)
+
+	rec = get("/health?format=text", "application/json")
+	assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+}
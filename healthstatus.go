@@ -0,0 +1,60 @@
+package controls
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrInvalidHealthStatus is returned when unmarshalling an unknown health
+// status name.
+var ErrInvalidHealthStatus = errors.New("invalid health status")
+
+var healthStatusNames = map[HealthStatus]string{
+	HealthUnknown:  "unknown",
+	Healthy:        "healthy",
+	HealthDegraded: "degraded",
+	Unhealthy:      "unhealthy",
+}
+
+func (h HealthStatus) String() string {
+	if name, ok := healthStatusNames[h]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("HealthStatus(%d)", int(h))
+}
+
+// MarshalText renders the status as its name.
+func (h HealthStatus) MarshalText() ([]byte, error) {
+	if _, ok := healthStatusNames[h]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidHealthStatus, int(h))
+	}
+
+	return []byte(h.String()), nil
+}
+
+// UnmarshalText parses a status name.
+func (h *HealthStatus) UnmarshalText(text []byte) error {
+	for status, name := range healthStatusNames {
+		if name == string(text) {
+			*h = status
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", ErrInvalidHealthStatus, text)
+}
+
+// HTTPStatus maps the status to the HTTP status code a health endpoint should
+// answer with: 200 for healthy and degraded services, which are still
+// serving, and 503 for unhealthy and unknown ones.
+func (h HealthStatus) HTTPStatus() int {
+	switch h {
+	case Healthy, HealthDegraded:
+		return http.StatusOK
+	default:
+		return http.StatusServiceUnavailable
+	}
+}
@@ -0,0 +1,48 @@
+package controls_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthStatus(t *testing.T) {
+	tests := []struct {
+		status controls.HealthStatus
+		name   string
+		code   int
+	}{
+		{controls.HealthUnknown, "unknown", http.StatusServiceUnavailable},
+		{controls.Healthy, "healthy", http.StatusOK},
+		{controls.HealthDegraded, "degraded", http.StatusOK},
+		{controls.Unhealthy, "unhealthy", http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.name, tt.status.String())
+			assert.Equal(t, tt.code, tt.status.HTTPStatus())
+
+			b, err := json.Marshal(controls.HealthMessage{Status: tt.status})
+			require.NoError(t, err)
+			assert.Contains(t, string(b), `"status":"`+tt.name+`"`)
+
+			var h controls.HealthMessage
+			require.NoError(t, json.Unmarshal(b, &h))
+			assert.Equal(t, tt.status, h.Status)
+		})
+	}
+
+	assert.Equal(t, "HealthStatus(9)", controls.HealthStatus(9).String())
+
+	_, err := json.Marshal(controls.HealthStatus(9))
+	require.ErrorIs(t, err, controls.ErrInvalidHealthStatus)
+
+	var h controls.HealthStatus
+	require.ErrorIs(t, json.Unmarshal([]byte(`"sick"`), &h), controls.ErrInvalidHealthStatus)
+}
@@ -0,0 +1,65 @@
+package controls
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultStateHistory is how many state transitions StateHistory keeps.
+const DefaultStateHistory = 128
+
+// Transition is a change of state of the controller, or of the named service.
+type Transition struct {
+	Service string    `json:"service,omitempty"`
+	From    State     `json:"from"`
+	To      State     `json:"to"`
+	Time    time.Time `json:"time"`
+}
+
+// WithStateHistory sets how many state transitions are kept for StateHistory.
+// A size of zero or less disables the history.
+func WithStateHistory(size int) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.history.size = size
+		})
+	}
+}
+
+// StateHistory returns the most recent state transitions of the controller
+// and its services, oldest first. Controller transitions have no Service.
+func (c *Controller) StateHistory() []Transition {
+	c.history.mu.Lock()
+	defer c.history.mu.Unlock()
+
+	return c.history.transitions.all()
+}
+
+type stateHistory struct {
+	mu          sync.Mutex
+	size        int
+	now         func() time.Time
+	transitions ring[Transition]
+}
+
+func (h *stateHistory) record(service string, from, to State) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.transitions.add(h.size, Transition{Service: service, From: from, To: to, Time: h.now()})
+}
+
+// setState moves s to state, recording the transition. It must be called with
+// q.mu held.
+func (q *Services) setState(s *service, state State) {
+	if s.state == state {
+		return
+	}
+
+	if q.transitioned != nil {
+		q.transitioned(s.Name, s.state, state)
+	}
+
+	s.state = state
+	q.unfinished.track(s)
+}
@@ -0,0 +1,63 @@
+package controls_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/controlstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_StateHistory(t *testing.T) {
+	clock := controlstest.NewFakeClock(epoch)
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithClock(clock),
+	)
+	c.Register("db", controls.WithStart(noopStart), controls.WithStop(func(_ context.Context) {}))
+
+	c.Start()
+	require.NoError(t, c.Stop())
+
+	history := c.StateHistory()
+
+	var got []string
+	for _, tr := range history {
+		got = append(got, tr.Service+":"+string(tr.From)+">"+string(tr.To))
+		assert.Equal(t, clock.Now(), tr.Time)
+	}
+
+	assert.Equal(t, []string{
+		":unknown>starting",
+		"db:unknown>running",
+		":starting>running",
+		":running>stopping",
+		"db:running>stopping",
+		"db:stopping>stopped",
+		":stopping>stopped",
+	}, got)
+}
+
+func TestController_WithStateHistory(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithStateHistory(2),
+	)
+	c.Register("svc",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	c.Start()
+	require.NoError(t, c.Stop())
+
+	history := c.StateHistory()
+	require.Len(t, history, 2)
+	assert.Equal(t, controls.Transition{Service: "svc", From: controls.Stopping, To: controls.Stopped, Time: history[0].Time}, history[0])
+	assert.Equal(t, controls.Transition{From: controls.Stopping, To: controls.Stopped, Time: history[1].Time}, history[1])
+}
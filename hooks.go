@@ -0,0 +1,85 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// HookFunc is a controller-level lifecycle hook, for work that concerns the
+// whole process rather than one service, such as warming caches or notifying
+// a deployment system.
+type HookFunc func(context.Context) error
+
+// hooks holds the controller's lifecycle hooks in the order they were added.
+type hooks struct {
+	mu          sync.Mutex
+	beforeStart []HookFunc
+	afterStart  []HookFunc
+	beforeStop  []HookFunc
+	afterStop   []HookFunc
+}
+
+// OnBeforeStart adds a hook run by Start before any service starts. If a hook
+// fails, the hooks after it are skipped and Start returns its error, leaving
+// the controller unstarted.
+func (c *Controller) OnBeforeStart(fn HookFunc) {
+	c.addHook(&c.hooks.beforeStart, fn)
+}
+
+// OnAfterStart adds a hook run once Start has brought the services up. Its
+// errors are logged.
+func (c *Controller) OnAfterStart(fn HookFunc) {
+	c.addHook(&c.hooks.afterStart, fn)
+}
+
+// OnBeforeStop adds a hook run when the shutdown begins, before any service is
+// stopped. Its errors are returned from Stop.
+func (c *Controller) OnBeforeStop(fn HookFunc) {
+	c.addHook(&c.hooks.beforeStop, fn)
+}
+
+// OnAfterStop adds a hook run once every service has stopped. Its errors are
+// returned from Stop.
+func (c *Controller) OnAfterStop(fn HookFunc) {
+	c.addHook(&c.hooks.afterStop, fn)
+}
+
+func (c *Controller) addHook(list *[]HookFunc, fn HookFunc) {
+	c.hooks.mu.Lock()
+	defer c.hooks.mu.Unlock()
+
+	*list = append(*list, fn)
+}
+
+// afterStop runs the OnAfterStop hooks and then the cleanups, under a fresh
+// shutdown timeout as stopping the services may have used up the first.
+func (c *Controller) afterStop() error {
+	ctx, cancel := c.withTimeout(context.Background(), c.shutdownTimeout)
+	defer cancel()
+
+	return errors.Join(c.runHooks(ctx, &c.hooks.afterStop, false), c.cleanUp(ctx))
+}
+
+// runHooks calls each hook in list in the order they were added. It stops at
+// the first error when failFast is set, and otherwise joins their errors.
+func (c *Controller) runHooks(ctx context.Context, list *[]HookFunc, failFast bool) error {
+	c.hooks.mu.Lock()
+	fns := slices.Clone(*list)
+	c.hooks.mu.Unlock()
+
+	var errs []error
+
+	for _, fn := range fns {
+		if err := fn(ctx); err != nil {
+			if failFast {
+				return err
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,87 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_LifecycleHooks(t *testing.T) {
+	errFlush := errors.New("flush failed") //nolint:err113
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+
+	add := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls = append(calls, name)
+	}
+
+	record := func(name string, err error) controls.HookFunc {
+		return func(_ context.Context) error {
+			add(name)
+
+			return err
+		}
+	}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("api",
+		controls.WithStart(func(_ context.Context) error { add("api start"); return nil }),
+		controls.WithStop(func(_ context.Context) { add("api stop") }),
+	)
+
+	c.OnBeforeStart(record("before start 1", nil))
+	c.OnBeforeStart(record("before start 2", nil))
+	c.OnAfterStart(record("after start", nil))
+	c.OnBeforeStop(record("before stop", nil))
+	c.OnAfterStop(record("after stop 1", errFlush))
+	c.OnAfterStop(record("after stop 2", nil))
+
+	require.NoError(t, c.Start())
+	require.ErrorIs(t, c.Stop(), errFlush)
+
+	assert.Equal(t, []string{
+		"before start 1", "before start 2", "api start", "after start",
+		"before stop", "api stop", "after stop 1", "after stop 2",
+	}, calls)
+}
+
+func TestController_BeforeStartHookFails(t *testing.T) {
+	errWarm := errors.New("cache warm-up failed") //nolint:err113
+
+	started := false
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("api",
+		controls.WithStart(func(_ context.Context) error { started = true; return nil }),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	c.OnBeforeStart(func(_ context.Context) error { return errWarm })
+	c.OnBeforeStart(func(_ context.Context) error {
+		t.Error("hooks after a failed one must not run")
+
+		return nil
+	})
+
+	require.ErrorIs(t, c.Start(), errWarm)
+	assert.False(t, started)
+	assert.Equal(t, controls.Unknown, c.GetState())
+}
@@ -0,0 +1,162 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// ErrServerNotRestartable is returned when a service built around a
+// caller-supplied server is started again after being shut down; neither an
+// http.Server nor a gRPC server can serve again once stopped.
+var ErrServerNotRestartable = errors.New("server cannot be restarted after shutdown")
+
+// HTTPService returns srv as the named service, for Register or WithServices.
+// Start binds srv.Addr (":http" when empty) so a port conflict fails the
+// start, then marks the service ready and serves, over TLS when srv.TLSConfig
+// is set. If serving fails the service fails with the error. Stop shuts the
+// server down gracefully within the controller's shutdown timeout, closing it
+// outright if that expires. The health check dials the bound port. The bound
+// address is reported in the service's Details. opts are applied after these,
+// such as to add WithDependsOn or WithLabels.
+func HTTPService(name string, srv *http.Server, opts ...ServiceOption) Service {
+	return newService(name, append(newHTTPServer(func() *http.Server { return srv }, false).serviceOptions(), opts...)...)
+}
+
+// httpServer runs the servers returned by newServer. When fresh is false
+// newServer always returns the same server, which is only started once.
+type httpServer struct {
+	newServer func() *http.Server
+	fresh     bool
+
+	mu     sync.Mutex
+	srv    *http.Server
+	bound  string
+	served bool
+}
+
+func newHTTPServer(newServer func() *http.Server, fresh bool) *httpServer {
+	return &httpServer{newServer: newServer, fresh: fresh}
+}
+
+func (h *httpServer) serviceOptions() []ServiceOption {
+	return []ServiceOption{
+		WithStart(h.start),
+		WithStopErr(h.stop),
+		WithHealthCheck(h.probe),
+		WithDetails(h.details),
+	}
+}
+
+// start binds the server's address, marks the service ready and serves until
+// the server is shut down, returning the error if serving fails instead.
+func (h *httpServer) start(ctx context.Context) error {
+	srv, ln, err := h.listen(ctx)
+	if err != nil {
+		return err
+	}
+
+	LoggerFrom(ctx).Info("Serving HTTP", "addr", ln.Addr().String())
+	Ready(ctx)
+
+	if srv.TLSConfig != nil {
+		err = srv.ServeTLS(ln, "", "")
+	} else {
+		err = srv.Serve(ln)
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	h.mu.Lock()
+	if h.srv == srv {
+		h.srv = nil
+		h.bound = ""
+	}
+	h.mu.Unlock()
+
+	return err
+}
+
+func (h *httpServer) listen(ctx context.Context) (*http.Server, net.Listener, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.served && !h.fresh {
+		return nil, nil, ErrServerNotRestartable
+	}
+
+	srv := h.newServer()
+
+	addr := srv.Addr
+	if addr == "" {
+		addr = ":http"
+
+		if srv.TLSConfig != nil {
+			addr = ":https"
+		}
+	}
+
+	ln, err := (&net.ListenConfig{}).Listen(ctx, "tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h.srv = srv
+	h.bound = ln.Addr().String()
+	h.served = true
+
+	return srv, ln, nil
+}
+
+func (h *httpServer) stop(ctx context.Context) error {
+	h.mu.Lock()
+	srv := h.srv
+	h.srv = nil
+	h.bound = ""
+	h.mu.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+
+	if err := srv.Shutdown(ctx); err != nil {
+		return errors.Join(err, srv.Close())
+	}
+
+	return nil
+}
+
+func (h *httpServer) probe(ctx context.Context) error {
+	h.mu.Lock()
+	addr := h.bound
+	h.mu.Unlock()
+
+	return probe(ctx, "tcp", addr)
+}
+
+func (h *httpServer) details() map[string]string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return map[string]string{"addr": h.bound}
+}
+
+// probe dials addr and returns the error if nothing answers. An empty addr
+// means the server isn't running and is skipped.
+func probe(ctx context.Context, network, addr string) error {
+	if addr == "" {
+		return nil
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+	if err != nil {
+		return fmt.Errorf("not accepting connections on %s: %w", addr, err)
+	}
+
+	return conn.Close()
+}
@@ -0,0 +1,118 @@
+package controls_test
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, url string) (string, error) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+
+	return string(body), err
+}
+
+func TestController_HTTPService(t *testing.T) {
+	srv := &http.Server{
+		Addr: "127.0.0.1:0",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = io.WriteString(w, "hello")
+		}),
+		ReadHeaderTimeout: time.Second,
+	}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithServices(controls.HTTPService("web", srv, controls.WithLabels(map[string]string{"tier": "edge"}))),
+	)
+
+	c.Start()
+
+	addr := c.Report().Services[0].Details["addr"]
+	require.NotEmpty(t, addr)
+	assert.Equal(t, "edge", c.Report().Services[0].Labels["tier"])
+
+	body, err := get(t, "http://"+addr)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", body)
+
+	c.Messages() <- controls.Status
+
+	require.NoError(t, c.StopService("web"))
+
+	_, err = get(t, "http://"+addr)
+	require.Error(t, err)
+
+	require.NoError(t, c.RestartService("web"))
+	assert.Eventually(t, func() bool {
+		return c.Report().Services[0].State == controls.Failed
+	}, time.Second, 5*time.Millisecond)
+	assert.ErrorIs(t, c.Report().Services[0].LastError, controls.ErrServerNotRestartable)
+
+	require.NoError(t, c.Stop())
+}
+
+func TestController_HTTPServiceAddressInUse(t *testing.T) {
+	first := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithPprof("127.0.0.1:0"),
+	)
+	first.Start()
+
+	addr := first.Report().Services[0].Details["addr"]
+
+	second := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithServices(controls.HTTPService("web", &http.Server{Addr: addr, ReadHeaderTimeout: time.Second})),
+	)
+	second.Start()
+
+	assert.Eventually(t, func() bool {
+		return second.Report().Services[0].State == controls.Failed
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, second.Stop())
+	require.NoError(t, first.Stop())
+}
+
+func TestController_HTTPServiceServeFailure(t *testing.T) {
+	// Without a certificate the address binds but serving fails at once.
+	srv := &http.Server{Addr: "127.0.0.1:0", TLSConfig: &tls.Config{MinVersion: tls.VersionTLS13}, ReadHeaderTimeout: time.Second}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithServices(controls.HTTPService("web", srv)),
+	)
+	c.Start()
+
+	assert.Eventually(t, func() bool {
+		return c.Report().Services[0].State == controls.Failed
+	}, time.Second, 5*time.Millisecond)
+	require.Error(t, c.Report().Services[0].LastError)
+	assert.Empty(t, c.Report().Services[0].Details["addr"])
+
+	require.NoError(t, c.Stop())
+}
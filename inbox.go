@@ -0,0 +1,166 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrReservedMessage is returned by Broadcast for messages the controller
+// handles itself.
+var ErrReservedMessage = errors.New("reserved message")
+
+// reserved are the messages the control loop acts on itself.
+var reserved = []Message{Stop, Status, Reload, Drain}
+
+// DefaultInboxSize is how many messages a service's channel buffers before
+// further messages are dropped.
+const DefaultInboxSize = 8
+
+// DefaultServiceEvents is how many of its service's events a ServiceHandle
+// keeps.
+const DefaultServiceEvents = 32
+
+// ServiceHandle gives access to a single registered service, so it can be
+// managed directly rather than through the controller's messages.
+type ServiceHandle struct {
+	name  string
+	inbox chan Message
+	// controller is the controller the service is registered with. It
+	// changes when the service is adopted.
+	controller atomic.Pointer[Controller]
+	mu         sync.Mutex
+	events     ring[Event]
+}
+
+func (h *ServiceHandle) Name() string {
+	return h.name
+}
+
+// State returns the service's current state.
+func (h *ServiceHandle) State() State {
+	return inspect(h, func(s *service) State { return s.state })
+}
+
+// LastError returns the error the service last failed or stopped with.
+func (h *ServiceHandle) LastError() error {
+	return inspect(h, func(s *service) error { return s.lastErr })
+}
+
+// Events returns the service's most recent events, oldest first. The handle
+// keeps DefaultServiceEvents of them.
+func (h *ServiceHandle) Events() []Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.events.all()
+}
+
+// Stop stops the service without stopping the controller, as StopService
+// does. ctx bounds the stop along with the shutdown timeout.
+func (h *ServiceHandle) Stop(ctx context.Context) error {
+	c := h.controller.Load()
+
+	return c.audited(programmatic, ActionStopService, h.name, "", func() error {
+		return c.stopService(ctx, h.name)
+	})
+}
+
+// Restart restarts the service, as RestartService does. ctx bounds stopping
+// it along with the shutdown timeout.
+func (h *ServiceHandle) Restart(ctx context.Context) error {
+	c := h.controller.Load()
+
+	return c.audited(programmatic, ActionRestartService, h.name, "", func() error {
+		return c.restartService(ctx, h.name)
+	})
+}
+
+// inspect returns what fn reads from the service, or fn's zero value once the
+// service is no longer registered.
+func inspect[T any](h *ServiceHandle, fn func(*service) T) T {
+	q := &h.controller.Load().services
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	s, err := q.lookup(h.name)
+	if err != nil {
+		var zero T
+
+		return zero
+	}
+
+	return fn(s)
+}
+
+// record keeps event if it concerns the handle's service.
+func (h *ServiceHandle) record(event Event) {
+	if event.Service != h.name {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.events.add(DefaultServiceEvents, event)
+}
+
+// Messages returns the service's own control channel. While the service is
+// running it receives Stop before its StopFunc is called, and every message
+// the controller passes to message handlers, such as Reload. Messages that
+// arrive while the channel is full are dropped and logged.
+func (h *ServiceHandle) Messages() <-chan Message {
+	return h.inbox
+}
+
+// Service returns the handle of the named service.
+func (c *Controller) Service(name string) (*ServiceHandle, error) {
+	c.services.mu.Lock()
+	defer c.services.mu.Unlock()
+
+	s, err := c.services.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.handle, nil
+}
+
+type inboxKey struct{}
+
+// MessagesFrom returns the control channel of the service whose Start, Stop
+// or status function was given ctx, so its loop can select on it. Outside a
+// service call it returns nil, which never delivers.
+func MessagesFrom(ctx context.Context) <-chan Message {
+	inbox, _ := ctx.Value(inboxKey{}).(chan Message)
+
+	return inbox
+}
+
+// deliver queues msg on the service's channel without blocking. It must be
+// called with q.mu held.
+func (q *Services) deliver(s *service, msg Message) {
+	select {
+	case s.handle.inbox <- msg:
+	default:
+		q.log().Warn("Service message dropped", "service", s.Name, "message", msg)
+	}
+}
+
+// Broadcast passes an application-defined message, such as "flush-caches", to
+// every service registered WithMessageHandler and queues it on every running
+// service's channel. It returns the handlers' joined errors. The controller's
+// own messages are rejected with ErrReservedMessage; send those on Messages.
+func (c *Controller) Broadcast(msg Message) error {
+	if slices.Contains(reserved, msg) {
+		return fmt.Errorf("%w: %s", ErrReservedMessage, msg)
+	}
+
+	return c.audited(programmatic, string(msg), "", "", func() error {
+		return c.services.handle(msg)
+	})
+}
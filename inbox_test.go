@@ -0,0 +1,176 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_ServiceMessages(t *testing.T) {
+	received := make(chan controls.Message, 4)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("worker",
+		controls.WithStart(func(ctx context.Context) error {
+			controls.Ready(ctx)
+
+			for msg := range controls.MessagesFrom(ctx) {
+				received <- msg
+
+				if msg == controls.Stop {
+					return nil
+				}
+			}
+
+			return nil
+		}),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	h, err := c.Service("worker")
+	require.NoError(t, err)
+	assert.Equal(t, "worker", h.Name())
+
+	c.Start()
+
+	c.Messages() <- controls.Reload
+	c.Messages() <- controls.Message("flush-caches")
+
+	require.NoError(t, c.Stop())
+
+	for _, want := range []controls.Message{controls.Reload, "flush-caches", controls.Stop} {
+		select {
+		case got := <-received:
+			assert.Equal(t, want, got)
+		case <-time.After(time.Second):
+			t.Fatalf("service did not receive %q", want)
+		}
+	}
+
+	_, err = c.Service("missing")
+	require.ErrorIs(t, err, controls.ErrServiceNotFound)
+	assert.Nil(t, controls.MessagesFrom(context.Background()))
+}
+
+func TestController_Broadcast(t *testing.T) {
+	errFlush := errors.New("flush failed") //nolint:err113
+
+	var handled []string
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+
+	for _, name := range []string{"cache", "db"} {
+		c.Register(name,
+			controls.WithStart(noopStart),
+			controls.WithStop(func(_ context.Context) {}),
+			controls.WithMessageHandler(func(msg controls.Message) error {
+				handled = append(handled, name+":"+string(msg))
+
+				if name == "db" {
+					return errFlush
+				}
+
+				return nil
+			}),
+		)
+	}
+
+	c.Start()
+
+	err := c.Broadcast("flush-caches")
+	require.ErrorIs(t, err, errFlush)
+	assert.EqualError(t, err, "db: flush failed")
+	assert.Equal(t, []string{"cache:flush-caches", "db:flush-caches"}, handled)
+
+	h, err := c.Service("cache")
+	require.NoError(t, err)
+	assert.Equal(t, controls.Message("flush-caches"), <-h.Messages())
+
+	require.ErrorIs(t, c.Broadcast(controls.Stop), controls.ErrReservedMessage)
+	require.NoError(t, c.Stop())
+}
+
+func TestController_RegisterReturnsHandle(t *testing.T) {
+	errBoom := errors.New("boom") //nolint:err113
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	api := c.Register("api",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+	broken := c.Register("broken",
+		controls.WithStart(func(_ context.Context) error { return errBoom }),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	h, err := c.Service("api")
+	require.NoError(t, err)
+	assert.Same(t, h, api)
+	assert.Equal(t, controls.Unknown, api.State())
+
+	c.Start()
+	assert.Equal(t, controls.Running, api.State())
+	require.NoError(t, api.LastError())
+
+	require.Eventually(t, func() bool { return broken.State() == controls.Failed }, time.Second, time.Millisecond)
+	require.ErrorIs(t, broken.LastError(), errBoom)
+
+	require.NoError(t, api.Stop(context.Background()))
+	assert.Equal(t, controls.Stopped, api.State())
+
+	require.NoError(t, api.Restart(context.Background()))
+	assert.Equal(t, controls.Running, api.State())
+
+	var types []controls.EventType
+	for _, e := range api.Events() {
+		assert.Equal(t, "api", e.Service)
+		types = append(types, e.Type)
+	}
+
+	assert.Contains(t, types, controls.ServiceStopped)
+	assert.Contains(t, types, controls.ServiceRestarted)
+
+	audit := c.AuditLog()
+	require.Len(t, audit, 2)
+	assert.Equal(t, controls.ActionStopService, audit[0].Command)
+	assert.Equal(t, "api", audit[0].Service)
+
+	require.NoError(t, c.Stop())
+}
+
+func TestServiceHandle_FollowsAdoption(t *testing.T) {
+	quiet := []controls.ControllerOpt{controls.WithLogger(discardLogger()), controls.WithoutSignals()}
+
+	sub := controls.NewController(context.Background(), quiet...)
+	h := sub.Register("worker",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	c := controls.NewController(context.Background(), quiet...)
+	require.NoError(t, c.Adopt(sub))
+
+	c.Start()
+	assert.Equal(t, controls.Running, h.State())
+
+	require.NoError(t, h.Stop(context.Background()))
+	assert.Equal(t, controls.Stopped, h.State())
+	assert.NotEmpty(t, h.Events())
+
+	require.NoError(t, c.Stop())
+}
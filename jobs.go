@@ -0,0 +1,145 @@
+package controls
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// JobFunc is a unit of scheduled work. Its context is cancelled when the
+// service stops. Errors are logged and the schedule carries on.
+type JobFunc func(context.Context) error
+
+// TickerService returns a service that calls fn every interval, measured
+// with the controller's clock, until it stops.
+func TickerService(name string, interval time.Duration, fn JobFunc, opts ...ServiceOption) Service {
+	return ScheduledService(name, Every(interval), fn, opts...)
+}
+
+// CronService returns a service that calls fn on the cron schedule spec, as
+// parsed by ParseCron. An invalid spec fails the service's start.
+func CronService(name, spec string, fn JobFunc, opts ...ServiceOption) Service {
+	schedule, err := ParseCron(spec)
+
+	return scheduled(name, schedule, err, fn, opts)
+}
+
+// ScheduledService returns a service that calls fn at each time returned by
+// schedule, for Register or WithServices. Runs never overlap: a run that
+// overlaps the next activation delays it, and missed activations are skipped.
+// Stopping the service cancels the running job's context and waits for it to
+// return. opts are applied after the service's own.
+func ScheduledService(name string, schedule Schedule, fn JobFunc, opts ...ServiceOption) Service {
+	return scheduled(name, schedule, nil, fn, opts)
+}
+
+func scheduled(name string, schedule Schedule, err error, fn JobFunc, opts []ServiceOption) Service {
+	j := &job{schedule: schedule, invalid: err, fn: fn}
+
+	return newService(name, append([]ServiceOption{
+		WithStart(j.start),
+		WithStop(j.stop),
+		WithDetails(j.details),
+	}, opts...)...)
+}
+
+type job struct {
+	schedule Schedule
+	invalid  error
+	fn       JobFunc
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	done    chan struct{}
+	next    time.Time
+	lastRun time.Time
+}
+
+func (j *job) start(ctx context.Context) error {
+	if j.invalid != nil {
+		return j.invalid
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	j.mu.Lock()
+	j.cancel = cancel
+	j.done = done
+	j.mu.Unlock()
+
+	go j.run(runCtx, clockFrom(ctx), done)
+
+	return nil
+}
+
+func (j *job) run(ctx context.Context, clock Clock, done chan struct{}) {
+	defer close(done)
+
+	logger := LoggerFrom(ctx)
+
+	for {
+		now := clock.Now()
+
+		next := j.schedule.Next(now)
+		if next.IsZero() {
+			logger.Warn("Schedule has no further activations")
+
+			return
+		}
+
+		j.mu.Lock()
+		j.next = next
+		j.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-clock.After(next.Sub(now)):
+		}
+
+		j.mu.Lock()
+		j.lastRun = clock.Now()
+		j.mu.Unlock()
+
+		if err := j.fn(ctx); err != nil {
+			logger.Error("Scheduled job failed", "error", err)
+		}
+	}
+}
+
+func (j *job) stop(ctx context.Context) {
+	j.mu.Lock()
+	cancel, done := j.cancel, j.done
+	j.cancel, j.done = nil, nil
+	j.next = time.Time{}
+	j.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+func (j *job) details() map[string]string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	d := map[string]string{}
+
+	if !j.next.IsZero() {
+		d["next_run"] = j.next.Format(time.RFC3339)
+	}
+
+	if !j.lastRun.IsZero() {
+		d["last_run"] = j.lastRun.Format(time.RFC3339)
+	}
+
+	return d
+}
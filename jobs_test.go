@@ -0,0 +1,85 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/controlstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_TickerService(t *testing.T) {
+	clock := controlstest.NewFakeClock(epoch)
+
+	var (
+		runs      atomic.Int64
+		cancelled atomic.Bool
+	)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithServices(controls.TickerService("sweeper", time.Minute, func(ctx context.Context) error {
+			if runs.Add(1) == 2 {
+				<-ctx.Done()
+				cancelled.Store(true)
+			}
+
+			return errors.New("logged and ignored") //nolint:err113
+		})),
+		controls.WithClock(clock),
+	)
+
+	c.Start()
+
+	for i := int64(1); i <= 2; i++ {
+		require.Eventually(t, func() bool { return clock.Waiters() > 0 }, time.Second, time.Millisecond)
+		clock.Advance(time.Minute)
+		require.Eventually(t, func() bool { return runs.Load() == i }, time.Second, time.Millisecond)
+	}
+
+	assert.Equal(t, clock.Now().Format(time.RFC3339), c.Report().Services[0].Details["last_run"])
+
+	require.NoError(t, c.Stop())
+	assert.True(t, cancelled.Load())
+	assert.Equal(t, int64(2), runs.Load())
+}
+
+func TestController_CronService(t *testing.T) {
+	clock := controlstest.NewFakeClock(epoch)
+
+	var runs atomic.Int64
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithClock(clock),
+	)
+	require.NoError(t, c.RegisterAll(
+		controls.CronService("nightly", "0 3 * * *", func(_ context.Context) error {
+			runs.Add(1)
+
+			return nil
+		}),
+		controls.CronService("broken", "every tuesday", func(_ context.Context) error { return nil }),
+	))
+
+	c.Start()
+
+	require.Eventually(t, func() bool {
+		return c.Report().Services[1].State == controls.Failed
+	}, time.Second, time.Millisecond)
+	assert.ErrorIs(t, c.Report().Services[1].LastError, controls.ErrInvalidCron)
+	assert.Eventually(t, func() bool {
+		return c.Report().Services[0].Details["next_run"] == "2026-01-01T03:00:00Z"
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, c.Stop())
+	assert.Zero(t, runs.Load())
+}
@@ -0,0 +1,265 @@
+// Package kafka runs a Kafka consumer group as a controls service. It works
+// through the small Group interface rather than a particular client, so a
+// sarama ConsumerGroup or a franz-go client can be adapted in a few lines.
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/phpboyscout/controls"
+)
+
+const (
+	// DefaultMaxLag is how many messages the group may fall behind before
+	// it is reported unhealthy.
+	DefaultMaxLag = 10000
+	// DefaultRetryDelay is how long the service waits before rejoining the
+	// group after consuming fails.
+	DefaultRetryDelay = time.Second
+)
+
+// ErrLagging is reported by the health check while the group's lag exceeds
+// its maximum.
+var ErrLagging = errors.New("consumer group lagging")
+
+// Group is a member of a Kafka consumer group.
+type Group interface {
+	// Consume joins the group and consumes the partitions assigned to it
+	// until ctx is done or the group rebalances. It calls joined once it has
+	// joined the group, whether or not it was assigned any partitions. It
+	// returns nil when a rebalance ends the session, and is then called
+	// again to rejoin.
+	Consume(ctx context.Context, joined func()) error
+	// Lag returns how many messages the group is behind across the
+	// partitions assigned to it.
+	Lag(ctx context.Context) (int64, error)
+	// Close leaves the group, committing the offsets consumed.
+	Close() error
+}
+
+// ConnectFunc creates the group member each time the service starts, as a
+// closed member can't rejoin.
+type ConnectFunc func(context.Context) (Group, error)
+
+type Option func(*consumer)
+
+// WithMaxLag overrides DefaultMaxLag. A limit of zero or less turns off the
+// lag check.
+func WithMaxLag(n int64) Option {
+	return func(c *consumer) {
+		c.maxLag = n
+	}
+}
+
+// WithRetryDelay overrides DefaultRetryDelay.
+func WithRetryDelay(d time.Duration) Option {
+	return func(c *consumer) {
+		c.retryDelay = d
+	}
+}
+
+// Service returns a service consuming with the group member returned by
+// connect. Start returns once the member has joined the group, and the
+// member rejoins whenever the group rebalances. If consuming fails the member
+// rejoins after the retry delay, and the service is unhealthy until it
+// succeeds. Stop cancels consumption, lets the current session finish within
+// the stop timeout and then closes the member, committing its offsets. The
+// health check also fails while the group's lag exceeds its maximum, and the
+// lag and number of rebalances are reported in the service's Details.
+func Service(name string, connect ConnectFunc, opts ...Option) controls.Service {
+	c := &consumer{connect: connect, maxLag: DefaultMaxLag, retryDelay: DefaultRetryDelay}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return controls.Service{
+		Name:        name,
+		Start:       c.start,
+		StopErr:     c.stop,
+		HealthCheck: c.check,
+		Details:     c.details,
+	}
+}
+
+type consumer struct {
+	connect    ConnectFunc
+	maxLag     int64
+	retryDelay time.Duration
+
+	mu         sync.Mutex
+	group      Group
+	cancel     context.CancelCauseFunc
+	done       chan struct{}
+	err        error
+	lag        int64
+	rebalances int
+}
+
+func (c *consumer) start(ctx context.Context) error {
+	group, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Consumption outlives Start, so it mustn't be cancelled along with its
+	// context.
+	runCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	joined := make(chan struct{})
+	done := make(chan struct{})
+
+	c.mu.Lock()
+	c.group, c.cancel, c.done = group, cancel, done
+	c.err, c.lag, c.rebalances = nil, 0, 0
+	c.mu.Unlock()
+
+	go c.consume(runCtx, group, sync.OnceFunc(func() { close(joined) }), done)
+
+	select {
+	case <-joined:
+		return nil
+	case <-done:
+		c.mu.Lock()
+		err := c.err
+		c.mu.Unlock()
+
+		return errors.Join(err, c.stop(ctx))
+	case <-ctx.Done():
+		_ = c.stop(ctx)
+
+		return context.Cause(ctx)
+	}
+}
+
+// consume rejoins the group after each rebalance or failure until ctx is
+// done. It gives up if the first attempt fails before joining, leaving start
+// to report the error. A rebalance rejoins at once, but no sooner than the
+// retry delay after the last session began, and a session that ends without
+// joining waits out the retry delay, so a member that keeps returning
+// straight away doesn't spin.
+func (c *consumer) consume(ctx context.Context, group Group, joined func(), done chan struct{}) {
+	defer close(done)
+
+	logger := controls.LoggerFrom(ctx)
+
+	var hasJoined atomic.Bool
+
+	for {
+		began := time.Now()
+
+		var session atomic.Bool
+
+		err := group.Consume(ctx, func() {
+			session.Store(true)
+			hasJoined.Store(true)
+			joined()
+		})
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+
+		delay := c.retryDelay
+
+		switch {
+		case err != nil && !hasJoined.Load():
+			return
+		case err != nil:
+			logger.Error("Consuming failed", "error", err, "retry", c.retryDelay)
+		case !session.Load():
+			logger.Warn("Consumer session ended before joining the group", "retry", c.retryDelay)
+		default:
+			c.mu.Lock()
+			c.rebalances++
+			c.mu.Unlock()
+
+			logger.Info("Consumer group rebalanced")
+
+			delay -= time.Since(began)
+		}
+
+		if delay <= 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// stop cancels consumption, waits for the session to end and closes the
+// group member.
+func (c *consumer) stop(ctx context.Context) error {
+	c.mu.Lock()
+	group, cancel, done := c.group, c.cancel, c.done
+	c.group, c.cancel, c.done = nil, nil, nil
+	c.mu.Unlock()
+
+	if group == nil {
+		return nil
+	}
+
+	cancel(controls.ErrStopping)
+
+	var err error
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("session still running: %w", context.Cause(ctx))
+	}
+
+	return errors.Join(err, group.Close())
+}
+
+func (c *consumer) check(ctx context.Context) error {
+	c.mu.Lock()
+	group, err := c.group, c.err
+	c.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if group == nil {
+		return nil
+	}
+
+	lag, err := group.Lag(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.lag = lag
+	c.mu.Unlock()
+
+	if c.maxLag > 0 && lag > c.maxLag {
+		return fmt.Errorf("%w: %d messages behind, limit %d", ErrLagging, lag, c.maxLag)
+	}
+
+	return nil
+}
+
+func (c *consumer) details() map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return map[string]string{
+		"lag":        strconv.FormatInt(c.lag, 10),
+		"rebalances": strconv.Itoa(c.rebalances),
+	}
+}
@@ -0,0 +1,197 @@
+package kafka_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/kafka"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// group is a fake consumer group member. Each session runs until the context
+// is cancelled or a value is sent on sessions, which ends it with that error;
+// nil stands for a rebalance.
+type group struct {
+	sessions chan error
+	joinErr  error
+	lag      atomic.Int64
+	closed   atomic.Bool
+	joins    atomic.Int64
+}
+
+func newGroup() *group {
+	return &group{sessions: make(chan error)}
+}
+
+func (g *group) Consume(ctx context.Context, joined func()) error {
+	if g.joinErr != nil {
+		return g.joinErr
+	}
+
+	g.joins.Add(1)
+	joined()
+
+	select {
+	case err := <-g.sessions:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (g *group) Lag(_ context.Context) (int64, error) {
+	return g.lag.Load(), nil
+}
+
+func (g *group) Close() error {
+	g.closed.Store(true)
+
+	return nil
+}
+
+func newController(t *testing.T, svc controls.Service) *controls.Controller {
+	t.Helper()
+
+	return controls.NewController(context.Background(),
+		controls.WithLogger(slog.New(slog.DiscardHandler)),
+		controls.WithoutSignals(),
+		controls.WithServices(svc),
+	)
+}
+
+func connect(g *group) kafka.ConnectFunc {
+	return func(_ context.Context) (kafka.Group, error) {
+		return g, nil
+	}
+}
+
+func TestService(t *testing.T) {
+	g := newGroup()
+	c := newController(t, kafka.Service("orders", connect(g),
+		kafka.WithMaxLag(100),
+		kafka.WithRetryDelay(time.Millisecond),
+	))
+
+	require.NoError(t, c.Start())
+	assert.Equal(t, int64(1), g.joins.Load())
+
+	g.sessions <- nil
+	require.Eventually(t, func() bool { return g.joins.Load() == 2 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "1", c.Report().Services[0].Details["rebalances"])
+
+	_, err := c.Status(context.Background())
+	require.NoError(t, err)
+
+	g.lag.Store(500)
+
+	_, err = c.Status(context.Background())
+	require.ErrorIs(t, err, kafka.ErrLagging)
+	assert.Equal(t, "500", c.Report().Services[0].Details["lag"])
+
+	g.lag.Store(0)
+
+	errBroker := errors.New("broker unavailable") //nolint:err113
+	g.sessions <- errBroker
+
+	require.Eventually(t, func() bool { return g.joins.Load() == 3 }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, c.Stop())
+	assert.True(t, g.closed.Load())
+}
+
+func TestService_JoinFailure(t *testing.T) {
+	errJoin := errors.New("no brokers") //nolint:err113
+
+	g := newGroup()
+	g.joinErr = errJoin
+
+	svc := kafka.Service("orders", connect(g))
+
+	err := svc.Start(context.Background())
+	require.ErrorIs(t, err, errJoin)
+	assert.True(t, g.closed.Load())
+}
+
+func TestService_ConnectFailure(t *testing.T) {
+	errConnect := errors.New("bad config") //nolint:err113
+
+	svc := kafka.Service("orders", func(_ context.Context) (kafka.Group, error) {
+		return nil, errConnect
+	})
+
+	require.ErrorIs(t, svc.Start(context.Background()), errConnect)
+	require.NoError(t, svc.StopErr(context.Background()))
+}
+
+func TestService_Restart(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		members []*group
+	)
+
+	svc := kafka.Service("orders", func(_ context.Context) (kafka.Group, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		g := newGroup()
+		members = append(members, g)
+
+		return g, nil
+	})
+
+	for range 2 {
+		require.NoError(t, svc.Start(context.Background()))
+		require.NoError(t, svc.StopErr(context.Background()))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, members, 2)
+	assert.True(t, members[0].closed.Load())
+	assert.True(t, members[1].closed.Load())
+}
+
+// hasty is a group member whose sessions end as soon as they begin.
+type hasty struct {
+	join     bool
+	sessions atomic.Int64
+}
+
+func (h *hasty) Consume(_ context.Context, joined func()) error {
+	if h.sessions.Add(1) == 1 || h.join {
+		joined()
+	}
+
+	return nil
+}
+
+func (h *hasty) Lag(_ context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (h *hasty) Close() error {
+	return nil
+}
+
+func TestService_NoSpin(t *testing.T) {
+	for _, join := range []bool{true, false} {
+		h := &hasty{join: join}
+		svc := kafka.Service("orders", func(_ context.Context) (kafka.Group, error) { return h, nil },
+			kafka.WithRetryDelay(20*time.Millisecond))
+
+		require.NoError(t, svc.Start(context.Background()))
+		time.Sleep(100 * time.Millisecond)
+		require.NoError(t, svc.StopErr(context.Background()))
+
+		assert.LessOrEqual(t, h.sessions.Load(), int64(7), "joined every session: %v", join)
+	}
+}
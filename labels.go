@@ -0,0 +1,130 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// WithLabels attaches metadata labels to the service, merging with any set
+// earlier. Labels are used to select groups of services, for example to stop
+// every service labelled tier=edge.
+func WithLabels(labels map[string]string) ServiceOption {
+	return func(s *Service) {
+		if s.Labels == nil {
+			s.Labels = make(map[string]string, len(labels))
+		}
+
+		maps.Copy(s.Labels, labels)
+	}
+}
+
+// matchLabels reports whether labels carries every key and value in selector.
+// An empty selector matches everything.
+func matchLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if got, ok := labels[k]; !ok || got != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// selectorString formats selector as sorted key=value pairs.
+func selectorString(selector map[string]string) string {
+	pairs := make([]string, 0, len(selector))
+	for _, k := range slices.Sorted(maps.Keys(selector)) {
+		pairs = append(pairs, k+"="+selector[k])
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+// Select returns the names of the services whose labels match selector, in
+// registration order.
+func (c *Controller) Select(selector map[string]string) []string {
+	return c.services.selectNames(selector)
+}
+
+// StopServices stops every running service whose labels match selector,
+// honouring dependencies between them, and returns the joined stop errors.
+func (c *Controller) StopServices(selector map[string]string) error {
+	return c.audited(programmatic, "stop_services", "", selectorString(selector), func() error {
+		return c.stopServices(selector)
+	})
+}
+
+func (c *Controller) stopServices(selector map[string]string) error {
+	ctx, cancel := c.withTimeout(context.Background(), c.shutdownTimeout)
+	defer cancel()
+
+	stopped, err := c.services.stopMatching(ctx, selector)
+	c.releaseWaitGroup(len(stopped))
+
+	for _, name := range stopped {
+		c.log().Info("Stopped service", "service", name)
+		c.emit(Event{Type: ServiceStopped, Service: name})
+	}
+
+	return err
+}
+
+// Filter returns a copy of the report holding only the services whose labels
+// match selector.
+func (r Report) Filter(selector map[string]string) Report {
+	services := make([]ServiceReport, 0, len(r.Services))
+
+	for _, s := range r.Services {
+		if matchLabels(s.Labels, selector) {
+			services = append(services, s)
+		}
+	}
+
+	r.Services = services
+
+	return r
+}
+
+func (q *Services) selectNames(selector map[string]string) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var names []string
+
+	for _, s := range q.services {
+		if matchLabels(s.Labels, selector) {
+			names = append(names, s.Name)
+		}
+	}
+
+	return names
+}
+
+// stopMatching stops the live services matching selector in dependency order
+// and returns their names.
+func (q *Services) stopMatching(ctx context.Context, selector map[string]string) ([]string, error) {
+	q.ops.Lock()
+	defer q.ops.Unlock()
+
+	var (
+		names []string
+		errs  []error
+	)
+
+	matched := q.matching(func(s *service) bool {
+		return s.live() && matchLabels(s.Labels, selector)
+	})
+
+	for _, s := range matched {
+		names = append(names, s.Name)
+	}
+
+	for _, wave := range stopWaves(matched) {
+		errs = append(errs, q.haltAll(ctx, wave, q.stopConcurrency))
+	}
+
+	return names, errors.Join(errs...)
+}
@@ -0,0 +1,65 @@
+package controls_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_Labels(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+
+	log := newStopLog()
+	c.Register("db", controls.WithStart(noopStart), controls.WithStop(log.stop("db")),
+		controls.WithLabels(map[string]string{"tier": "data"}))
+	c.Register("gateway", controls.WithStart(noopStart), controls.WithStop(log.stop("gateway")),
+		controls.WithLabels(map[string]string{"tier": "edge"}),
+		controls.WithLabels(map[string]string{"zone": "a"}))
+	c.Register("proxy", controls.WithStart(noopStart), controls.WithStop(log.stop("proxy")),
+		controls.WithLabels(map[string]string{"tier": "edge", "zone": "b"}),
+		controls.WithDependsOn("gateway"))
+
+	c.Start()
+
+	assert.Equal(t, []string{"gateway", "proxy"}, c.Select(map[string]string{"tier": "edge"}))
+	assert.Equal(t, []string{"proxy"}, c.Select(map[string]string{"tier": "edge", "zone": "b"}))
+	assert.Equal(t, []string{"db", "gateway", "proxy"}, c.Select(nil))
+	assert.Empty(t, c.Select(map[string]string{"tier": "none"}))
+
+	report := c.Report().Filter(map[string]string{"zone": "a"})
+	require.Len(t, report.Services, 1)
+	assert.Equal(t, map[string]string{"tier": "edge", "zone": "a"}, report.Services[0].Labels)
+
+	rec := httptest.NewRecorder()
+	c.AdminHandler().ServeHTTP(rec,
+		httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/services?label=tier=data", nil))
+
+	var services []map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &services))
+	require.Len(t, services, 1)
+	assert.Equal(t, "db", services[0]["name"])
+
+	require.NoError(t, c.StopServices(map[string]string{"tier": "edge"}))
+	log.before(t, "proxy", "gateway")
+
+	states := map[string]controls.State{}
+	for _, s := range c.Report().Services {
+		states[s.Name] = s.State
+	}
+
+	assert.Equal(t, map[string]controls.State{
+		"db": controls.Running, "gateway": controls.Stopped, "proxy": controls.Stopped,
+	}, states)
+
+	require.NoError(t, c.Stop())
+}
@@ -0,0 +1,136 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// Hook is a pair of lifecycle callbacks with the same shape as fx.Hook, so
+// either can be built from the other's fields. Both are optional.
+type Hook struct {
+	OnStart func(context.Context) error
+	OnStop  func(context.Context) error
+}
+
+// Lifecycle collects hooks in the style of fx.Lifecycle and runs them as a
+// single service, letting constructors written for fx be driven by a
+// controller.
+type Lifecycle struct {
+	mu    sync.Mutex
+	hooks []Hook
+	// started is how many hooks have started and not yet been stopped.
+	started int
+}
+
+// Lifecycle registers a service called name that runs the returned
+// Lifecycle's hooks. OnStart hooks run in the order they were appended; if
+// one fails, the hooks already started are stopped and the service fails.
+// OnStop hooks run in reverse order, and their errors are joined.
+func (c *Controller) Lifecycle(name string, opts ...ServiceOption) *Lifecycle {
+	l := &Lifecycle{}
+
+	opts = append([]ServiceOption{
+		WithStart(l.start),
+		WithStopErr(l.stop),
+	}, opts...)
+
+	c.Register(name, opts...)
+
+	return l
+}
+
+// Append adds a hook. Hooks should be appended before the controller starts.
+func (l *Lifecycle) Append(h Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = append(l.hooks, h)
+}
+
+func (l *Lifecycle) snapshot() []Hook {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return slices.Clone(l.hooks)
+}
+
+func (l *Lifecycle) start(ctx context.Context) error {
+	for _, h := range l.snapshot() {
+		if h.OnStart != nil {
+			if err := h.OnStart(ctx); err != nil {
+				return errors.Join(err, l.stop(context.WithoutCancel(ctx)))
+			}
+		}
+
+		l.mu.Lock()
+		l.started++
+		l.mu.Unlock()
+	}
+
+	return nil
+}
+
+// stop runs the OnStop hooks of the hooks that started, last first. Once they
+// have been stopped, further calls do nothing until the hooks start again.
+func (l *Lifecycle) stop(ctx context.Context) error {
+	l.mu.Lock()
+	hooks := l.hooks[:l.started]
+	l.started = 0
+	l.mu.Unlock()
+
+	var errs []error
+
+	for _, h := range slices.Backward(hooks) {
+		if h.OnStop == nil {
+			continue
+		}
+
+		if err := h.OnStop(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Hook returns a Hook that starts and stops the controller, for handing it to
+// an fx application:
+//
+//	h := c.Hook()
+//	lc.Append(fx.Hook{OnStart: h.OnStart, OnStop: h.OnStop})
+//
+// OnStart fails with the errors of any services that failed to start, and OnStop gives up
+// waiting for the shutdown once its context is done.
+func (c *Controller) Hook() Hook {
+	return Hook{
+		OnStart: func(_ context.Context) error {
+			if err := c.Start(); err != nil {
+				return err
+			}
+
+			var errs []error
+
+			for _, s := range c.Services() {
+				if s.State == Failed {
+					errs = append(errs, s.LastError)
+				}
+			}
+
+			return errors.Join(errs...)
+		},
+		OnStop: func(ctx context.Context) error {
+			done := make(chan error, 1)
+
+			go func() { done <- c.Stop() }()
+
+			select {
+			case err := <-done:
+				return err
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			}
+		},
+	}
+}
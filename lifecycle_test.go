@@ -0,0 +1,81 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_Lifecycle(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+
+	record := func(call string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			calls = append(calls, call)
+
+			return nil
+		}
+	}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+
+	lc := c.Lifecycle("fx")
+	lc.Append(controls.Hook{OnStart: record("start db"), OnStop: record("stop db")})
+	lc.Append(controls.Hook{OnStart: record("start api")})
+	lc.Append(controls.Hook{OnStart: record("start cache"), OnStop: record("stop cache")})
+
+	c.Start()
+	require.NoError(t, c.Stop())
+
+	assert.Equal(t, []string{"start db", "start api", "start cache", "stop cache", "stop db"}, calls)
+}
+
+func TestController_LifecycleStartFailure(t *testing.T) {
+	errBoom := errors.New("boom") //nolint:err113
+
+	var calls []string
+
+	record := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			calls = append(calls, name)
+
+			return nil
+		}
+	}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+
+	lc := c.Lifecycle("fx")
+	lc.Append(controls.Hook{OnStart: record("start db"), OnStop: record("stop db")})
+	lc.Append(controls.Hook{
+		OnStart: func(context.Context) error { return errBoom },
+		OnStop:  record("stop api"),
+	})
+	lc.Append(controls.Hook{OnStart: record("start cache"), OnStop: record("stop cache")})
+
+	err := c.Hook().OnStart(context.Background())
+	require.ErrorIs(t, err, errBoom)
+
+	require.NoError(t, c.Hook().OnStop(context.Background()))
+	assert.True(t, c.IsStopped())
+	assert.Equal(t, []string{"start db", "stop db"}, calls,
+		"only the hooks that started are stopped, and only once")
+}
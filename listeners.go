@@ -0,0 +1,181 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownListener is returned when a listener is asked for by a name that
+// wasn't declared with WithListener or passed in by systemd.
+var ErrUnknownListener = errors.New("unknown listener")
+
+// ListenerRegistry hands out the controller's named listeners. Each is
+// created on first use: taken from systemd socket activation when a socket of
+// that name was passed in, otherwise through the registry's Handoff, which
+// inherits it from a previous process or listens afresh.
+type ListenerRegistry struct {
+	mu        sync.Mutex
+	addrs     map[string]listenAddr
+	listeners map[string]net.Listener
+	handoff   *Handoff
+}
+
+type listenAddr struct {
+	network, address string
+}
+
+func newListenerRegistry() *ListenerRegistry {
+	return &ListenerRegistry{addrs: map[string]listenAddr{}, listeners: map[string]net.Listener{}}
+}
+
+// WithListener declares a named listener on network and address, for
+// services to ask for with Listener.
+func WithListener(name, network, address string) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.listeners.addrs[name] = listenAddr{network: network, address: address}
+		})
+	}
+}
+
+// WithHandoff makes the controller's listeners go through h, so they are
+// inherited from the process that started this one and handed on by Upgrade.
+// By default the registry makes its own Handoff when it first listens.
+func WithHandoff(h *Handoff) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.listeners.handoff = h
+		})
+	}
+}
+
+// Listeners returns the controller's listener registry.
+func (c *Controller) Listeners() *ListenerRegistry {
+	return c.listeners
+}
+
+// Handoff returns the Handoff the registry's listeners go through, for
+// passing to Upgrade.
+func (r *ListenerRegistry) Handoff() *Handoff {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.handoffLocked()
+}
+
+func (r *ListenerRegistry) handoffLocked() *Handoff {
+	if r.handoff == nil {
+		r.handoff = NewHandoff()
+	}
+
+	return r.handoff
+}
+
+// Listen returns the named listener, creating it on first use.
+func (r *ListenerRegistry) Listen(name string) (net.Listener, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if l, ok := r.listeners[name]; ok {
+		return l, nil
+	}
+
+	addr, declared := r.addrs[name]
+
+	var (
+		l   net.Listener
+		err error
+	)
+
+	switch f := activated.take(name); {
+	case f != nil:
+		l, err = net.FileListener(f)
+		_ = f.Close()
+
+		if err == nil && declared {
+			// Hand the socket on under its declared address.
+			r.handoffLocked().adopt(addr.network+":"+addr.address, l)
+		}
+	case declared:
+		l, err = r.handoffLocked().Listen(addr.network, addr.address)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownListener, name)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("listener %s: %w", name, err)
+	}
+
+	r.listeners[name] = l
+
+	return l, nil
+}
+
+type listenersKey struct{}
+
+// Listener returns the named listener from the registry of the controller
+// running the service that ctx was given to.
+func Listener(ctx context.Context, name string) (net.Listener, error) {
+	r, ok := ctx.Value(listenersKey{}).(*ListenerRegistry)
+	if !ok || r == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownListener, name)
+	}
+
+	return r.Listen(name)
+}
+
+// activated holds the sockets passed in by systemd socket activation, named
+// with FileDescriptorName=. They are read from the environment once, and each
+// can only be taken once.
+var activated = &activation{}
+
+type activation struct {
+	once  sync.Once
+	mu    sync.Mutex
+	files map[string]*os.File
+}
+
+func (a *activation) take(name string) *os.File {
+	a.once.Do(a.load)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f := a.files[name]
+	delete(a.files, name)
+
+	return f
+}
+
+func (a *activation) load() {
+	a.files = map[string]*os.File{}
+
+	defer func() {
+		_ = os.Unsetenv("LISTEN_PID")
+		_ = os.Unsetenv("LISTEN_FDS")
+		_ = os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return
+	}
+
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil {
+		return
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	for i := range n {
+		if i < len(names) && names[i] != "" {
+			a.files[names[i]] = os.NewFile(uintptr(firstInheritedFD+i), names[i])
+		}
+	}
+}
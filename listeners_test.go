@@ -0,0 +1,54 @@
+package controls_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_Listeners(t *testing.T) {
+	h := controls.NewHandoff()
+	got := make(chan net.Listener, 1)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithHandoff(h),
+		controls.WithListener("http", "tcp", "127.0.0.1:0"),
+	)
+	c.Register("api",
+		controls.WithStart(func(ctx context.Context) error {
+			l, err := controls.Listener(ctx, "http")
+			got <- l
+
+			return err
+		}),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	c.Start()
+	defer c.Stop()
+
+	l := <-got
+	require.NotNil(t, l)
+
+	again, err := c.Listeners().Listen("http")
+	require.NoError(t, err)
+	assert.Same(t, l, again)
+
+	viaHandoff, err := h.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	assert.Same(t, l, viaHandoff, "the listener is handed off on upgrade")
+	assert.Same(t, h, c.Listeners().Handoff())
+
+	_, err = c.Listeners().Listen("grpc")
+	require.ErrorIs(t, err, controls.ErrUnknownListener)
+
+	_, err = controls.Listener(context.Background(), "http")
+	require.ErrorIs(t, err, controls.ErrUnknownListener)
+}
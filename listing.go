@@ -0,0 +1,100 @@
+package controls
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"time"
+)
+
+// ServiceInfo briefly describes a registered service. Report has the full
+// detail.
+type ServiceInfo struct {
+	Name      string
+	State     State
+	Ready     bool
+	LastError error
+	Labels    map[string]string
+}
+
+// Services lists the registered services in registration order.
+func (c *Controller) Services() []ServiceInfo {
+	return c.services.infos()
+}
+
+// Snapshot is a consistent view of the controller and its services, taken in
+// one go. Later changes don't affect it.
+type Snapshot struct {
+	Time     time.Time
+	State    State
+	Services []ServiceInfo
+}
+
+// Snapshot returns the controller's state and its services in registration
+// order, for dashboards and debug dumps.
+func (c *Controller) Snapshot() Snapshot {
+	return Snapshot{
+		Time:     c.clock.Now(),
+		State:    c.GetState(),
+		Services: c.services.infos(),
+	}
+}
+
+// All iterates over the snapshot's services in registration order.
+func (s Snapshot) All() iter.Seq[ServiceInfo] {
+	return slices.Values(s.Services)
+}
+
+// Lookup returns the named service as it was when the snapshot was taken.
+func (s Snapshot) Lookup(name string) (ServiceInfo, bool) {
+	i := slices.IndexFunc(s.Services, func(info ServiceInfo) bool { return info.Name == name })
+	if i < 0 {
+		return ServiceInfo{}, false
+	}
+
+	return s.Services[i], true
+}
+
+// All iterates over the registered services in registration order. The
+// services are read when iteration begins, so the loop sees a consistent view
+// and may safely call back into the controller, say to stop a service.
+func (c *Controller) All() iter.Seq[ServiceInfo] {
+	return func(yield func(ServiceInfo) bool) {
+		for _, info := range c.services.infos() {
+			if !yield(info) {
+				return
+			}
+		}
+	}
+}
+
+// RunningCount returns how many services are running.
+func (c *Controller) RunningCount() int {
+	n := 0
+
+	for _, s := range c.Services() {
+		if s.State == Running {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (q *Services) infos() []ServiceInfo {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	infos := make([]ServiceInfo, 0, len(q.services))
+	for _, s := range q.services {
+		infos = append(infos, ServiceInfo{
+			Name:      s.Name,
+			State:     s.state,
+			Ready:     s.ready,
+			LastError: s.lastErr,
+			Labels:    maps.Clone(s.Labels),
+		})
+	}
+
+	return infos
+}
@@ -0,0 +1,107 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_Services(t *testing.T) {
+	errBoom := errors.New("boom") //nolint:err113
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("api",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithLabels(map[string]string{"tier": "edge"}),
+	)
+	c.Register("worker",
+		controls.WithStart(func(_ context.Context) error { return errBoom }),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+	c.Register("cron",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	assert.Zero(t, c.RunningCount())
+
+	c.Start()
+	require.NoError(t, c.StopService("cron"))
+
+	assert.Eventually(t, func() bool { return c.Services()[1].State == controls.Failed }, time.Second, time.Millisecond)
+
+	services := c.Services()
+	require.Len(t, services, 3)
+
+	assert.Equal(t, "api", services[0].Name)
+	assert.Equal(t, controls.Running, services[0].State)
+	assert.True(t, services[0].Ready)
+	assert.Equal(t, map[string]string{"tier": "edge"}, services[0].Labels)
+
+	assert.Equal(t, controls.Failed, services[1].State)
+	require.ErrorIs(t, services[1].LastError, errBoom)
+
+	assert.Equal(t, controls.Stopped, services[2].State)
+
+	assert.Equal(t, 1, c.RunningCount())
+
+	c.Stop()
+}
+
+func TestController_SnapshotAndAll(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+
+	for _, name := range []string{"api", "worker", "cron"} {
+		c.Register(name,
+			controls.WithStart(noopStart),
+			controls.WithStop(func(_ context.Context) {}),
+			controls.WithLabels(map[string]string{"name": name}),
+		)
+	}
+
+	require.NoError(t, c.Start())
+
+	snap := c.Snapshot()
+	assert.Equal(t, controls.Running, snap.State)
+	assert.False(t, snap.Time.IsZero())
+
+	var names []string
+	for info := range c.All() {
+		names = append(names, info.Name)
+
+		// the loop can act on the controller without deadlocking or
+		// disturbing the iteration
+		require.NoError(t, c.StopService(info.Name))
+	}
+
+	assert.Equal(t, []string{"api", "worker", "cron"}, names)
+
+	for info := range snap.All() {
+		assert.Equal(t, controls.Running, info.State, "the snapshot doesn't change")
+	}
+
+	snap.Services[0].Labels["name"] = "changed"
+	assert.Equal(t, "api", c.Snapshot().Services[0].Labels["name"])
+
+	worker, ok := c.Snapshot().Lookup("worker")
+	require.True(t, ok)
+	assert.Equal(t, controls.Stopped, worker.State)
+
+	_, ok = snap.Lookup("missing")
+	assert.False(t, ok)
+
+	require.NoError(t, c.Stop())
+}
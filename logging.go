@@ -0,0 +1,218 @@
+package controls
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"strings"
+)
+
+// Logger is the minimal leveled, key-value logging interface the controller
+// can write to. *slog.Logger satisfies it, and thin wrappers around zap's
+// SugaredLogger or zerolog can too. args alternate keys and values.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// WithLogBackend routes the controller's logging to l. It is equivalent to
+// WithLogger(slog.New(NewLoggerHandler(l))).
+func WithLogBackend(l Logger) ControllerOpt {
+	return WithLogger(slog.New(NewLoggerHandler(l)))
+}
+
+// NewLoggerHandler returns a slog.Handler that forwards records to l, flattening
+// attributes and groups into dotted key-value pairs.
+func NewLoggerHandler(l Logger) slog.Handler {
+	return loggerHandler{logger: l}
+}
+
+type loggerHandler struct {
+	logger Logger
+	args   []any
+	group  string
+}
+
+func (h loggerHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h loggerHandler) Handle(_ context.Context, r slog.Record) error {
+	args := append([]any{}, h.args...)
+
+	r.Attrs(func(a slog.Attr) bool {
+		args = appendAttr(args, h.group, a)
+
+		return true
+	})
+
+	switch {
+	case r.Level >= slog.LevelError:
+		h.logger.Error(r.Message, args...)
+	case r.Level >= slog.LevelWarn:
+		h.logger.Warn(r.Message, args...)
+	case r.Level >= slog.LevelInfo:
+		h.logger.Info(r.Message, args...)
+	default:
+		h.logger.Debug(r.Message, args...)
+	}
+
+	return nil
+}
+
+func (h loggerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	args := append([]any{}, h.args...)
+	for _, a := range attrs {
+		args = appendAttr(args, h.group, a)
+	}
+
+	h.args = args
+
+	return h
+}
+
+func (h loggerHandler) WithGroup(name string) slog.Handler {
+	if name != "" {
+		h.group += name + "."
+	}
+
+	return h
+}
+
+func appendAttr(args []any, prefix string, a slog.Attr) []any {
+	a.Value = a.Value.Resolve()
+
+	if a.Value.Kind() != slog.KindGroup {
+		if a.Equal(slog.Attr{}) {
+			return args
+		}
+
+		return append(args, prefix+a.Key, a.Value.Any())
+	}
+
+	if a.Key != "" {
+		prefix += a.Key + "."
+	}
+
+	for _, ga := range a.Value.Group() {
+		args = appendAttr(args, prefix, ga)
+	}
+
+	return args
+}
+
+// NewStdLogger adapts a standard library *log.Logger to Logger, writing each
+// record as "LEVEL msg key=value ...".
+func NewStdLogger(l *log.Logger) Logger {
+	return stdLogger{l}
+}
+
+type stdLogger struct {
+	l *log.Logger
+}
+
+func (s stdLogger) Debug(msg string, args ...any) { s.print("DEBUG", msg, args) }
+func (s stdLogger) Info(msg string, args ...any)  { s.print("INFO", msg, args) }
+func (s stdLogger) Warn(msg string, args ...any)  { s.print("WARN", msg, args) }
+func (s stdLogger) Error(msg string, args ...any) { s.print("ERROR", msg, args) }
+
+func (s stdLogger) print(level, msg string, args []any) {
+	var b strings.Builder
+
+	b.WriteString(level + " " + msg)
+
+	for i := 0; i < len(args); i += 2 {
+		if i+1 < len(args) {
+			fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+		} else {
+			fmt.Fprintf(&b, " %v", args[i])
+		}
+	}
+
+	s.l.Print(b.String())
+}
+
+// WithQuiet stops the controller logging anything below slog.LevelError on its
+// own, for CLIs where lifecycle chatter would pollute command output. The
+// suppressed information remains available through Report and events.
+func WithQuiet() ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.quiet = true
+		})
+	}
+}
+
+// log returns the logger the controller uses for its own messages.
+func (c *Controller) log() *slog.Logger {
+	if !c.quiet {
+		return c.logger
+	}
+
+	return slog.New(minLevelHandler{Handler: c.logger.Handler(), min: slog.LevelError})
+}
+
+// minLevelHandler drops records below min before they reach the wrapped handler.
+type minLevelHandler struct {
+	slog.Handler
+	min slog.Level
+}
+
+func (h minLevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.min && h.Handler.Enabled(ctx, level)
+}
+
+func (h minLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return minLevelHandler{Handler: h.Handler.WithAttrs(attrs), min: h.min}
+}
+
+func (h minLevelHandler) WithGroup(name string) slog.Handler {
+	return minLevelHandler{Handler: h.Handler.WithGroup(name), min: h.min}
+}
+
+type loggerKey struct{}
+
+// LoggerFrom returns the logger scoped to the service whose Start, Stop or
+// status function was given ctx. Every record it emits carries a "service"
+// attribute. It falls back to slog.Default outside a service call.
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+
+	return slog.Default()
+}
+
+// ServiceLogger returns the logger scoped to the named service, for use from
+// message handlers and other code that isn't handed a context.
+func (c *Controller) ServiceLogger(name string) *slog.Logger {
+	return c.logger.With("service", name)
+}
+
+// scoped returns ctx carrying the logger and control channel for s.
+func (q *Services) scoped(ctx context.Context, s *service) context.Context {
+	logger := slog.Default()
+	if q.logger != nil {
+		logger = q.logger()
+	}
+
+	ctx = context.WithValue(ctx, inboxKey{}, s.handle.inbox)
+	ctx = context.WithValue(ctx, listenersKey{}, q.listeners)
+
+	if q.timeSource != nil {
+		ctx = context.WithValue(ctx, clockKey{}, q.timeSource)
+	}
+
+	return context.WithValue(ctx, loggerKey{}, logger.With("service", s.Name))
+}
+
+func (q *Services) log() *slog.Logger {
+	if q.controlLog == nil {
+		return slog.Default()
+	}
+
+	return q.controlLog()
+}
@@ -0,0 +1,167 @@
+package controls_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_WithQuiet(t *testing.T) {
+	var buf bytes.Buffer
+
+	rec := &eventRecorder{}
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+		controls.WithoutSignals(),
+		controls.WithQuiet(),
+		controls.WithEventHandler(rec.handle),
+	)
+	c.Register("broken",
+		controls.WithStart(func(_ context.Context) error { return fmt.Errorf("boom") }), //nolint:err113
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	c.Start()
+	assert.Eventually(t, func() bool {
+		return len(rec.ofType(controls.ServiceFailed)) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	c.Stop()
+	assert.Eventually(t, c.IsStopped, time.Second, 5*time.Millisecond)
+
+	assert.NotContains(t, buf.String(), "level=INFO")
+	assert.NotContains(t, buf.String(), "level=WARN")
+	assert.Contains(t, buf.String(), "boom")
+
+	var states []controls.State
+	for _, e := range rec.ofType(controls.StateChanged) {
+		states = append(states, e.State)
+	}
+
+	assert.Equal(t, []controls.State{controls.Starting, controls.Failed, controls.Stopping, controls.Stopped}, states)
+}
+
+func TestController_ServiceLoggers(t *testing.T) {
+	var buf bytes.Buffer
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+		controls.WithoutSignals(),
+		controls.WithQuiet(),
+	)
+	c.Register("api",
+		controls.WithStart(func(ctx context.Context) error {
+			controls.LoggerFrom(ctx).Info("starting")
+
+			return nil
+		}),
+		controls.WithStop(func(ctx context.Context) { controls.LoggerFrom(ctx).Info("stopping") }),
+		controls.WithStatusContext(func(ctx context.Context) { controls.LoggerFrom(ctx).Info("checking") }),
+		controls.WithMessageHandler(func(_ controls.Message) error {
+			c.ServiceLogger("api").Info("handling")
+
+			return nil
+		}),
+	)
+
+	c.Start()
+	c.Messages() <- controls.Status
+	c.Messages() <- controls.Reload
+	c.Messages() <- controls.Status
+	assert.NoError(t, c.Stop())
+
+	for _, msg := range []string{"starting", "checking", "handling", "stopping"} {
+		assert.Contains(t, buf.String(), "msg="+msg+" service=api")
+	}
+
+	assert.Equal(t, slog.Default(), controls.LoggerFrom(context.Background()))
+}
+
+type logLine struct {
+	level string
+	msg   string
+	args  []any
+}
+
+type recordingLogger struct {
+	mu    sync.Mutex
+	lines []logLine
+}
+
+func (r *recordingLogger) record(level, msg string, args []any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lines = append(r.lines, logLine{level: level, msg: msg, args: args})
+}
+
+func (r *recordingLogger) find(msg string) (logLine, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, l := range r.lines {
+		if l.msg == msg {
+			return l, true
+		}
+	}
+
+	return logLine{}, false
+}
+
+func (r *recordingLogger) Debug(msg string, args ...any) { r.record("debug", msg, args) }
+func (r *recordingLogger) Info(msg string, args ...any)  { r.record("info", msg, args) }
+func (r *recordingLogger) Warn(msg string, args ...any)  { r.record("warn", msg, args) }
+func (r *recordingLogger) Error(msg string, args ...any) { r.record("error", msg, args) }
+
+func TestController_WithLogBackend(t *testing.T) {
+	backend := &recordingLogger{}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogBackend(backend),
+		controls.WithoutSignals(),
+	)
+	c.Register("api",
+		controls.WithStart(func(ctx context.Context) error {
+			controls.LoggerFrom(ctx).WithGroup("http").Debug("listening", slog.Group("tls", "enabled", true), "port", 80)
+
+			return nil
+		}),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	c.Start()
+	require.NoError(t, c.StopService("api"))
+	require.NoError(t, c.Stop())
+
+	line, ok := backend.find("listening")
+	require.True(t, ok)
+	assert.Equal(t, "debug", line.level)
+	assert.Equal(t, []any{"service", "api", "http.tls.enabled", true, "http.port", int64(80)}, line.args)
+
+	line, ok = backend.find("Stopped service")
+	require.True(t, ok)
+	assert.Equal(t, "info", line.level)
+	assert.Equal(t, []any{"service", "api"}, line.args)
+
+	_, ok = backend.find("Stopped")
+	assert.True(t, ok)
+}
+
+func TestNewStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := slog.New(controls.NewLoggerHandler(controls.NewStdLogger(log.New(&buf, "", 0))))
+	logger.With("service", "api").Warn("slow", "took", "2s")
+
+	assert.Equal(t, "WARN slow service=api took=2s\n", buf.String())
+}
@@ -0,0 +1,146 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// ErrMaintenance is reported by the readiness probe while the controller is
+// in maintenance.
+var ErrMaintenance = errors.New("in maintenance")
+
+// maintenance records whether the controller is in maintenance and the
+// services it paused on entering.
+type maintenance struct {
+	mu     sync.Mutex
+	active bool
+	paused []string
+}
+
+// EnterMaintenance quiesces the controller without stopping it: readiness
+// reports not ready, so traffic drains away, and the running services whose
+// labels match selector are paused. A nil selector pauses nothing. Entering
+// again pauses any further services selector matches. It returns the joined
+// stop errors.
+func (c *Controller) EnterMaintenance(selector map[string]string) error {
+	return c.audited(programmatic, ActionEnterMaintenance, "", selectorString(selector), func() error {
+		return c.enterMaintenance(selector)
+	})
+}
+
+func (c *Controller) enterMaintenance(selector map[string]string) error {
+	if !c.isUp() {
+		return ErrNotRunning
+	}
+
+	c.maintenance.mu.Lock()
+	defer c.maintenance.mu.Unlock()
+
+	if !c.maintenance.active {
+		c.maintenance.active = true
+		c.log().Warn("Entering maintenance")
+	}
+
+	if selector == nil {
+		return nil
+	}
+
+	ctx, cancel := c.withTimeout(context.Background(), c.shutdownTimeout)
+	defer cancel()
+
+	stopped, paused, err := c.services.pauseMatching(ctx, selector)
+	c.releaseWaitGroup(len(stopped))
+
+	for _, name := range stopped {
+		c.log().Info("Stopped service for maintenance", "service", name)
+		c.emit(Event{Type: ServiceStopped, Service: name, Message: "paused for maintenance"})
+	}
+
+	for _, name := range paused {
+		c.log().Info("Paused service for maintenance", "service", name)
+	}
+
+	c.maintenance.paused = append(c.maintenance.paused, stopped...)
+	c.maintenance.paused = append(c.maintenance.paused, paused...)
+	c.assess()
+
+	return err
+}
+
+// ExitMaintenance leaves maintenance, resuming the services it paused unless
+// they have since been stopped or restarted, and waits for them to become
+// ready. It returns the joined resume errors.
+func (c *Controller) ExitMaintenance() error {
+	return c.audited(programmatic, ActionExitMaintenance, "", "", c.exitMaintenance)
+}
+
+func (c *Controller) exitMaintenance() error {
+	if !c.isUp() {
+		return ErrNotRunning
+	}
+
+	c.maintenance.mu.Lock()
+	defer c.maintenance.mu.Unlock()
+
+	if !c.maintenance.active {
+		return nil
+	}
+
+	wg := &sync.WaitGroup{}
+	resumed, launched, err := c.services.resume(c.runCtx, c.maintenance.paused, wg)
+	c.addToWaitGroup(launched)
+	wg.Wait()
+
+	for _, name := range resumed {
+		c.log().Info("Resumed service after maintenance", "service", name)
+	}
+
+	c.maintenance.active = false
+	c.maintenance.paused = nil
+	c.log().Warn("Left maintenance")
+	c.assess()
+
+	return err
+}
+
+// InMaintenance reports whether the controller is in maintenance.
+func (c *Controller) InMaintenance() bool {
+	c.maintenance.mu.Lock()
+	defer c.maintenance.mu.Unlock()
+
+	return c.maintenance.active
+}
+
+// resume resumes the named services that are still paused, adding each to wg
+// until it is ready. It returns their names, how many were launched again
+// rather than resumed in place, and the joined resume errors.
+func (q *Services) resume(ctx context.Context, names []string, wg *sync.WaitGroup) ([]string, int, error) {
+	q.ops.Lock()
+	defer q.ops.Unlock()
+
+	var (
+		resumed  []string
+		launched int
+		errs     []error
+	)
+
+	paused := q.matching(func(s *service) bool {
+		return s.state == Paused && slices.Contains(names, s.Name)
+	})
+
+	for _, s := range paused {
+		wg.Add(1)
+
+		relaunched, err := q.unpause(ctx, s, wg.Done)
+		if relaunched {
+			launched++
+		}
+
+		resumed = append(resumed, s.Name)
+		errs = append(errs, err)
+	}
+
+	return resumed, launched, errors.Join(errs...)
+}
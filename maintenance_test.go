@@ -0,0 +1,106 @@
+package controls_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_Maintenance(t *testing.T) {
+	var workerStarts atomic.Int64
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("api", controls.WithStart(noopStart), controls.WithStop(func(_ context.Context) {}))
+	c.Register("worker",
+		controls.WithStart(func(_ context.Context) error { workerStarts.Add(1); return nil }),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithLabels(map[string]string{"role": "batch"}),
+	)
+
+	require.ErrorIs(t, c.EnterMaintenance(nil), controls.ErrNotRunning)
+
+	c.Start()
+	defer c.Stop()
+
+	require.NoError(t, c.CheckReady())
+
+	require.NoError(t, c.EnterMaintenance(map[string]string{"role": "batch"}))
+	assert.True(t, c.InMaintenance())
+	assert.True(t, c.Report().Maintenance)
+	assert.True(t, c.IsRunning(), "the controller keeps running")
+	require.ErrorIs(t, c.CheckReady(), controls.ErrMaintenance)
+	require.NoError(t, c.CheckLive(context.Background()))
+
+	states := map[string]controls.State{}
+	for _, s := range c.Services() {
+		states[s.Name] = s.State
+	}
+
+	assert.Equal(t, map[string]controls.State{"api": controls.Running, "worker": controls.Paused}, states)
+
+	require.NoError(t, c.ExitMaintenance())
+	assert.False(t, c.InMaintenance())
+	assert.Equal(t, 2, c.RunningCount())
+	assert.Equal(t, int64(2), workerStarts.Load())
+	require.NoError(t, c.CheckReady())
+}
+
+func TestController_MaintenancePausesInPlace(t *testing.T) {
+	var starts, stops, pauses, resumes atomic.Int64
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("consumer",
+		controls.WithStart(func(_ context.Context) error { starts.Add(1); return nil }),
+		controls.WithStop(func(_ context.Context) { stops.Add(1) }),
+		controls.WithPause(func(_ context.Context) error { pauses.Add(1); return nil }),
+		controls.WithResume(func(_ context.Context) error { resumes.Add(1); return nil }),
+	)
+
+	require.NoError(t, c.Start())
+
+	require.NoError(t, c.EnterMaintenance(map[string]string{}))
+	require.NoError(t, c.EnterMaintenance(map[string]string{}))
+	assert.Equal(t, controls.Paused, c.Services()[0].State)
+	assert.Equal(t, int64(1), pauses.Load())
+	assert.Zero(t, stops.Load())
+
+	require.NoError(t, c.ExitMaintenance())
+	assert.Equal(t, controls.Running, c.Services()[0].State)
+	assert.Equal(t, int64(1), resumes.Load())
+	assert.Equal(t, int64(1), starts.Load())
+	require.NoError(t, c.CheckReady())
+
+	require.NoError(t, c.Stop())
+	assert.Equal(t, int64(1), stops.Load())
+}
+
+func TestController_MaintenanceResumeFails(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("consumer",
+		controls.WithStart(noopStart),
+		controls.WithPause(func(_ context.Context) error { return nil }),
+		controls.WithResume(func(_ context.Context) error { return assert.AnError }),
+	)
+
+	require.NoError(t, c.Start())
+	require.NoError(t, c.EnterMaintenance(map[string]string{}))
+
+	require.ErrorIs(t, c.ExitMaintenance(), assert.AnError)
+	assert.Equal(t, controls.Failed, c.Services()[0].State)
+
+	require.NoError(t, c.Stop())
+}
@@ -0,0 +1,118 @@
+package controls
+
+import (
+	"slices"
+	"time"
+)
+
+// DefaultMetricsInterval is how often WithMetrics reports its gauges.
+const DefaultMetricsInterval = 10 * time.Second
+
+// MetricsSink receives the controller's metrics. Tags are "key:value" pairs.
+// Counts are reported from whichever goroutine observes the change, some with
+// the services lock held, so a sink must not block or call back into the
+// controller. The statsd package provides a StatsD and DogStatsD sink.
+type MetricsSink interface {
+	Count(name string, value int64, tags ...string)
+	Gauge(name string, value float64, tags ...string)
+}
+
+// serviceStates lists the states reported by the service.state gauge.
+var serviceStates = []State{Unknown, Running, Paused, Stopping, Stopped, Failed, Completed, Disabled}
+
+// controllerStates lists the states reported by the controller.state gauge.
+var controllerStates = []State{Unknown, Starting, Running, Degraded, Failed, Paused, Draining, Stopping, Stopped}
+
+// WithMetrics reports lifecycle metrics to sink. These counters are tagged
+// with the service:
+//
+//   - service.starts and service.stops, as a service starts and stops
+//   - service.restarts, for each restart
+//   - service.errors, when a service fails
+//   - service.health_failures, for each failed status check
+//
+// Every interval, DefaultMetricsInterval when zero or less, it reports the
+// gauges controller.state, tagged with each state and set to 1 for the
+// current one and 0 for the rest, service.state, likewise tagged with the
+// service, and controller.uptime_seconds.
+func WithMetrics(sink MetricsSink, interval time.Duration) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			if interval <= 0 {
+				interval = DefaultMetricsInterval
+			}
+
+			ctrl.metrics = sink
+			ctrl.metricsInterval = interval
+			ctrl.AddEventHandler(ctrl.countEvent)
+		})
+	}
+}
+
+func (c *Controller) countEvent(e Event) {
+	if e.Synthetic {
+		return
+	}
+
+	switch e.Type {
+	case ServiceRestarted:
+		c.metrics.Count("service.restarts", 1, "service:"+e.Service)
+	case ServiceUnhealthy:
+		c.metrics.Count("service.health_failures", 1, "service:"+e.Service)
+	default:
+	}
+}
+
+// serviceTransitioned records a service's change of state in the history and
+// counts its starts, stops and failures. It is called with the services lock held.
+func (c *Controller) serviceTransitioned(name string, from, to State) {
+	c.history.record(name, from, to)
+
+	if c.metrics == nil {
+		return
+	}
+
+	switch to {
+	case Running:
+		c.metrics.Count("service.starts", 1, "service:"+name)
+	case Stopped:
+		c.metrics.Count("service.stops", 1, "service:"+name)
+	case Failed:
+		c.metrics.Count("service.errors", 1, "service:"+name)
+	default:
+	}
+}
+
+// startMetrics reports the gauges now and then every metrics interval.
+func (c *Controller) startMetrics() {
+	if c.metrics == nil {
+		return
+	}
+
+	c.reportGauges()
+	c.every(c.metricsInterval, func(_ <-chan struct{}) {
+		c.reportGauges()
+	})
+}
+
+func (c *Controller) reportGauges() {
+	r := c.Report()
+
+	gaugeState(c.metrics, "controller.state", r.State, controllerStates)
+	c.metrics.Gauge("controller.uptime_seconds", r.Uptime.Seconds())
+
+	for _, s := range r.Services {
+		gaugeState(c.metrics, "service.state", s.State, serviceStates, "service:"+s.Name)
+	}
+}
+
+func gaugeState(sink MetricsSink, name string, current State, states []State, tags ...string) {
+	for _, state := range states {
+		value := 0.0
+		if state == current {
+			value = 1
+		}
+
+		sink.Gauge(name, value, slices.Concat(tags, []string{"state:" + string(state)})...)
+	}
+}
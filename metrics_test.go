@@ -0,0 +1,109 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/controlstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSink struct {
+	mu     sync.Mutex
+	counts map[string]int64
+	gauges map[string]float64
+}
+
+func newFakeSink() *fakeSink {
+	return &fakeSink{counts: map[string]int64{}, gauges: map[string]float64{}}
+}
+
+func (s *fakeSink) Count(name string, value int64, tags ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.counts[name+"|"+strings.Join(tags, ",")] += value
+}
+
+func (s *fakeSink) Gauge(name string, value float64, tags ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gauges[name+"|"+strings.Join(tags, ",")] = value
+}
+
+func (s *fakeSink) count(key string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.counts[key]
+}
+
+func (s *fakeSink) gauge(key string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.gauges[key]
+}
+
+func TestController_WithMetrics(t *testing.T) {
+	clock := controlstest.NewFakeClock(epoch)
+	sink := newFakeSink()
+	fail := make(chan error)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithClock(clock),
+		controls.WithMetrics(sink, time.Minute),
+	)
+	c.Register("api",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+	c.Register("worker",
+		controls.WithStart(func(ctx context.Context) error {
+			controls.Ready(ctx)
+
+			select {
+			case err := <-fail:
+				return err
+			case <-ctx.Done():
+				return nil
+			}
+		}),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	c.Start()
+	defer c.Stop()
+
+	assert.Equal(t, int64(1), sink.count("service.starts|service:api"))
+	assert.InDelta(t, 1, sink.gauge("controller.state|state:running"), 0)
+	assert.InDelta(t, 0, sink.gauge("controller.state|state:starting"), 0)
+	assert.InDelta(t, 1, sink.gauge("service.state|service:api,state:running"), 0)
+
+	require.NoError(t, c.RestartService("api"))
+	assert.Equal(t, int64(1), sink.count("service.restarts|service:api"))
+	assert.Equal(t, int64(1), sink.count("service.stops|service:api"))
+	assert.Equal(t, int64(2), sink.count("service.starts|service:api"))
+
+	fail <- errors.New("boom") //nolint:err113
+	require.Eventually(t, func() bool {
+		return sink.count("service.errors|service:worker") == 1
+	}, time.Second, time.Millisecond)
+
+	clock.Advance(time.Minute)
+	require.Eventually(t, func() bool {
+		return sink.gauge("service.state|service:worker,state:failed") == 1
+	}, time.Second, time.Millisecond)
+	assert.InDelta(t, 0, sink.gauge("service.state|service:worker,state:running"), 0)
+	assert.InDelta(t, 60, sink.gauge("controller.uptime_seconds|"), 0)
+}
@@ -0,0 +1,170 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPanic is returned by a StartFunc wrapped with RecoverMiddleware when the
+// service panics.
+var ErrPanic = errors.New("service panicked")
+
+type StartMiddleware func(StartFunc) StartFunc
+type StopMiddleware func(StopFunc) StopFunc
+
+// Middleware wraps the start and stop functions of every registered service.
+// Either half may be nil.
+type Middleware struct {
+	Start StartMiddleware
+	Stop  StopMiddleware
+}
+
+// WithServiceMiddleware wraps every service registered with the controller in
+// mw, the first outermost. The stop middleware also wraps WithStopErr functions,
+// which still return their error.
+func WithServiceMiddleware(mw ...Middleware) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.middleware = append(ctrl.middleware, mw...)
+		})
+	}
+}
+
+// wrap applies mw to the service's start and stop functions.
+func (s *Service) wrap(mw []Middleware) {
+	for i := len(mw) - 1; i >= 0; i-- {
+		if mw[i].Start != nil && s.Start != nil {
+			s.Start = mw[i].Start(s.Start)
+		}
+
+		if mw[i].Stop != nil && s.Stop != nil {
+			s.Stop = mw[i].Stop(s.Stop)
+		}
+	}
+
+	if s.StopErr == nil {
+		return
+	}
+
+	stopErr := s.StopErr
+	s.StopErr = func(ctx context.Context) error {
+		var err error
+
+		stop := StopFunc(func(ctx context.Context) { err = stopErr(ctx) })
+		for i := len(mw) - 1; i >= 0; i-- {
+			if mw[i].Stop != nil {
+				stop = mw[i].Stop(stop)
+			}
+		}
+
+		stop(ctx)
+
+		return err
+	}
+}
+
+// RecoverMiddleware turns a panic in a StartFunc into an ErrPanic error, and
+// logs a panic in a StopFunc rather than crashing the shutdown.
+func RecoverMiddleware() Middleware {
+	return Middleware{
+		Start: func(next StartFunc) StartFunc {
+			return func(ctx context.Context) (err error) {
+				defer func() {
+					if r := recover(); r != nil {
+						err = fmt.Errorf("%w: %v", ErrPanic, r)
+					}
+				}()
+
+				return next(ctx)
+			}
+		},
+		Stop: func(next StopFunc) StopFunc {
+			return func(ctx context.Context) {
+				defer func() {
+					if r := recover(); r != nil {
+						LoggerFrom(ctx).Error(ErrPanic.Error(), "panic", r)
+					}
+				}()
+
+				next(ctx)
+			}
+		},
+	}
+}
+
+// TimingMiddleware logs how long each StartFunc and StopFunc took to return,
+// measured with the controller's clock.
+func TimingMiddleware() Middleware {
+	return Middleware{
+		Start: func(next StartFunc) StartFunc {
+			return func(ctx context.Context) error {
+				defer logDuration(ctx, "Start returned", clockFrom(ctx).Now())
+
+				return next(ctx)
+			}
+		},
+		Stop: func(next StopFunc) StopFunc {
+			return func(ctx context.Context) {
+				defer logDuration(ctx, "Stop returned", clockFrom(ctx).Now())
+
+				next(ctx)
+			}
+		},
+	}
+}
+
+func logDuration(ctx context.Context, msg string, begin time.Time) {
+	LoggerFrom(ctx).Info(msg, "duration", clockFrom(ctx).Now().Sub(begin))
+}
+
+// LoggingMiddleware logs each service starting and stopping, and any error
+// its StartFunc returns.
+func LoggingMiddleware() Middleware {
+	return Middleware{
+		Start: func(next StartFunc) StartFunc {
+			return func(ctx context.Context) error {
+				LoggerFrom(ctx).Info("Starting service")
+
+				err := next(ctx)
+				if err != nil {
+					LoggerFrom(ctx).Error("Service failed", "error", err)
+				}
+
+				return err
+			}
+		},
+		Stop: func(next StopFunc) StopFunc {
+			return func(ctx context.Context) {
+				LoggerFrom(ctx).Info("Stopping service")
+				next(ctx)
+				LoggerFrom(ctx).Info("Service stopped")
+			}
+		},
+	}
+}
+
+// TimeoutMiddleware cancels the context given to each StartFunc and StopFunc
+// after d. Only use it on services whose StartFunc returns once started;
+// cancelling a long-running StartFunc's context stops the service.
+func TimeoutMiddleware(d time.Duration) Middleware {
+	return Middleware{
+		Start: func(next StartFunc) StartFunc {
+			return func(ctx context.Context) error {
+				ctx, cancel := context.WithTimeout(ctx, d)
+				defer cancel()
+
+				return next(ctx)
+			}
+		},
+		Stop: func(next StopFunc) StopFunc {
+			return func(ctx context.Context) {
+				ctx, cancel := context.WithTimeout(ctx, d)
+				defer cancel()
+
+				next(ctx)
+			}
+		},
+	}
+}
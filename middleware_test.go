@@ -0,0 +1,154 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/controlstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tracing(name string, calls *[]string) controls.Middleware {
+	return controls.Middleware{
+		Start: func(next controls.StartFunc) controls.StartFunc {
+			return func(ctx context.Context) error {
+				*calls = append(*calls, name+" start")
+
+				return next(ctx)
+			}
+		},
+		Stop: func(next controls.StopFunc) controls.StopFunc {
+			return func(ctx context.Context) {
+				*calls = append(*calls, name+" stop")
+				next(ctx)
+			}
+		},
+	}
+}
+
+func TestController_WithServiceMiddleware(t *testing.T) {
+	var calls []string
+
+	errStop := errors.New("stop failed") //nolint:err113
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithServiceMiddleware(tracing("outer", &calls), tracing("inner", &calls)),
+	)
+	c.Register("db",
+		controls.WithStart(func(_ context.Context) error {
+			calls = append(calls, "start")
+
+			return nil
+		}),
+		controls.WithStopErr(func(_ context.Context) error {
+			calls = append(calls, "stop")
+
+			return errStop
+		}),
+	)
+
+	c.Start()
+	require.ErrorIs(t, c.Stop(), errStop)
+
+	assert.Equal(t, []string{"outer start", "inner start", "start", "outer stop", "inner stop", "stop"}, calls)
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	rec := &eventRecorder{}
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithEventHandler(rec.handle),
+		controls.WithServiceMiddleware(controls.RecoverMiddleware()),
+	)
+	c.Register("broken",
+		controls.WithStart(func(_ context.Context) error { panic("boom") }),
+		controls.WithStop(func(_ context.Context) { panic("boom") }),
+	)
+
+	c.Start()
+
+	assert.Eventually(t, func() bool { return len(rec.ofType(controls.ServiceFailed)) == 1 }, time.Second, time.Millisecond)
+	require.ErrorIs(t, rec.ofType(controls.ServiceFailed)[0].Err, controls.ErrPanic)
+
+	require.NoError(t, c.Stop())
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	var buf logBuffer
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+		controls.WithoutSignals(),
+		controls.WithServiceMiddleware(controls.LoggingMiddleware(), controls.TimingMiddleware()),
+	)
+	c.Register("db", controls.WithStart(noopStart), controls.WithStop(func(_ context.Context) {}))
+
+	c.Start()
+	require.NoError(t, c.Stop())
+	require.NoError(t, c.Close())
+
+	out := buf.String()
+	assert.Contains(t, out, `msg="Starting service" service=db`)
+	assert.Contains(t, out, `msg="Start returned" service=db duration=`)
+	assert.Contains(t, out, `msg="Service stopped" service=db`)
+}
+
+func TestTimingMiddleware_UsesClock(t *testing.T) {
+	var buf logBuffer
+
+	clock := controlstest.NewFakeClock(epoch)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+		controls.WithoutSignals(),
+		controls.WithClock(clock),
+		controls.WithServiceMiddleware(controls.TimingMiddleware()),
+	)
+	c.Register("db",
+		controls.WithStart(func(_ context.Context) error {
+			clock.Advance(3 * time.Second)
+
+			return nil
+		}),
+		controls.WithStop(func(_ context.Context) { clock.Advance(2 * time.Second) }),
+	)
+
+	c.Start()
+	require.NoError(t, c.Stop())
+	require.NoError(t, c.Close())
+
+	out := buf.String()
+	assert.Contains(t, out, `msg="Start returned" service=db duration=3s`)
+	assert.Contains(t, out, `msg="Stop returned" service=db duration=2s`)
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	stopped := make(chan error, 1)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithShutdownTimeout(time.Hour),
+		controls.WithServiceMiddleware(controls.TimeoutMiddleware(10*time.Millisecond)),
+	)
+	c.Register("slow",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(ctx context.Context) {
+			<-ctx.Done()
+			stopped <- ctx.Err()
+		}),
+	)
+
+	c.Start()
+	require.NoError(t, c.Stop())
+	require.ErrorIs(t, <-stopped, context.DeadlineExceeded)
+}
@@ -0,0 +1,199 @@
+// Package nats runs NATS subscriptions as a controls service, and carries
+// remote control commands over NATS. A *nats.Conn from
+// github.com/nats-io/nats.go satisfies Conn as it is, and needs only a few
+// lines to satisfy Subscriber.
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/phpboyscout/controls"
+)
+
+// DefaultSubject is the subject remote control commands are published on.
+const DefaultSubject = "controls"
+
+// drainPollInterval is how often Stop checks whether a draining connection
+// has closed.
+const drainPollInterval = 10 * time.Millisecond
+
+// ErrDisconnected is reported by the health check while the connection is
+// down, such as when it is reconnecting.
+var ErrDisconnected = errors.New("not connected to NATS")
+
+// Conn is a connection to NATS.
+type Conn interface {
+	// Drain unsubscribes every subscription, lets them process the messages
+	// already received and then closes the connection. It returns without
+	// waiting for that to finish.
+	Drain() error
+	IsConnected() bool
+	IsClosed() bool
+	Close()
+}
+
+// Service returns a service that connects with connect and then sets up its
+// subscriptions with subscribe. Stop drains the connection, waiting for the
+// subscriptions to finish the messages they hold within the stop timeout
+// before closing it outright. The health check fails while the connection is
+// down, and the connection's state is reported in the service's Details.
+func Service[C Conn](
+	name string, connect func(context.Context) (C, error), subscribe func(context.Context, C) error,
+) controls.Service {
+	s := &subscriber[C]{connect: connect, subscribe: subscribe}
+
+	return controls.Service{
+		Name:        name,
+		Start:       s.start,
+		StopErr:     s.stop,
+		HealthCheck: s.check,
+		Details:     s.details,
+	}
+}
+
+type subscriber[C Conn] struct {
+	connect   func(context.Context) (C, error)
+	subscribe func(context.Context, C) error
+
+	mu   sync.Mutex
+	conn Conn
+}
+
+func (s *subscriber[C]) start(ctx context.Context) error {
+	conn, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.subscribe(ctx, conn); err != nil {
+		conn.Close()
+
+		return err
+	}
+
+	s.mu.Lock()
+	s.conn = conn
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *subscriber[C]) stop(ctx context.Context) error {
+	s.mu.Lock()
+	conn := s.conn
+	s.conn = nil
+	s.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+
+	if err := conn.Drain(); err != nil {
+		conn.Close()
+
+		return err
+	}
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for !conn.IsClosed() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			conn.Close()
+
+			return fmt.Errorf("drain: %w", context.Cause(ctx))
+		}
+	}
+
+	return nil
+}
+
+func (s *subscriber[C]) check(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil && !s.conn.IsConnected() {
+		return ErrDisconnected
+	}
+
+	return nil
+}
+
+func (s *subscriber[C]) details() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return map[string]string{
+		"connected": strconv.FormatBool(s.conn != nil && s.conn.IsConnected()),
+	}
+}
+
+// Subscriber subscribes to NATS subjects. A *nats.Conn needs a few lines to
+// adapt:
+//
+//	func (s subscriber) Subscribe(subject string, handle func([]byte, func([]byte) error)) (func() error, error) {
+//		sub, err := s.nc.Subscribe(subject, func(m *nats.Msg) { handle(m.Data, m.Respond) })
+//		if err != nil {
+//			return nil, err
+//		}
+//
+//		return sub.Unsubscribe, nil
+//	}
+type Subscriber interface {
+	// Subscribe calls handle with each message published on subject, and a
+	// function replying to it, until unsubscribe is called.
+	Subscribe(subject string, handle func(data []byte, respond func([]byte) error)) (unsubscribe func() error, err error)
+}
+
+// Transport receives remote control commands as NATS requests, for use with
+// controls.WithRemoteControl. Each message on the subject is a
+// controls.RemoteRequest encoded as JSON, and the controls.RemoteReply is
+// sent back as JSON to the message's reply subject. Publishing a request to a
+// subject a fleet of daemons subscribes to gathers a reply from each.
+type Transport struct {
+	sub     Subscriber
+	subject string
+}
+
+// NewTransport returns a Transport for subject, DefaultSubject when empty.
+func NewTransport(sub Subscriber, subject string) *Transport {
+	if subject == "" {
+		subject = DefaultSubject
+	}
+
+	return &Transport{sub: sub, subject: subject}
+}
+
+// Serve subscribes to the subject and handles requests until ctx is done.
+// Messages that aren't valid requests, and replies that can't be sent, such
+// as to a message with no reply subject, are ignored.
+func (t *Transport) Serve(ctx context.Context, handle controls.RemoteHandler) error {
+	unsubscribe, err := t.sub.Subscribe(t.subject, func(data []byte, respond func([]byte) error) {
+		var req controls.RemoteRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			return
+		}
+
+		reply, err := json.Marshal(handle(ctx, req))
+		if err != nil {
+			return
+		}
+
+		_ = respond(reply)
+	})
+	if err != nil {
+		return err
+	}
+
+	<-ctx.Done()
+
+	return unsubscribe()
+}
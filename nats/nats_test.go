@@ -0,0 +1,207 @@
+package nats_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/nats"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conn is a fake NATS connection. Draining closes it once drained is closed.
+type conn struct {
+	connected   atomic.Bool
+	closed      atomic.Bool
+	drained     chan struct{}
+	subscribed  atomic.Int64
+	forceClosed atomic.Bool
+}
+
+func newConn() *conn {
+	c := &conn{drained: make(chan struct{})}
+	c.connected.Store(true)
+
+	return c
+}
+
+func (c *conn) Drain() error {
+	go func() {
+		<-c.drained
+		c.closed.Store(true)
+	}()
+
+	return nil
+}
+
+func (c *conn) IsConnected() bool { return c.connected.Load() && !c.closed.Load() }
+
+func (c *conn) IsClosed() bool { return c.closed.Load() }
+
+func (c *conn) Close() {
+	c.forceClosed.Store(true)
+	c.closed.Store(true)
+}
+
+func (c *conn) Subscribe(_ string) {
+	c.subscribed.Add(1)
+}
+
+func service(nc *conn, subscribe func(context.Context, *conn) error) controls.Service {
+	return nats.Service("events", func(_ context.Context) (*conn, error) {
+		return nc, nil
+	}, subscribe)
+}
+
+func TestService(t *testing.T) {
+	nc := newConn()
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(slog.New(slog.DiscardHandler)),
+		controls.WithoutSignals(),
+		controls.WithServices(service(nc, func(_ context.Context, nc *conn) error {
+			nc.Subscribe("orders.created")
+			nc.Subscribe("orders.cancelled")
+
+			return nil
+		})),
+	)
+
+	require.NoError(t, c.Start())
+	assert.Equal(t, int64(2), nc.subscribed.Load())
+	assert.Equal(t, "true", c.Report().Services[0].Details["connected"])
+
+	_, err := c.Status(context.Background())
+	require.NoError(t, err)
+
+	nc.connected.Store(false)
+
+	_, err = c.Status(context.Background())
+	require.ErrorIs(t, err, nats.ErrDisconnected)
+
+	close(nc.drained)
+
+	require.NoError(t, c.Stop())
+	assert.True(t, nc.IsClosed())
+	assert.False(t, nc.forceClosed.Load())
+}
+
+func TestService_DrainTimeout(t *testing.T) {
+	nc := newConn()
+	defer close(nc.drained)
+
+	svc := service(nc, func(_ context.Context, _ *conn) error { return nil })
+
+	require.NoError(t, svc.Start(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := svc.StopErr(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, nc.forceClosed.Load())
+}
+
+func TestService_SubscribeFailure(t *testing.T) {
+	errSubscribe := errors.New("permission denied") //nolint:err113
+
+	nc := newConn()
+	svc := service(nc, func(_ context.Context, _ *conn) error { return errSubscribe })
+
+	require.ErrorIs(t, svc.Start(context.Background()), errSubscribe)
+	assert.True(t, nc.forceClosed.Load())
+	require.NoError(t, svc.StopErr(context.Background()))
+}
+
+// bus is a fake NATS server for request and reply.
+type bus struct {
+	mu       sync.Mutex
+	handlers map[string]func([]byte, func([]byte) error)
+}
+
+func (b *bus) Subscribe(subject string, handle func([]byte, func([]byte) error)) (func() error, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[subject] = handle
+
+	return func() error {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.handlers, subject)
+
+		return nil
+	}, nil
+}
+
+func (b *bus) subscribed(subject string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.handlers[subject] != nil
+}
+
+// request publishes data on subject and returns the reply.
+func (b *bus) request(subject, data string) (string, bool) {
+	b.mu.Lock()
+	handle := b.handlers[subject]
+	b.mu.Unlock()
+
+	if handle == nil {
+		return "", false
+	}
+
+	var reply string
+
+	handle([]byte(data), func(r []byte) error {
+		reply = string(r)
+
+		return nil
+	})
+
+	return reply, true
+}
+
+func TestTransport(t *testing.T) {
+	b := &bus{handlers: map[string]func([]byte, func([]byte) error){}}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(slog.New(slog.DiscardHandler)),
+		controls.WithoutSignals(),
+		controls.WithName("worker-1"),
+		controls.WithRemoteControl(nats.NewTransport(b, "")),
+		controls.WithService("api", controls.WithStart(func(_ context.Context) error { return nil })),
+	)
+
+	require.NoError(t, c.Start())
+	require.Eventually(t, func() bool { return b.subscribed(nats.DefaultSubject) }, time.Second, 5*time.Millisecond)
+
+	data, ok := b.request(nats.DefaultSubject, `{"id":"1","command":"status"}`)
+	require.True(t, ok)
+
+	var reply struct {
+		ID         string `json:"id"`
+		Controller string `json:"controller"`
+		Status     string `json:"status"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(data), &reply))
+	assert.Equal(t, "1", reply.ID)
+	assert.Equal(t, "worker-1", reply.Controller)
+	assert.Equal(t, "running", reply.Status)
+
+	_, ok = b.request(nats.DefaultSubject, `not json`)
+	require.True(t, ok)
+
+	require.NoError(t, c.Stop())
+	assert.Eventually(t, func() bool { return !b.subscribed(nats.DefaultSubject) }, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,113 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrAwaitFailed is returned when a service gated with WithAwait can't start
+// because a one-shot service it waits for failed or was never launched.
+var ErrAwaitFailed = errors.New("awaited service did not complete")
+
+// errHalted reports that a service was stopped while waiting to start.
+var errHalted = errors.New("stopped while awaiting")
+
+// WithOneShot marks the service as running to completion, like a migration or
+// cache warm-up. Once its StartFunc returns nil it is recorded as Completed and
+// no longer counts as running; it needs no stop function.
+func WithOneShot() ServiceOption {
+	return func(s *Service) {
+		s.OneShot = true
+	}
+}
+
+// WithAwait holds the service back until the named one-shot services have
+// completed. If one of them fails, or isn't launched alongside it, the service
+// fails with ErrAwaitFailed instead of starting.
+func WithAwait(names ...string) ServiceOption {
+	return func(s *Service) {
+		s.Await = append(s.Await, names...)
+	}
+}
+
+// await blocks until every service s waits on has completed, failing if one
+// didn't or ctx ends first, and returns errHalted if s itself was stopped in
+// the meantime. The services are looked up when it is called, so a service
+// registered after s that is launched alongside it is still found.
+func (q *Services) await(ctx context.Context, s *service) error {
+	for _, name := range s.Await {
+		q.mu.Lock()
+		dep, err := q.lookup(name)
+		if err == nil && (!dep.OneShot || dep.settled == nil) {
+			err = fmt.Errorf("%w: %s is not a launched one-shot service", ErrAwaitFailed, name)
+		}
+
+		var settled chan struct{}
+		if dep != nil {
+			settled = dep.settled
+		}
+		q.mu.Unlock()
+
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-settled:
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+
+		q.mu.Lock()
+		state := dep.state
+		q.mu.Unlock()
+
+		if state != Completed {
+			return fmt.Errorf("%w: %s is %s", ErrAwaitFailed, name, state)
+		}
+	}
+
+	if len(s.Await) == 0 {
+		return nil
+	}
+
+	return q.stillRunning(s)
+}
+
+// stillRunning returns errHalted if s was stopped while waiting to start.
+func (q *Services) stillRunning(s *service) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if s.state != Running {
+		return errHalted
+	}
+
+	return nil
+}
+
+// complete records a one-shot service as finished, unless it was stopped
+// while running.
+func (q *Services) complete(s *service) {
+	q.mu.Lock()
+	running := s.state == Running
+	if running {
+		q.setState(s, Completed)
+		s.stoppedAt = q.clock()
+		s.upTotal += s.stoppedAt.Sub(s.startedAt)
+	}
+	q.mu.Unlock()
+
+	if running && q.completed != nil {
+		q.completed(s.Name)
+	}
+
+	q.notify()
+}
+
+func (c *Controller) serviceCompleted(name string) {
+	c.log().Info("Service completed", "service", name)
+	c.releaseWaitGroup(1)
+	c.emit(Event{Type: ServiceCompleted, Service: name, State: Completed})
+}
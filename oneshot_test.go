@@ -0,0 +1,109 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func states(c *controls.Controller) map[string]controls.State {
+	states := map[string]controls.State{}
+	for _, s := range c.Report().Services {
+		states[s.Name] = s.State
+	}
+
+	return states
+}
+
+func TestController_OneShot(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+
+	record := func(name string) controls.StartFunc {
+		return func(_ context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			order = append(order, name)
+
+			return nil
+		}
+	}
+
+	rec := &eventRecorder{}
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithEventHandler(rec.handle),
+	)
+	c.Register("api", controls.WithStart(record("api")), controls.WithStop(func(_ context.Context) {}),
+		controls.WithAwait("migrate", "warm"))
+	c.Register("migrate", controls.WithStart(record("migrate")), controls.WithOneShot())
+	c.Register("warm", controls.WithStart(record("warm")), controls.WithOneShot())
+
+	c.Start()
+
+	assert.ElementsMatch(t, []string{"migrate", "warm"}, order[:2])
+	assert.Equal(t, "api", order[2])
+	assert.Equal(t, map[string]controls.State{
+		"api": controls.Running, "migrate": controls.Completed, "warm": controls.Completed,
+	}, states(c))
+	assert.Len(t, rec.ofType(controls.ServiceCompleted), 2)
+
+	require.NoError(t, c.Stop())
+
+	waited := make(chan struct{})
+	go func() {
+		c.Wait()
+		close(waited)
+	}()
+
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return once the controller stopped")
+	}
+
+	assert.Equal(t, controls.Completed, states(c)["migrate"])
+}
+
+func TestController_OneShotFailure(t *testing.T) {
+	errMigration := errors.New("migration failed")
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("migrate", controls.WithStart(func(_ context.Context) error { return errMigration }),
+		controls.WithOneShot())
+	c.Register("api", controls.WithStart(noopStart), controls.WithStop(func(_ context.Context) {}),
+		controls.WithAwait("migrate"))
+	c.Register("worker", controls.WithStart(noopStart), controls.WithStop(func(_ context.Context) {}),
+		controls.WithAwait("api"))
+
+	c.Start()
+
+	require.Eventually(t, func() bool {
+		s := states(c)
+
+		return s["api"] == controls.Failed && s["worker"] == controls.Failed
+	}, time.Second, time.Millisecond)
+
+	report := c.Report()
+	assert.ErrorIs(t, report.Services[0].LastError, errMigration)
+	assert.ErrorIs(t, report.Services[1].LastError, controls.ErrAwaitFailed)
+	assert.ErrorIs(t, report.Services[2].LastError, controls.ErrAwaitFailed)
+
+	require.NoError(t, c.Stop())
+}
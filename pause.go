@@ -0,0 +1,150 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// PauseFunc suspends a running service in place, such as to stop taking new
+// work while keeping its connections and caches, and ResumeFunc picks up
+// where it left off.
+type (
+	PauseFunc  func(ctx context.Context) error
+	ResumeFunc func(ctx context.Context) error
+)
+
+// WithPause sets the function called when a feature flag or maintenance
+// pauses the service. It is bounded by the service's stop timeout. A service
+// without one, or whose PauseFunc fails, is stopped instead and started again
+// when it resumes.
+func WithPause(fn PauseFunc) ServiceOption {
+	return func(s *Service) {
+		s.Pause = fn
+	}
+}
+
+// WithResume sets the function called to resume a service its PauseFunc
+// paused. It is bounded by the service's start timeout. A service whose
+// ResumeFunc fails is marked failed.
+func WithResume(fn ResumeFunc) ServiceOption {
+	return func(s *Service) {
+		s.Resume = fn
+	}
+}
+
+// pauseMatching pauses the running services matching selector in dependency
+// order. It returns the names of those it had to stop and those it paused in
+// place, and the joined errors.
+func (q *Services) pauseMatching(ctx context.Context, selector map[string]string) ([]string, []string, error) {
+	q.ops.Lock()
+	defer q.ops.Unlock()
+
+	var (
+		stopped, paused []string
+		errs            []error
+	)
+
+	matched := q.matching(func(s *service) bool {
+		return s.launched() && matchLabels(s.Labels, selector)
+	})
+
+	for _, wave := range stopWaves(matched) {
+		for _, s := range wave {
+			halted, err := q.pause(ctx, s)
+			if halted {
+				stopped = append(stopped, s.Name)
+			} else {
+				paused = append(paused, s.Name)
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	return stopped, paused, errors.Join(errs...)
+}
+
+// live reports whether the service has been started and not yet stopped,
+// including while its PauseFunc has it paused.
+func (s *service) live() bool {
+	return s.launched() || s.suspended
+}
+
+// pause pauses the launched service s with its PauseFunc, or stops it when it
+// has none, has failed or the PauseFunc fails, and reports whether it was
+// stopped. It must be called with q.ops held and q.mu not held.
+func (q *Services) pause(ctx context.Context, s *service) (bool, error) {
+	defer q.transition(s, Paused)
+
+	if s.Pause == nil || !q.is(s, func(s *service) bool { return s.state == Running }) {
+		return true, q.halt(ctx, s)
+	}
+
+	pauseCtx, cancel := q.stopWithin(ctx, s)
+	err := s.Pause(q.scoped(pauseCtx, s))
+
+	cancel()
+
+	if err == nil {
+		q.mu.Lock()
+		s.suspended = true
+		q.mu.Unlock()
+
+		return false, nil
+	}
+
+	err = fmt.Errorf("%s: pause: %w", s.Name, err)
+
+	q.mu.Lock()
+	s.lastErr = err
+	q.mu.Unlock()
+
+	return true, errors.Join(err, q.halt(ctx, s))
+}
+
+// unpause resumes the paused service s: with its ResumeFunc if its PauseFunc
+// paused it, otherwise by launching it again and calling done once it is
+// ready. It reports whether s was launched. It must be called with q.ops held
+// and q.mu not held.
+func (q *Services) unpause(ctx context.Context, s *service, done func()) (bool, error) {
+	q.mu.Lock()
+
+	if !s.suspended {
+		q.launch(ctx, s, done)
+		q.mu.Unlock()
+
+		return true, nil
+	}
+
+	q.setState(s, Running)
+	s.suspended = false
+	q.mu.Unlock()
+
+	defer done()
+
+	if s.Resume == nil {
+		return false, nil
+	}
+
+	d := s.StartTimeout
+	if d <= 0 {
+		d = q.startTimeout
+	}
+
+	if d > 0 && q.withTimeout != nil {
+		var cancel context.CancelFunc
+
+		ctx, cancel = q.withTimeout(ctx, d)
+		defer cancel()
+	}
+
+	if err := s.Resume(q.scoped(ctx, s)); err != nil {
+		err = fmt.Errorf("%s: resume: %w", s.Name, err)
+		q.failed(s, err)
+
+		return false, err
+	}
+
+	return false, nil
+}
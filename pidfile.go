@@ -0,0 +1,72 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ErrPIDFileInUse is returned when a pid file names a process that is still
+// running.
+var ErrPIDFileInUse = errors.New("pid file in use")
+
+// PIDFileService returns a service called "pidfile" that writes the process's
+// pid to path on start and removes the file on stop. A file left behind by a
+// process that is no longer running is replaced, but one naming a live
+// process fails the start with ErrPIDFileInUse. opts are applied after the
+// service's own.
+func PIDFileService(path string, opts ...ServiceOption) Service {
+	p := pidFile(path)
+
+	return newService("pidfile", append([]ServiceOption{WithStart(p.write), WithStopErr(p.remove)}, opts...)...)
+}
+
+type pidFile string
+
+func (p pidFile) write(_ context.Context) error {
+	if pid, ok := p.read(); ok && pid != os.Getpid() && processAlive(pid) {
+		return fmt.Errorf("%w: %s: process %d is running", ErrPIDFileInUse, p, pid)
+	}
+
+	return os.WriteFile(string(p), []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644) //nolint:gosec // pid files are world readable
+}
+
+// remove deletes the file unless another process has since taken it over.
+func (p pidFile) remove(_ context.Context) error {
+	if pid, ok := p.read(); ok && pid != os.Getpid() {
+		return nil
+	}
+
+	if err := os.Remove(string(p)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (p pidFile) read() (int, bool) {
+	b, err := os.ReadFile(string(p))
+	if err != nil {
+		return 0, false
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+
+	return pid, err == nil && pid > 0
+}
+
+// processAlive reports whether a process with the pid exists.
+func processAlive(pid int) bool {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	err = proc.Signal(syscall.Signal(0))
+
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
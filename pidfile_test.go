@@ -0,0 +1,58 @@
+package controls_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPIDFileService(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.pid")
+
+	// A pid that can't belong to a running process.
+	require.NoError(t, os.WriteFile(path, []byte("999999999\n"), 0o600))
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithServices(controls.PIDFileService(path)),
+	)
+
+	c.Start()
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err, "a stale pid file is replaced")
+	assert.Equal(t, strconv.Itoa(os.Getpid())+"\n", string(b))
+
+	require.NoError(t, c.Stop())
+	assert.NoFileExists(t, path)
+}
+
+func TestPIDFileService_InUse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.pid")
+
+	// The parent of the test process is still running.
+	require.NoError(t, os.WriteFile(path, []byte(strconv.Itoa(os.Getppid())), 0o600))
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithServices(controls.PIDFileService(path)),
+	)
+
+	c.Start()
+	defer c.Stop()
+
+	services := c.Services()
+	require.Len(t, services, 1)
+	assert.Equal(t, controls.Failed, services[0].State)
+	require.ErrorIs(t, services[0].LastError, controls.ErrPIDFileInUse)
+	assert.FileExists(t, path, "another process's pid file is left alone")
+}
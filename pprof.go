@@ -0,0 +1,34 @@
+package controls
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// PprofServiceName is the name WithPprof registers the pprof service under.
+const PprofServiceName = "pprof"
+
+const pprofReadHeaderTimeout = 5 * time.Second
+
+// PprofService returns the options for a service serving the net/http/pprof
+// handlers under /debug/pprof/ on addr. It is wired like HTTPService, but
+// builds a new server on every start so it can be restarted.
+func PprofService(addr string) []ServiceOption {
+	return newHTTPServer(func() *http.Server {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+		return &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: pprofReadHeaderTimeout}
+	}, true).serviceOptions()
+}
+
+// WithPprof registers PprofService(addr) as the "pprof" service, as
+// WithService does.
+func WithPprof(addr string) ControllerOpt {
+	return WithService(PprofServiceName, PprofService(addr)...)
+}
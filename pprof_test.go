@@ -0,0 +1,42 @@
+package controls_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_WithPprof(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithPprof("127.0.0.1:0"),
+	)
+
+	c.Start()
+
+	report := c.Report()
+	require.Len(t, report.Services, 1)
+	assert.Equal(t, controls.PprofServiceName, report.Services[0].Name)
+
+	addr := report.Services[0].Details["addr"]
+	require.NotEmpty(t, addr)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+addr+"/debug/pprof/cmdline", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, c.Stop())
+
+	_, err = http.DefaultClient.Do(req) //nolint:bodyclose
+	assert.Error(t, err)
+}
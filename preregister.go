@@ -0,0 +1,27 @@
+package controls
+
+// WithService registers a service as the controller is built, so a fully
+// configured controller can be constructed in one expression. Services are
+// registered once every option has been applied, so WithServiceMiddleware
+// covers them wherever it appears.
+func WithService(name string, opts ...ServiceOption) ControllerOpt {
+	return func(c Controllable) {
+		if ctrl, ok := c.(*Controller); ok {
+			ctrl.preregistered = append(ctrl.preregistered, newService(name, opts...))
+
+			return
+		}
+
+		c.Register(name, opts...)
+	}
+}
+
+// WithServices registers already built services as the controller is built,
+// as WithService does.
+func WithServices(services ...Service) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.preregistered = append(ctrl.preregistered, services...)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package controls_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_WithService(t *testing.T) {
+	var wrapped atomic.Int64
+
+	trace := controls.Middleware{
+		Start: func(next controls.StartFunc) controls.StartFunc {
+			return func(ctx context.Context) error {
+				wrapped.Add(1)
+
+				return next(ctx)
+			}
+		},
+	}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithService("api",
+			controls.WithStart(noopStart),
+			controls.WithStop(func(_ context.Context) {}),
+		),
+		controls.WithServices(controls.Service{
+			Name:  "db",
+			Start: noopStart,
+			Stop:  func(_ context.Context) {},
+		}),
+		controls.WithServiceMiddleware(trace),
+	)
+
+	services := c.Services()
+	require.Len(t, services, 2)
+	assert.Equal(t, "api", services[0].Name)
+	assert.Equal(t, "db", services[1].Name)
+
+	c.Start()
+	assert.Equal(t, 2, c.RunningCount())
+	assert.Equal(t, int64(2), wrapped.Load(), "middleware applies wherever it appears")
+
+	require.NoError(t, c.Stop())
+}
+
+func TestController_RegisterAll(t *testing.T) {
+	names := func(c *controls.Controller) []string {
+		var names []string
+		for s := range c.All() {
+			names = append(names, s.Name)
+		}
+
+		return names
+	}
+
+	newController := func() *controls.Controller {
+		c := controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+		)
+		c.Register("api", controls.WithStart(noopStart))
+
+		return c
+	}
+
+	t.Run("registers every service", func(t *testing.T) {
+		c := newController()
+
+		require.NoError(t, c.RegisterAll(
+			controls.Service{Name: "db", Start: noopStart},
+			controls.Service{Name: "cache", Start: noopStart},
+		))
+
+		assert.Equal(t, []string{"api", "db", "cache"}, names(c))
+
+		require.NoError(t, c.Start())
+		defer c.Stop()
+
+		assert.True(t, c.IsRunning())
+	})
+
+	t.Run("registers nothing when a service is invalid", func(t *testing.T) {
+		c := newController()
+
+		err := c.RegisterAll(
+			controls.Service{Name: "db", Start: noopStart},
+			controls.Service{Name: "api", Start: noopStart},
+			controls.Service{Name: "db", Start: noopStart},
+			controls.Service{Name: "worker"},
+			controls.Service{Start: noopStart},
+		)
+
+		require.ErrorIs(t, err, controls.ErrServiceExists)
+		require.ErrorIs(t, err, controls.ErrMissingStart)
+		require.ErrorIs(t, err, controls.ErrUnnamedService)
+		assert.Contains(t, err.Error(), "service already registered: api")
+		assert.Contains(t, err.Error(), "service already registered: db")
+		assert.Contains(t, err.Error(), "service has no start function: worker")
+		assert.Contains(t, err.Error(), "service 4: service has no name")
+
+		assert.Equal(t, []string{"api"}, names(c))
+	})
+}
+
+func TestController_RegisterDuplicate(t *testing.T) {
+	t.Run("register", func(t *testing.T) {
+		c := controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+		)
+
+		require.NotNil(t, c.Register("api", controls.WithStart(noopStart)))
+		assert.Nil(t, c.Register("api", controls.WithStart(noopStart)))
+
+		err := c.Start()
+		require.ErrorIs(t, err, controls.ErrServiceExists)
+		assert.EqualError(t, err, "service already registered: api")
+		assert.Equal(t, controls.Unknown, c.GetState())
+		assert.Len(t, c.Services(), 1)
+	})
+
+	t.Run("while building", func(t *testing.T) {
+		c := controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+			controls.WithService("db", controls.WithStart(noopStart)),
+			controls.WithServices(controls.Service{Name: "db", Start: noopStart}),
+		)
+
+		require.ErrorIs(t, c.Start(), controls.ErrServiceExists)
+	})
+}
@@ -0,0 +1,134 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultLivenessTimeout bounds how long the liveness probe waits for the
+// control loop to respond.
+const DefaultLivenessTimeout = time.Second
+
+var (
+	// ErrUnresponsive is reported by the liveness probe when the control loop
+	// doesn't respond in time.
+	ErrUnresponsive = errors.New("control loop unresponsive")
+	// ErrNotReady is reported by the readiness probe.
+	ErrNotReady = errors.New("not ready")
+	// ErrStartingUp is reported by the startup probe until Start has
+	// finished.
+	ErrStartingUp = errors.New("still starting")
+)
+
+// CheckLive is the liveness probe: it fails only when the control loop of a
+// running controller doesn't respond before ctx is done, a sign the process
+// is wedged and should be restarted. A controller that hasn't started or is
+// shutting down is live.
+func (c *Controller) CheckLive(ctx context.Context) error {
+	if !c.isUp() {
+		return nil
+	}
+
+	select {
+	case c.pings <- struct{}{}:
+		return nil
+	case <-c.shutdown.done:
+		return nil
+	case <-ctx.Done():
+		if !c.isUp() {
+			return nil
+		}
+
+		return fmt.Errorf("%w: %w", ErrUnresponsive, context.Cause(ctx))
+	}
+}
+
+// CheckReady is the readiness probe: it passes while the controller is
+// Running, not in maintenance, and every launched service is running, ready
+// and not reporting degraded or unhealthy health. The reasons it fails are
+// joined.
+func (c *Controller) CheckReady() error {
+	if c.InMaintenance() {
+		return fmt.Errorf("%w: %w", ErrNotReady, ErrMaintenance)
+	}
+
+	if state := c.GetState(); state != Running {
+		return fmt.Errorf("%w: controller is %s", ErrNotReady, state)
+	}
+
+	return c.services.readiness()
+}
+
+// CheckStarted is the startup probe: it fails until Start has finished.
+func (c *Controller) CheckStarted() error {
+	switch state := c.GetState(); state {
+	case Unknown, Starting:
+		return fmt.Errorf("%w: controller is %s", ErrStartingUp, state)
+	default:
+		return nil
+	}
+}
+
+func (q *Services) readiness() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var errs []error
+
+	for _, s := range q.services {
+		switch {
+		case !s.launched():
+		case s.state != Running:
+			errs = append(errs, fmt.Errorf("%w: %s is %s", ErrNotReady, s.Name, s.state))
+		case !s.ready:
+			errs = append(errs, fmt.Errorf("%w: %s is not ready", ErrNotReady, s.Name))
+		case s.stalled:
+			errs = append(errs, fmt.Errorf("%w: %s is stalled", ErrNotReady, s.Name))
+		case s.lastHealth != nil && (s.lastHealth.Status == HealthDegraded || s.lastHealth.Status == Unhealthy):
+			errs = append(errs, fmt.Errorf("%w: %s is %s", ErrNotReady, s.Name, s.lastHealth.Status))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// LivezHandler serves the liveness probe, giving the control loop
+// DefaultLivenessTimeout to respond.
+func (c *Controller) LivezHandler() http.Handler {
+	return probeHandler(func(r *http.Request) error {
+		ctx, cancel := c.withTimeout(r.Context(), DefaultLivenessTimeout)
+		defer cancel()
+
+		return c.CheckLive(ctx)
+	})
+}
+
+// ReadyzHandler serves the readiness probe.
+func (c *Controller) ReadyzHandler() http.Handler {
+	return probeHandler(func(*http.Request) error { return c.CheckReady() })
+}
+
+// StartupzHandler serves the startup probe.
+func (c *Controller) StartupzHandler() http.Handler {
+	return probeHandler(func(*http.Request) error { return c.CheckStarted() })
+}
+
+// probeHandler answers "ok" when check passes, and otherwise 503 with the
+// reasons it failed.
+func probeHandler(check func(*http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		if err := check(r); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = fmt.Fprintln(w, err)
+
+			return
+		}
+
+		_, _ = fmt.Fprintln(w, "ok")
+	})
+}
@@ -0,0 +1,109 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_Probes(t *testing.T) {
+	healthy := make(chan bool, 1)
+	healthy <- true
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("db",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithHealthCheck(func(_ context.Context) error {
+			if ok := <-healthy; !ok {
+				return errors.New("replica lag") //nolint:err113
+			}
+
+			return nil
+		}),
+	)
+
+	h := c.AdminHandler()
+
+	probe := func(path string) (int, string) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequestWithContext(context.Background(), http.MethodGet, path, nil))
+
+		return rec.Code, rec.Body.String()
+	}
+
+	code, _ := probe("/livez")
+	assert.Equal(t, http.StatusOK, code, "live before starting")
+
+	code, body := probe("/startupz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "still starting: controller is unknown\n", body)
+
+	require.ErrorIs(t, c.CheckReady(), controls.ErrNotReady)
+
+	c.Start()
+	defer c.Stop()
+
+	for _, path := range []string{"/livez", "/readyz", "/startupz"} {
+		code, body = probe(path)
+		assert.Equal(t, http.StatusOK, code, path)
+		assert.Equal(t, "ok\n", body, path)
+	}
+
+	_, err := c.Status(context.Background())
+	require.NoError(t, err)
+
+	healthy <- false
+
+	_, err = c.Status(context.Background())
+	require.Error(t, err)
+
+	code, body = probe("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not ready: controller is degraded\n", body)
+
+	code, _ = probe("/livez")
+	assert.Equal(t, http.StatusOK, code, "an unhealthy service doesn't fail liveness")
+}
+
+func TestController_CheckLiveUnresponsive(t *testing.T) {
+	block := make(chan struct{})
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("stuck",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithMessageHandler(func(controls.Message) error {
+			<-block
+
+			return nil
+		}),
+	)
+
+	c.Start()
+	defer c.Stop()
+	defer close(block)
+
+	require.NoError(t, c.CheckLive(context.Background()))
+
+	c.Messages() <- "wedge"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, c.CheckLive(ctx), controls.ErrUnresponsive)
+}
@@ -0,0 +1,57 @@
+package controls
+
+import (
+	"context"
+	"time"
+)
+
+type readyKey struct{}
+
+// Ready signals that the service whose StartFunc was given ctx is ready. A
+// StartFunc that blocks for the life of the service, such as one serving
+// requests, should call it once it can do useful work; for other services
+// returning nil from the StartFunc counts as ready. Calls after the first, or
+// with any other context, do nothing.
+func Ready(ctx context.Context) {
+	if ready, ok := ctx.Value(readyKey{}).(func()); ok {
+		ready()
+	}
+}
+
+// WithReadyTimeout bounds how long Start waits for services to become ready
+// before moving to Running anyway. By default Start waits indefinitely.
+func WithReadyTimeout(d time.Duration) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.readyTimeout = d
+		})
+	}
+}
+
+// startServices launches the services and waits for them to become ready,
+// giving up after the ready timeout.
+func (c *Controller) startServices() {
+	var timeout <-chan struct{}
+
+	if c.readyTimeout > 0 {
+		ctx, cancel := c.withTimeout(context.Background(), c.readyTimeout)
+		defer cancel()
+
+		timeout = ctx.Done()
+	}
+
+	if !c.services.start(c.runCtx, timeout) {
+		c.log().Warn("Services not ready before timeout", "timeout", c.readyTimeout)
+	}
+}
+
+func (q *Services) markReady(s *service) {
+	q.mu.Lock()
+	if s.state == Running {
+		s.ready = true
+		s.startDuration = q.clock().Sub(s.startedAt)
+	}
+	q.mu.Unlock()
+
+	q.notify()
+}
@@ -0,0 +1,124 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blocking returns a StartFunc that runs until its stop function is called,
+// signalling readiness first when ready is set.
+func blocking(ready bool) (controls.StartFunc, controls.StopFunc) {
+	quit := make(chan struct{})
+
+	start := func(ctx context.Context) error {
+		if ready {
+			controls.Ready(ctx)
+		}
+
+		<-quit
+
+		return nil
+	}
+
+	return start, func(_ context.Context) { close(quit) }
+}
+
+func TestController_Ready(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+
+	start, stop := blocking(true)
+	c.Register("server", controls.WithStart(start), controls.WithStop(stop))
+	c.Register("plain", controls.WithStart(noopStart), controls.WithStop(func(_ context.Context) {}))
+
+	c.Start()
+
+	assert.True(t, c.IsRunning())
+
+	for _, s := range c.Report().Services {
+		assert.True(t, s.Ready, s.Name)
+	}
+
+	require.NoError(t, c.StopService("server"))
+	assert.False(t, c.Report().Services[0].Ready)
+
+	require.NoError(t, c.Stop())
+}
+
+func TestController_WithReadyTimeout(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithReadyTimeout(20*time.Millisecond),
+	)
+
+	start, stop := blocking(false)
+	c.Register("slow", controls.WithStart(start), controls.WithStop(stop))
+
+	began := time.Now()
+	c.Start()
+
+	assert.GreaterOrEqual(t, time.Since(began), 20*time.Millisecond)
+	assert.True(t, c.IsRunning())
+	assert.False(t, c.Report().Services[0].Ready)
+
+	require.NoError(t, c.Stop())
+}
+
+func TestController_WaitUntilRunning(t *testing.T) {
+	t.Run("services ready", func(t *testing.T) {
+		c := controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+		)
+
+		release := make(chan struct{})
+		_, stop := blocking(false)
+		c.Register("server",
+			controls.WithStart(func(ctx context.Context) error {
+				<-release
+				controls.Ready(ctx)
+
+				return nil
+			}),
+			controls.WithStop(stop),
+		)
+
+		go c.Start()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, c.WaitUntilRunning(ctx), context.DeadlineExceeded)
+
+		close(release)
+		require.NoError(t, c.WaitUntilRunning(context.Background()))
+		assert.True(t, c.Report().Services[0].Ready)
+
+		require.NoError(t, c.Stop())
+		require.ErrorIs(t, c.WaitUntilRunning(context.Background()), controls.ErrNotRunning)
+	})
+
+	t.Run("service failed", func(t *testing.T) {
+		errBind := errors.New("address in use")
+
+		c := controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+		)
+		c.Register("server", controls.WithStart(func(_ context.Context) error { return errBind }))
+
+		go c.Start()
+
+		require.ErrorIs(t, c.WaitUntilRunning(context.Background()), errBind)
+		require.NoError(t, c.Stop())
+	})
+}
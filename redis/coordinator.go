@@ -0,0 +1,113 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultKey prefixes the keys holding the turns to stop.
+	DefaultKey = "controls:shutdown"
+	// DefaultLease is how long a turn is held before it expires, in case its
+	// holder dies while stopping. It should outlast a shutdown.
+	DefaultLease = 10 * time.Minute
+	// DefaultPollInterval is how often a waiting Coordinator tries again for
+	// a turn.
+	DefaultPollInterval = time.Second
+)
+
+// releaseScript deletes a turn only while the caller still holds it, so a
+// lease that expired and was taken by another instance isn't given back.
+const releaseScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`
+
+// WithKey overrides DefaultKey, such as to coordinate each application
+// separately.
+func WithKey(key string) Option {
+	return func(c *config) {
+		c.key = key
+	}
+}
+
+// WithLease overrides DefaultLease.
+func WithLease(d time.Duration) Option {
+	return func(c *config) {
+		c.lease = d
+	}
+}
+
+// WithPollInterval overrides DefaultPollInterval.
+func WithPollInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.poll = d
+	}
+}
+
+// Coordinator is a controls.ShutdownCoordinator handing out turns to stop as
+// leases on the keys <key>:0 to <key>:<turns-1>, so at most turns instances
+// sharing the key stop at once.
+type Coordinator struct {
+	config
+
+	turns int
+}
+
+// NewCoordinator returns a Coordinator for the Redis server at addr,
+// DefaultAddr when empty, handing out turns turns at once, at least one.
+func NewCoordinator(addr string, turns int, opts ...Option) *Coordinator {
+	return &Coordinator{config: newConfig(addr, opts), turns: max(turns, 1)}
+}
+
+// Acquire polls for a free turn until it takes one or ctx is done.
+func (c *Coordinator) Acquire(ctx context.Context) (func(context.Context) error, error) {
+	rc, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer context.AfterFunc(ctx, func() { _ = rc.Close() })()
+	defer rc.Close()
+
+	token := rand.Text()
+	lease := strconv.FormatInt(c.lease.Milliseconds(), 10)
+
+	for {
+		for i := range c.turns {
+			key := c.key + ":" + strconv.Itoa(i)
+
+			reply, err := rc.do("SET", key, token, "NX", "PX", lease)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, context.Cause(ctx)
+				}
+
+				return nil, err
+			}
+
+			if reply == "OK" {
+				return func(ctx context.Context) error { return c.release(ctx, key, token) }, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		case <-time.After(c.poll):
+		}
+	}
+}
+
+func (c *Coordinator) release(ctx context.Context, key, token string) error {
+	rc, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer context.AfterFunc(ctx, func() { _ = rc.Close() })()
+	defer rc.Close()
+
+	_, err = rc.do("EVAL", releaseScript, "1", key, token)
+
+	return err
+}
@@ -0,0 +1,88 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoordinator(t *testing.T) {
+	b := newBroker(t, "s3cret")
+
+	newCoordinator := func() *redis.Coordinator {
+		return redis.NewCoordinator(b.addr(), 1,
+			redis.WithAuth("", "s3cret"),
+			redis.WithKey("app"),
+			redis.WithPollInterval(5*time.Millisecond),
+		)
+	}
+
+	release, err := newCoordinator().Acquire(context.Background())
+	require.NoError(t, err)
+
+	type result struct {
+		release func(context.Context) error
+		err     error
+	}
+
+	waiting := make(chan result, 1)
+
+	go func() {
+		release, err := newCoordinator().Acquire(context.Background())
+		waiting <- result{release, err}
+	}()
+
+	select {
+	case <-waiting:
+		require.FailNow(t, "took a turn while it was held")
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	require.NoError(t, release(context.Background()))
+
+	select {
+	case r := <-waiting:
+		require.NoError(t, r.err)
+		require.NoError(t, r.release(context.Background()))
+	case <-time.After(time.Second):
+		require.FailNow(t, "no turn after it was given back")
+	}
+
+	assert.Empty(t, b.keys)
+}
+
+func TestCoordinator_Turns(t *testing.T) {
+	b := newBroker(t, "")
+	c := redis.NewCoordinator(b.addr(), 2, redis.WithPollInterval(5*time.Millisecond))
+
+	_, err := c.Acquire(context.Background())
+	require.NoError(t, err)
+
+	_, err = c.Acquire(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	_, err = c.Acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestCoordinator_ReleaseExpired(t *testing.T) {
+	b := newBroker(t, "")
+	c := redis.NewCoordinator(b.addr(), 1)
+
+	release, err := c.Acquire(context.Background())
+	require.NoError(t, err)
+
+	// The lease expired and another instance took the turn.
+	b.keys[redis.DefaultKey+":0"] = "someone-else"
+
+	require.NoError(t, release(context.Background()))
+	assert.Equal(t, "someone-else", b.keys[redis.DefaultKey+":0"])
+}
@@ -0,0 +1,319 @@
+// Package redis carries remote control commands over Redis pub/sub, for use
+// with controls.WithRemoteControl, and coordinates rolling shutdowns, for use
+// with controls.WithShutdownCoordinator. It speaks the Redis protocol
+// directly, so it needs no client library.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phpboyscout/controls"
+)
+
+const (
+	// DefaultAddr is a local Redis server.
+	DefaultAddr = "127.0.0.1:6379"
+	// DefaultChannel is the channel commands are published on.
+	DefaultChannel = "controls"
+	// ReplySuffix is appended to the command channel to name the channel
+	// replies are published on when a request doesn't give its own.
+	ReplySuffix = ".replies"
+)
+
+var (
+	// ErrCommandFailed is returned when Redis answers a command with an
+	// error.
+	ErrCommandFailed = errors.New("redis command failed")
+	// ErrProtocol is returned when Redis sends something the transport
+	// doesn't understand.
+	ErrProtocol = errors.New("redis protocol error")
+)
+
+// Request is a controls.RemoteRequest as published on the command channel.
+// ReplyTo names the channel the reply is published on.
+type Request struct {
+	controls.RemoteRequest
+
+	ReplyTo string `json:"reply_to,omitempty"`
+}
+
+// Option configures a Transport or a Coordinator.
+type Option func(*config)
+
+// config is the connection and its use shared by a Transport and a
+// Coordinator.
+type config struct {
+	addr               string
+	username, password string
+	dialer             *net.Dialer
+
+	channel string
+
+	key   string
+	lease time.Duration
+	poll  time.Duration
+}
+
+func newConfig(addr string, opts []Option) config {
+	if addr == "" {
+		addr = DefaultAddr
+	}
+
+	c := config{
+		addr:    addr,
+		dialer:  &net.Dialer{},
+		channel: DefaultChannel,
+		key:     DefaultKey,
+		lease:   DefaultLease,
+		poll:    DefaultPollInterval,
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c
+}
+
+// WithChannel overrides DefaultChannel, such as to address one group of
+// daemons.
+func WithChannel(channel string) Option {
+	return func(c *config) {
+		c.channel = channel
+	}
+}
+
+// WithAuth authenticates with the server. An empty username authenticates
+// as the default user.
+func WithAuth(username, password string) Option {
+	return func(c *config) {
+		c.username, c.password = username, password
+	}
+}
+
+// WithDialer replaces the net.Dialer used to connect.
+func WithDialer(d *net.Dialer) Option {
+	return func(c *config) {
+		c.dialer = d
+	}
+}
+
+// Transport subscribes to a channel for Request messages encoded as JSON and
+// publishes each controls.RemoteReply, also as JSON, to the request's
+// ReplyTo channel or else the command channel with ReplySuffix appended.
+type Transport struct {
+	config
+
+	mu  sync.Mutex
+	pub *conn
+}
+
+// New returns a Transport for the Redis server at addr, DefaultAddr when
+// empty.
+func New(addr string, opts ...Option) *Transport {
+	return &Transport{config: newConfig(addr, opts)}
+}
+
+// Serve subscribes to the command channel and handles requests until ctx is
+// done or the connection fails.
+func (t *Transport) Serve(ctx context.Context, handle controls.RemoteHandler) error {
+	sub, err := t.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer context.AfterFunc(ctx, func() { _ = sub.Close() })()
+	defer sub.Close()
+	defer t.closePublisher()
+
+	if err := sub.send("SUBSCRIBE", t.channel); err != nil {
+		return err
+	}
+
+	for {
+		reply, err := sub.read()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		msg, ok := reply.([]any)
+		if !ok || len(msg) != 3 || msg[0] != "message" {
+			continue
+		}
+
+		payload, _ := msg[2].(string)
+
+		var req Request
+		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+			continue
+		}
+
+		replyTo := req.ReplyTo
+		if replyTo == "" {
+			replyTo = t.channel + ReplySuffix
+		}
+
+		body, err := json.Marshal(handle(ctx, req.RemoteRequest))
+		if err != nil {
+			return err
+		}
+
+		if err := t.publish(ctx, replyTo, body); err != nil {
+			return err
+		}
+	}
+}
+
+// publish sends payload on channel over the publishing connection, which is
+// separate because a subscribed connection can't publish.
+func (t *Transport) publish(ctx context.Context, channel string, payload []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.pub == nil {
+		pub, err := t.dial(ctx)
+		if err != nil {
+			return err
+		}
+
+		t.pub = pub
+	}
+
+	_, err := t.pub.do("PUBLISH", channel, string(payload))
+	if err != nil {
+		_ = t.pub.Close()
+		t.pub = nil
+	}
+
+	return err
+}
+
+func (t *Transport) closePublisher() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.pub != nil {
+		_ = t.pub.Close()
+		t.pub = nil
+	}
+}
+
+func (c *config) dial(ctx context.Context) (*conn, error) {
+	nc, err := c.dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	rc := &conn{Conn: nc, r: bufio.NewReader(nc)}
+
+	if c.password != "" {
+		args := []string{"AUTH", c.password}
+		if c.username != "" {
+			args = []string{"AUTH", c.username, c.password}
+		}
+
+		if _, err := rc.do(args...); err != nil {
+			_ = rc.Close()
+
+			return nil, fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	return rc, nil
+}
+
+// conn is a connection speaking RESP, the Redis protocol.
+type conn struct {
+	net.Conn
+
+	r *bufio.Reader
+}
+
+// do sends a command and reads its reply.
+func (c *conn) do(args ...string) (any, error) {
+	if err := c.send(args...); err != nil {
+		return nil, err
+	}
+
+	return c.read()
+}
+
+func (c *conn) send(args ...string) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	_, err := io.WriteString(c.Conn, b.String())
+
+	return err
+}
+
+// read returns the next reply: a string for simple and bulk strings, an
+// int64 for integers, nil for a null, a []any for arrays, or the server's
+// error.
+func (c *conn) read() (any, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, ErrProtocol
+	}
+
+	switch kind, rest := line[0], line[1:]; kind {
+	case '+':
+		return rest, nil
+	case '-':
+		return nil, fmt.Errorf("%w: %s", ErrCommandFailed, rest)
+	case ':':
+		return strconv.ParseInt(rest, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(rest)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(rest)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+
+		return items, nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected %q", ErrProtocol, line)
+	}
+}
@@ -0,0 +1,303 @@
+package redis_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// broker is a tiny Redis server supporting AUTH, SUBSCRIBE, PUBLISH, SET NX
+// and the coordinator's release script.
+type broker struct {
+	ln       net.Listener
+	password string
+
+	mu          sync.Mutex
+	subscribers map[string][]chan string
+	keys        map[string]string
+}
+
+func newBroker(t *testing.T, password string) *broker {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	b := &broker{ln: ln, password: password, subscribers: map[string][]chan string{}, keys: map[string]string{}}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go b.serve(nc)
+		}
+	}()
+
+	return b
+}
+
+func (b *broker) addr() string {
+	return b.ln.Addr().String()
+}
+
+// subscribe returns the messages published on channel from now on.
+func (b *broker) subscribe(channel string) <-chan string {
+	ch := make(chan string, 16)
+
+	b.mu.Lock()
+	b.subscribers[channel] = append(b.subscribers[channel], ch)
+	b.mu.Unlock()
+
+	return ch
+}
+
+func (b *broker) publish(channel, payload string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, ch := range b.subscribers[channel] {
+		ch <- payload
+	}
+
+	return len(b.subscribers[channel])
+}
+
+func (b *broker) subscribed(channel string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subscribers[channel]) > 0
+}
+
+// setNX sets key unless it is already set, reporting whether it did.
+func (b *broker) setNX(key, value string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.keys[key]; ok {
+		return false
+	}
+
+	b.keys[key] = value
+
+	return true
+}
+
+// deleteIf deletes key if it holds value, as the release script does.
+func (b *broker) deleteIf(key, value string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.keys[key] != value {
+		return 0
+	}
+
+	delete(b.keys, key)
+
+	return 1
+}
+
+func (b *broker) serve(nc net.Conn) {
+	defer nc.Close()
+
+	r := bufio.NewReader(nc)
+	authed := b.password == ""
+
+	var mu sync.Mutex
+
+	write := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		_, _ = io.WriteString(nc, s)
+	}
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		switch {
+		case args[0] == "AUTH":
+			if args[len(args)-1] != b.password {
+				write("-WRONGPASS invalid password\r\n")
+
+				continue
+			}
+
+			authed = true
+
+			write("+OK\r\n")
+		case !authed:
+			write("-NOAUTH Authentication required.\r\n")
+		case args[0] == "SUBSCRIBE":
+			messages := b.subscribe(args[1])
+
+			write(fmt.Sprintf("*3\r\n$9\r\nsubscribe\r\n%s:1\r\n", bulk(args[1])))
+
+			go func() {
+				for msg := range messages {
+					write(fmt.Sprintf("*3\r\n$7\r\nmessage\r\n%s%s", bulk(args[1]), bulk(msg)))
+				}
+			}()
+		case args[0] == "PUBLISH":
+			write(":" + strconv.Itoa(b.publish(args[1], args[2])) + "\r\n")
+		case args[0] == "SET":
+			if b.setNX(args[1], args[2]) {
+				write("+OK\r\n")
+			} else {
+				write("$-1\r\n")
+			}
+		case args[0] == "EVAL":
+			write(":" + strconv.Itoa(b.deleteIf(args[3], args[4])) + "\r\n")
+		default:
+			write("-ERR unknown command\r\n")
+		}
+	}
+}
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+
+	for i := range args {
+		if _, err := r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+
+		arg, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+
+	return args, nil
+}
+
+// reply is a decoded controls.RemoteReply. Its report is read loosely, as
+// the report is rendered for people rather than decoded back.
+type reply struct {
+	ID         string `json:"id"`
+	Controller string `json:"controller"`
+	Status     string `json:"status"`
+	Report     *struct {
+		State string `json:"state"`
+	} `json:"report"`
+}
+
+func TestTransport(t *testing.T) {
+	b := newBroker(t, "s3cret")
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(slog.New(slog.DiscardHandler)),
+		controls.WithoutSignals(),
+		controls.WithName("worker-1"),
+		controls.WithRemoteControl(redis.New(b.addr(),
+			redis.WithChannel("fleet"),
+			redis.WithAuth("", "s3cret"),
+		)),
+		controls.WithService("api", controls.WithStart(func(_ context.Context) error { return nil })),
+	)
+
+	require.NoError(t, c.Start())
+	require.Eventually(t, func() bool { return b.subscribed("fleet") }, time.Second, 5*time.Millisecond)
+
+	replies := b.subscribe("fleet" + redis.ReplySuffix)
+	b.publish("fleet", `{"id":"1","command":"status"}`)
+
+	var r reply
+
+	select {
+	case payload := <-replies:
+		require.NoError(t, json.Unmarshal([]byte(payload), &r))
+	case <-time.After(time.Second):
+		require.FailNow(t, "no reply")
+	}
+
+	assert.Equal(t, "1", r.ID)
+	assert.Equal(t, "worker-1", r.Controller)
+	require.NotNil(t, r.Report)
+	assert.Equal(t, "running", r.Report.State)
+
+	mine := b.subscribe("replies.ops")
+	b.publish("fleet", `{"id":"2","command":"shutdown","actor":"ops","reply_to":"replies.ops"}`)
+
+	select {
+	case payload := <-mine:
+		require.NoError(t, json.Unmarshal([]byte(payload), &r))
+	case <-time.After(time.Second):
+		require.FailNow(t, "no reply")
+	}
+
+	assert.Equal(t, "2", r.ID)
+	assert.Equal(t, "stopping", r.Status)
+
+	require.NoError(t, c.Wait())
+	require.NoError(t, c.Close())
+}
+
+func TestTransport_AuthFailure(t *testing.T) {
+	b := newBroker(t, "s3cret")
+
+	err := redis.New(b.addr(), redis.WithAuth("", "wrong")).Serve(context.Background(),
+		func(_ context.Context, _ controls.RemoteRequest) controls.RemoteReply { return controls.RemoteReply{} })
+	require.ErrorIs(t, err, redis.ErrCommandFailed)
+	assert.Contains(t, err.Error(), "WRONGPASS")
+}
+
+func TestTransport_StopsWithContext(t *testing.T) {
+	b := newBroker(t, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- redis.New(b.addr()).Serve(ctx,
+			func(_ context.Context, _ controls.RemoteRequest) controls.RemoteReply { return controls.RemoteReply{} })
+	}()
+
+	require.Eventually(t, func() bool { return b.subscribed(redis.DefaultChannel) }, time.Second, 5*time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.FailNow(t, "Serve did not return")
+	}
+}
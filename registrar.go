@@ -0,0 +1,148 @@
+package controls
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultRegistrarTimeout bounds each call the controller makes to its
+// Registrar.
+const DefaultRegistrarTimeout = 5 * time.Second
+
+// Registration describes the process to a service discovery system such as
+// Consul or etcd.
+type Registration struct {
+	// ID identifies this instance, defaulting to Name.
+	ID   string
+	Name string
+	Host string
+	Port int
+	Tags []string
+	// HealthURL is an HTTP endpoint the discovery system can poll, such as
+	// the controller's ReadyzHandler.
+	HealthURL string
+	// TTL, when set, is how long the registration stays healthy without an
+	// update. The controller reports its health every TTL/2.
+	TTL time.Duration
+}
+
+// Registrar registers the process with a service discovery system. The
+// consul and etcd packages provide implementations.
+type Registrar interface {
+	Register(ctx context.Context, reg Registration) error
+	Deregister(ctx context.Context, reg Registration) error
+	// ReportHealth keeps a TTL registration alive, passing the controller's
+	// readiness and, when it isn't ready, the reason.
+	ReportHealth(ctx context.Context, reg Registration, status HealthStatus, output string) error
+}
+
+// WithRegistrar registers reg with r once Start has brought the services up,
+// reports the controller's readiness to r every reg.TTL/2 while it runs, and
+// deregisters before the services are stopped. Failures are logged and
+// don't affect the services.
+func WithRegistrar(r Registrar, reg Registration) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			if reg.ID == "" {
+				reg.ID = reg.Name
+			}
+
+			ctrl.registrar = r
+			ctrl.registration = reg
+		})
+	}
+}
+
+// announcement tracks the registration, so a shutdown racing Start never
+// leaves it behind.
+type announcement struct {
+	mu         sync.Mutex
+	registered bool
+	retracted  bool
+}
+
+// announce registers the controller and starts the TTL updates.
+func (c *Controller) announce() {
+	if c.registrar == nil {
+		return
+	}
+
+	c.announcement.mu.Lock()
+	defer c.announcement.mu.Unlock()
+
+	if c.announcement.retracted {
+		return
+	}
+
+	ctx, cancel := c.withTimeout(context.Background(), DefaultRegistrarTimeout)
+	defer cancel()
+
+	if err := c.registrar.Register(ctx, c.registration); err != nil {
+		c.log().Error("Failed to register", "id", c.registration.ID, "error", err)
+
+		return
+	}
+
+	c.announcement.registered = true
+	c.log().Info("Registered", "id", c.registration.ID)
+
+	if c.registration.TTL <= 0 {
+		return
+	}
+
+	c.every(c.registration.TTL/2, func(_ <-chan struct{}) {
+		c.reportRegistrationHealth()
+	})
+}
+
+// reportRegistrationHealth reports the controller's readiness, degraded when
+// it is Degraded, while it remains registered.
+func (c *Controller) reportRegistrationHealth() {
+	c.announcement.mu.Lock()
+	defer c.announcement.mu.Unlock()
+
+	if !c.announcement.registered {
+		return
+	}
+
+	status, output := Healthy, ""
+	if err := c.CheckReady(); err != nil {
+		status, output = Unhealthy, err.Error()
+
+		if c.GetState() == Degraded {
+			status = HealthDegraded
+		}
+	}
+
+	ctx, cancel := c.withTimeout(context.Background(), DefaultRegistrarTimeout)
+	defer cancel()
+
+	if err := c.registrar.ReportHealth(ctx, c.registration, status, output); err != nil {
+		c.log().Error("Failed to report health to registrar", "id", c.registration.ID, "error", err)
+	}
+}
+
+// retract removes a successful registration.
+func (c *Controller) retract() {
+	c.announcement.mu.Lock()
+	defer c.announcement.mu.Unlock()
+
+	c.announcement.retracted = true
+
+	if !c.announcement.registered {
+		return
+	}
+
+	ctx, cancel := c.withTimeout(context.Background(), DefaultRegistrarTimeout)
+	defer cancel()
+
+	if err := c.registrar.Deregister(ctx, c.registration); err != nil {
+		c.log().Error("Failed to deregister", "id", c.registration.ID, "error", err)
+
+		return
+	}
+
+	c.announcement.registered = false
+	c.log().Info("Deregistered", "id", c.registration.ID)
+}
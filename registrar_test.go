@@ -0,0 +1,102 @@
+package controls_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/controlstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRegistrar struct {
+	mu     sync.Mutex
+	calls  []string
+	health []controls.HealthStatus
+}
+
+func (r *fakeRegistrar) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, call)
+}
+
+func (r *fakeRegistrar) Calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.calls...)
+}
+
+func (r *fakeRegistrar) Health() []controls.HealthStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]controls.HealthStatus(nil), r.health...)
+}
+
+func (r *fakeRegistrar) Register(_ context.Context, reg controls.Registration) error {
+	r.record("register " + reg.ID)
+
+	return nil
+}
+
+func (r *fakeRegistrar) Deregister(_ context.Context, reg controls.Registration) error {
+	r.record("deregister " + reg.ID)
+
+	return nil
+}
+
+func (r *fakeRegistrar) ReportHealth(
+	_ context.Context, _ controls.Registration, status controls.HealthStatus, _ string,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.health = append(r.health, status)
+
+	return nil
+}
+
+func TestController_WithRegistrar(t *testing.T) {
+	clock := controlstest.NewFakeClock(epoch)
+	reg := &fakeRegistrar{}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithClock(clock),
+		controls.WithRegistrar(reg, controls.Registration{Name: "api", TTL: 10 * time.Second}),
+	)
+	c.Register("api",
+		controls.WithStart(func(_ context.Context) error {
+			reg.record("start")
+
+			return nil
+		}),
+		controls.WithStop(func(_ context.Context) {
+			reg.record("stop")
+		}),
+	)
+
+	c.Start()
+	assert.Equal(t, []string{"start", "register api"}, reg.Calls())
+
+	require.Eventually(t, func() bool { return clock.Waiters() > 0 }, time.Second, time.Millisecond)
+	clock.Advance(5 * time.Second)
+	require.Eventually(t, func() bool { return len(reg.Health()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, controls.Healthy, reg.Health()[0])
+
+	require.NoError(t, c.EnterMaintenance(nil))
+	clock.Advance(5 * time.Second)
+	require.Eventually(t, func() bool { return len(reg.Health()) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, controls.Unhealthy, reg.Health()[1])
+
+	c.Stop()
+	assert.Equal(t, []string{"start", "register api", "deregister api", "stop"}, reg.Calls())
+}
@@ -0,0 +1,81 @@
+package controls
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// registry holds the named controllers of the process, so they can be found
+// from admin endpoints and diagnostics.
+var registry = struct {
+	mu          sync.RWMutex
+	controllers map[string]*Controller
+}{controllers: map[string]*Controller{}}
+
+// WithName names the controller. Once started it is added to the package
+// registry, where Get finds it until it stops or is closed. A later controller
+// with the same name replaces it.
+func WithName(name string) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.name = name
+		})
+	}
+}
+
+// Name returns the name given WithName, or "" for an unnamed controller.
+func (c *Controller) Name() string {
+	return c.name
+}
+
+// Get returns the named controller called name, if it has been started and
+// has not stopped.
+func Get(name string) (*Controller, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	c, ok := registry.controllers[name]
+
+	return c, ok
+}
+
+// Controllers returns the named controllers that have been started and have
+// not stopped, ordered by name.
+func Controllers() []*Controller {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	cs := make([]*Controller, 0, len(registry.controllers))
+	for _, c := range registry.controllers {
+		cs = append(cs, c)
+	}
+
+	slices.SortFunc(cs, func(a, b *Controller) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return cs
+}
+
+func (c *Controller) enroll() {
+	if c.name == "" {
+		return
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.controllers[c.name] = c
+}
+
+// withdraw removes the controller from the registry, unless it has already
+// been replaced.
+func (c *Controller) withdraw() {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if registry.controllers[c.name] == c {
+		delete(registry.controllers, c.name)
+	}
+}
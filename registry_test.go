@@ -0,0 +1,72 @@
+package controls_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	newNamed := func(name string) *controls.Controller {
+		c := controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+			controls.WithName(name),
+		)
+		c.Register("svc",
+			controls.WithStart(noopStart),
+			controls.WithStop(func(_ context.Context) {}),
+		)
+
+		return c
+	}
+
+	billing := newNamed("registry-billing")
+	auth := newNamed("registry-auth")
+
+	_, found := controls.Get("registry-billing")
+	assert.False(t, found, "a controller joins the registry when it starts")
+
+	billing.Start()
+	auth.Start()
+
+	got, ok := controls.Get("registry-billing")
+	require.True(t, ok)
+	assert.Same(t, billing, got)
+	assert.Equal(t, "registry-billing", got.Name())
+	assert.Equal(t, "registry-billing", got.Report().Name)
+
+	var names []string
+	for _, c := range controls.Controllers() {
+		names = append(names, c.Name())
+	}
+
+	assert.Subset(t, names, []string{"registry-auth", "registry-billing"})
+
+	require.NoError(t, billing.Stop())
+
+	_, ok = controls.Get("registry-billing")
+	assert.False(t, ok, "a stopped controller leaves the registry")
+
+	got, ok = controls.Get("registry-auth")
+	require.True(t, ok)
+	assert.Same(t, auth, got)
+
+	_, ok = controls.Get("")
+	assert.False(t, ok, "unnamed controllers are not registered")
+
+	require.NoError(t, auth.Close())
+
+	_, ok = controls.Get("registry-auth")
+	assert.False(t, ok, "a closed controller leaves the registry")
+
+	unstarted := newNamed("registry-unstarted")
+	require.NoError(t, unstarted.Close())
+
+	_, ok = controls.Get("registry-unstarted")
+	assert.False(t, ok)
+}
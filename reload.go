@@ -0,0 +1,60 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ReloadFunc applies new configuration to a running service.
+type ReloadFunc func(ctx context.Context, cfg any) error
+
+// WithReload sets the function Controller.Reload calls with new configuration.
+func WithReload(fn ReloadFunc) ServiceOption {
+	return func(s *Service) {
+		s.Reload = fn
+	}
+}
+
+// Reload delivers cfg to every service registered WithReload, in registration
+// order, skipping disabled services. It returns the names of the services
+// that applied it and the joined errors of those that didn't, each prefixed
+// with the service name.
+func (c *Controller) Reload(cfg any) ([]string, error) {
+	var (
+		applied []string
+		errs    []error
+	)
+
+	for _, s := range c.services.reloadable() {
+		if err := s.Reload(c.services.scoped(c.ctx, s), cfg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name, err))
+
+			continue
+		}
+
+		applied = append(applied, s.Name)
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		c.log().Error("Reload failed", "error", err)
+	}
+
+	return applied, err
+}
+
+func (q *Services) reloadable() []*service {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var services []*service
+
+	for _, s := range q.services {
+		if s.Reload != nil && s.state != Disabled {
+			services = append(services, s)
+		}
+	}
+
+	return services
+}
@@ -0,0 +1,60 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type config struct {
+	Level string
+}
+
+func TestController_Reload(t *testing.T) {
+	var got config
+
+	errInvalid := errors.New("invalid level") //nolint:err113
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("api",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithReload(func(_ context.Context, cfg any) error {
+			got = cfg.(config) //nolint:forcetypeassert
+
+			return nil
+		}),
+	)
+	c.Register("strict",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithReload(func(_ context.Context, _ any) error { return errInvalid }),
+	)
+	c.Register("off",
+		controls.WithEnabledIf(false),
+		controls.WithReload(func(_ context.Context, _ any) error {
+			t.Error("disabled service reloaded")
+
+			return nil
+		}),
+	)
+	c.Register("static", controls.WithStart(noopStart), controls.WithStop(func(_ context.Context) {}))
+
+	c.Start()
+
+	applied, err := c.Reload(config{Level: "debug"})
+	require.ErrorIs(t, err, errInvalid)
+	assert.EqualError(t, err, "strict: invalid level")
+	assert.Equal(t, []string{"api"}, applied)
+	assert.Equal(t, config{Level: "debug"}, got)
+
+	require.NoError(t, c.Stop())
+}
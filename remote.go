@@ -0,0 +1,156 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultRemoteRetryDelay is how long the controller waits before serving a
+// RemoteTransport again after it fails.
+const DefaultRemoteRetryDelay = time.Second
+
+// ErrUnknownCommand is returned in a RemoteReply for a command the controller
+// doesn't recognise.
+var ErrUnknownCommand = errors.New("unknown command")
+
+// RemoteRequest is a control command received through a RemoteTransport.
+// Command is ActionStatus, ActionReload, ActionShutdown, or
+// ActionStopService or ActionRestartService for the named Service. Actor,
+// when set, is recorded in the audit trail as who sent it.
+type RemoteRequest struct {
+	ID      string `json:"id,omitempty"`
+	Command string `json:"command"`
+	Service string `json:"service,omitempty"`
+	Actor   string `json:"actor,omitempty"`
+}
+
+// RemoteReply answers a RemoteRequest. Status says what the controller is
+// doing about the command, and a status request also carries the Report. A
+// failed command's Error is its message, and Err the error itself, for
+// transports that map errors to their own codes.
+type RemoteReply struct {
+	ID         string  `json:"id,omitempty"`
+	Controller string  `json:"controller,omitempty"`
+	Status     string  `json:"status,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	Err        error   `json:"-"`
+	Report     *Report `json:"report,omitempty"`
+}
+
+// RemoteHandler handles a request received by a RemoteTransport and returns
+// the reply to send back.
+type RemoteHandler func(context.Context, RemoteRequest) RemoteReply
+
+// RemoteTransport carries control commands to the controller from elsewhere,
+// such as a topic on a message bus that a fleet of daemons listens on, and
+// carries the replies back.
+type RemoteTransport interface {
+	// Serve receives requests until ctx is done, passing each to handle and
+	// sending back the reply it returns.
+	Serve(ctx context.Context, handle RemoteHandler) error
+}
+
+// WithRemoteControl serves commands from t once the controller starts, until
+// it has stopped. If t fails, such as when it loses its connection, the error
+// is logged and t is served again after DefaultRemoteRetryDelay. Commands are
+// audited with the AuditRemote source.
+func WithRemoteControl(t RemoteTransport) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.remote = t
+		})
+	}
+}
+
+func (c *Controller) startRemote() {
+	if c.remote == nil {
+		return
+	}
+
+	c.goBackground(func(done <-chan struct{}) {
+		ctx, cancel := context.WithCancel(context.WithoutCancel(c.ctx))
+		defer cancel()
+
+		go func() {
+			select {
+			case <-done:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		for {
+			err := c.remote.Serve(ctx, c.handleRemote)
+			if ctx.Err() != nil {
+				return
+			}
+
+			c.log().Error("Remote control failed", "error", err, "retry", DefaultRemoteRetryDelay)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.clock.After(DefaultRemoteRetryDelay):
+			}
+		}
+	})
+}
+
+// handleRemote carries out a remote request.
+func (c *Controller) handleRemote(ctx context.Context, req RemoteRequest) RemoteReply {
+	reply := RemoteReply{ID: req.ID, Controller: c.name}
+	o := origin{source: AuditRemote, actor: req.Actor}
+
+	var err error
+
+	switch req.Command {
+	case ActionStatus:
+		r := c.Report()
+		reply.Status = string(r.State)
+		reply.Report = &r
+	case ActionReload:
+		switch {
+		case !c.isUp():
+			err = ErrNotRunning
+		case c.sendMessage(Reload, o, ctx.Done()):
+			reply.Status = "reloading"
+		case ctx.Err() != nil:
+			err = context.Cause(ctx)
+		default:
+			err = ErrNotRunning
+		}
+	case ActionStopService:
+		if err = c.audited(o, ActionStopService, req.Service, "", func() error {
+			return c.stopService(ctx, req.Service)
+		}); err == nil {
+			reply.Status = "stopped"
+		}
+	case ActionRestartService:
+		if err = c.audited(o, ActionRestartService, req.Service, "", func() error {
+			return c.restartService(ctx, req.Service)
+		}); err == nil {
+			reply.Status = "restarted"
+		}
+	case ActionShutdown:
+		if !c.isUp() {
+			err = ErrNotRunning
+
+			break
+		}
+
+		reply.Status = "stopping"
+
+		go c.requestStop(StopRequested, o)
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnknownCommand, req.Command)
+	}
+
+	if err != nil {
+		reply.Error = err.Error()
+		reply.Err = err
+	}
+
+	return reply
+}
@@ -0,0 +1,164 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/controlstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// remoteTransport hands requests sent with send to the controller.
+type remoteTransport struct {
+	requests chan controls.RemoteRequest
+	replies  chan controls.RemoteReply
+}
+
+func newRemoteTransport() *remoteTransport {
+	return &remoteTransport{
+		requests: make(chan controls.RemoteRequest),
+		replies:  make(chan controls.RemoteReply),
+	}
+}
+
+func (r *remoteTransport) Serve(ctx context.Context, handle controls.RemoteHandler) error {
+	for {
+		select {
+		case req := <-r.requests:
+			r.replies <- handle(ctx, req)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (r *remoteTransport) send(t *testing.T, req controls.RemoteRequest) controls.RemoteReply {
+	t.Helper()
+
+	select {
+	case r.requests <- req:
+	case <-time.After(time.Second):
+		require.FailNow(t, "request not received")
+	}
+
+	return <-r.replies
+}
+
+func TestController_RemoteControl(t *testing.T) {
+	var reloads atomic.Int64
+
+	remote := newRemoteTransport()
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithName("remote-test"),
+		controls.WithRemoteControl(remote),
+		controls.WithService("api",
+			controls.WithStart(noopStart),
+			controls.WithMessageHandler(func(msg controls.Message) error {
+				if msg == controls.Reload {
+					reloads.Add(1)
+				}
+
+				return nil
+			}),
+		),
+	)
+
+	require.NoError(t, c.Start())
+
+	reply := remote.send(t, controls.RemoteRequest{ID: "1", Command: controls.ActionStatus})
+	assert.Equal(t, "1", reply.ID)
+	assert.Equal(t, "remote-test", reply.Controller)
+	assert.Equal(t, "running", reply.Status)
+	require.NotNil(t, reply.Report)
+	require.Len(t, reply.Report.Services, 1)
+	assert.Equal(t, "api", reply.Report.Services[0].Name)
+
+	reply = remote.send(t, controls.RemoteRequest{ID: "2", Command: controls.ActionReload, Actor: "ops"})
+	assert.Equal(t, "reloading", reply.Status)
+	assert.Empty(t, reply.Error)
+	assert.Eventually(t, func() bool { return reloads.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	reply = remote.send(t, controls.RemoteRequest{Command: controls.ActionStopService, Service: "api", Actor: "ops"})
+	assert.Equal(t, "stopped", reply.Status)
+	assert.Equal(t, controls.Stopped, c.Report().Services[0].State)
+
+	reply = remote.send(t, controls.RemoteRequest{Command: controls.ActionRestartService, Service: "api", Actor: "ops"})
+	assert.Equal(t, "restarted", reply.Status)
+
+	reply = remote.send(t, controls.RemoteRequest{Command: controls.ActionStopService, Service: "missing"})
+	require.ErrorIs(t, reply.Err, controls.ErrServiceNotFound)
+	assert.Empty(t, reply.Status)
+
+	reply = remote.send(t, controls.RemoteRequest{ID: "3", Command: "dance"})
+	assert.Contains(t, reply.Error, controls.ErrUnknownCommand.Error())
+
+	reply = remote.send(t, controls.RemoteRequest{ID: "4", Command: controls.ActionShutdown, Actor: "ops"})
+	assert.Equal(t, "stopping", reply.Status)
+
+	require.NoError(t, c.Wait())
+	assert.Equal(t, controls.StopRequested, c.StopReason())
+
+	var entries []controls.AuditEntry
+
+	for _, e := range c.AuditLog() {
+		if e.Source == controls.AuditRemote {
+			entries = append(entries, e)
+		}
+	}
+
+	require.Len(t, entries, 5)
+	assert.Equal(t, "ops", entries[0].Actor)
+	assert.Equal(t, "reload", entries[0].Command)
+	assert.Equal(t, controls.ActionStopService, entries[1].Command)
+	assert.Equal(t, "api", entries[1].Service)
+	assert.Equal(t, controls.ActionRestartService, entries[2].Command)
+	assert.Equal(t, "missing", entries[3].Service)
+	assert.Equal(t, "stop", entries[4].Command)
+}
+
+// flakyTransport fails the first time it is served.
+type flakyTransport struct {
+	*remoteTransport
+
+	serves atomic.Int64
+}
+
+func (f *flakyTransport) Serve(ctx context.Context, handle controls.RemoteHandler) error {
+	if f.serves.Add(1) == 1 {
+		return errors.New("connection refused") //nolint:err113
+	}
+
+	return f.remoteTransport.Serve(ctx, handle)
+}
+
+func TestController_RemoteControlRetries(t *testing.T) {
+	clock := controlstest.NewFakeClock(time.Now())
+	remote := &flakyTransport{remoteTransport: newRemoteTransport()}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithClock(clock),
+		controls.WithRemoteControl(remote),
+		controls.WithService("api", controls.WithStart(noopStart)),
+	)
+
+	require.NoError(t, c.Start())
+	defer c.Stop()
+
+	require.Eventually(t, func() bool { return clock.Waiters() > 0 }, time.Second, time.Millisecond)
+	clock.Advance(controls.DefaultRemoteRetryDelay)
+
+	reply := remote.send(t, controls.RemoteRequest{Command: controls.ActionStatus})
+	assert.Equal(t, "running", reply.Status)
+	assert.Equal(t, int64(2), remote.serves.Load())
+}
@@ -0,0 +1,154 @@
+package controls
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Report is a point in time view of the controller and its services, suitable
+// for dumping on a debug endpoint.
+type Report struct {
+	Name        string          `json:"name,omitempty"`
+	State       State           `json:"state"`
+	Maintenance bool            `json:"maintenance,omitempty"`
+	StartedAt   time.Time       `json:"started_at"`
+	Uptime      time.Duration   `json:"uptime"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Services    []ServiceReport `json:"services"`
+}
+
+// ServiceReport describes a single registered service within a Report.
+type ServiceReport struct {
+	Name      string        `json:"name"`
+	State     State         `json:"state"`
+	StartedAt time.Time     `json:"started_at"`
+	Uptime    time.Duration `json:"uptime"`
+	Restarts  int           `json:"restarts"`
+	Ready     bool          `json:"ready"`
+	// HealthFailures counts the service's failed status checks.
+	HealthFailures int `json:"health_failures"`
+	// Stalled is set while a service with a heartbeat deadline is overdue.
+	Stalled bool `json:"stalled,omitempty"`
+	// TotalUptime sums the service's uptime over every run. LastRestart is
+	// when it was last restarted, and StartDuration and StopDuration how long
+	// it last took to become ready and to stop.
+	TotalUptime   time.Duration     `json:"total_uptime"`
+	LastRestart   time.Time         `json:"last_restart"`
+	StartDuration time.Duration     `json:"start_duration"`
+	StopDuration  time.Duration     `json:"stop_duration"`
+	LastError     error             `json:"last_error,omitempty"`
+	LastHealth    *HealthMessage    `json:"last_health,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Details       map[string]string `json:"details,omitempty"`
+}
+
+// MarshalJSON renders uptimes as duration strings and omits zero timestamps.
+func (r Report) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name        string          `json:"name,omitempty"`
+		State       State           `json:"state"`
+		Maintenance bool            `json:"maintenance,omitempty"`
+		StartedAt   *time.Time      `json:"started_at,omitempty"`
+		Uptime      string          `json:"uptime"`
+		GeneratedAt time.Time       `json:"generated_at"`
+		Services    []ServiceReport `json:"services"`
+	}{
+		Name:        r.Name,
+		State:       r.State,
+		Maintenance: r.Maintenance,
+		StartedAt:   timeOrNil(r.StartedAt),
+		Uptime:      r.Uptime.String(),
+		GeneratedAt: r.GeneratedAt,
+		Services:    r.Services,
+	})
+}
+
+// MarshalJSON renders the uptime as a duration string and the last error as
+// its message.
+func (r ServiceReport) MarshalJSON() ([]byte, error) {
+	var lastErr string
+	if r.LastError != nil {
+		lastErr = r.LastError.Error()
+	}
+
+	return json.Marshal(struct {
+		Name           string            `json:"name"`
+		State          State             `json:"state"`
+		StartedAt      *time.Time        `json:"started_at,omitempty"`
+		Uptime         string            `json:"uptime"`
+		Restarts       int               `json:"restarts"`
+		Ready          bool              `json:"ready"`
+		HealthFailures int               `json:"health_failures"`
+		Stalled        bool              `json:"stalled,omitempty"`
+		TotalUptime    string            `json:"total_uptime"`
+		LastRestart    *time.Time        `json:"last_restart,omitempty"`
+		StartDuration  string            `json:"start_duration"`
+		StopDuration   string            `json:"stop_duration"`
+		LastError      string            `json:"last_error,omitempty"`
+		LastHealth     *HealthMessage    `json:"last_health,omitempty"`
+		Labels         map[string]string `json:"labels,omitempty"`
+		Details        map[string]string `json:"details,omitempty"`
+	}{
+		Name:           r.Name,
+		State:          r.State,
+		StartedAt:      timeOrNil(r.StartedAt),
+		Uptime:         r.Uptime.String(),
+		Restarts:       r.Restarts,
+		Ready:          r.Ready,
+		HealthFailures: r.HealthFailures,
+		Stalled:        r.Stalled,
+		TotalUptime:    r.TotalUptime.String(),
+		LastRestart:    timeOrNil(r.LastRestart),
+		StartDuration:  r.StartDuration.String(),
+		StopDuration:   r.StopDuration.String(),
+		LastError:      lastErr,
+		LastHealth:     r.LastHealth,
+		Labels:         r.Labels,
+		Details:        r.Details,
+	})
+}
+
+// Report returns the current state of the controller and its services.
+func (c *Controller) Report() Report {
+	now := c.clock.Now()
+	startedAt := c.getStartedAt()
+
+	r := Report{
+		Name:        c.name,
+		State:       c.GetState(),
+		Maintenance: c.InMaintenance(),
+		StartedAt:   startedAt,
+		GeneratedAt: now,
+		Services:    c.services.reports(now),
+	}
+
+	if !startedAt.IsZero() {
+		r.Uptime = now.Sub(startedAt)
+	}
+
+	return r
+}
+
+// RecordHealth stores h as the latest health message for the named service so
+// it is included in Report, setting its ServiceName and, when zero, its
+// Timestamp. It returns false when no such service exists.
+func (c *Controller) RecordHealth(name string, h HealthMessage) bool {
+	h.ServiceName = name
+
+	if h.Timestamp.IsZero() {
+		h.Timestamp = c.clock.Now()
+	}
+
+	recorded := c.services.recordHealth(name, h)
+	c.assess()
+
+	return recorded
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
@@ -0,0 +1,99 @@
+package controls_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/controlstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_Report(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("ok",
+		controls.WithStart(func(_ context.Context) error { return nil }),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+	c.Register("broken",
+		controls.WithStart(func(_ context.Context) error { return fmt.Errorf("boom") }), //nolint:err113
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	before := c.Report()
+	assert.Equal(t, controls.Unknown, before.State)
+	assert.Zero(t, before.Uptime)
+
+	c.Start()
+	assert.True(t, c.RecordHealth("ok", controls.HealthMessage{Status: controls.Healthy, Message: "fine"}))
+	assert.False(t, c.RecordHealth("missing", controls.HealthMessage{}))
+
+	assert.Eventually(t, func() bool {
+		return c.Report().Services[1].State == controls.Failed
+	}, time.Second, 5*time.Millisecond)
+
+	r := c.Report()
+	assert.Equal(t, controls.Degraded, r.State)
+	require.Len(t, r.Services, 2)
+	assert.Equal(t, controls.Running, r.Services[0].State)
+	require.NotNil(t, r.Services[0].LastHealth)
+	assert.Equal(t, "fine", r.Services[0].LastHealth.Message)
+	assert.Equal(t, "ok", r.Services[0].LastHealth.ServiceName)
+	assert.False(t, r.Services[0].LastHealth.Timestamp.IsZero())
+	assert.EqualError(t, r.Services[1].LastError, "boom")
+
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "degraded", decoded["state"])
+
+	services, ok := decoded["services"].([]any)
+	require.True(t, ok)
+	require.Len(t, services, 2)
+	assert.Equal(t, "boom", services[1].(map[string]any)["last_error"])
+	assert.IsType(t, "", services[0].(map[string]any)["uptime"])
+}
+
+func TestController_ReportTimings(t *testing.T) {
+	clock := controlstest.NewFakeClock(epoch)
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithClock(clock),
+	)
+	c.Register("db",
+		controls.WithStart(func(_ context.Context) error {
+			clock.Advance(2 * time.Second)
+
+			return nil
+		}),
+		controls.WithStop(func(_ context.Context) { clock.Advance(3 * time.Second) }),
+	)
+
+	c.Start()
+	clock.Advance(10 * time.Second)
+
+	restartedAt := clock.Now()
+	require.NoError(t, c.RestartService("db"))
+	assert.Eventually(t, func() bool { return c.Report().Services[0].Ready }, time.Second, time.Millisecond)
+	clock.Advance(5 * time.Second)
+
+	s := c.Report().Services[0]
+	assert.Equal(t, 7*time.Second, s.Uptime)
+	assert.Equal(t, 22*time.Second, s.TotalUptime)
+	assert.Equal(t, restartedAt.Add(3*time.Second), s.LastRestart)
+	assert.Equal(t, 2*time.Second, s.StartDuration)
+	assert.Equal(t, 3*time.Second, s.StopDuration)
+
+	require.NoError(t, c.Stop())
+}
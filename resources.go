@@ -0,0 +1,87 @@
+package controls
+
+import (
+	"sync"
+	"time"
+)
+
+// background tracks goroutines owned by the controller itself, such as
+// servers, tickers and watchers, so shutdown can stop and drain them.
+type background struct {
+	mu   sync.Mutex
+	wg   sync.WaitGroup
+	done chan struct{}
+}
+
+// goBackground runs fn in a tracked goroutine. done is closed when the
+// controller releases its resources and fn must return promptly after that.
+func (c *Controller) goBackground(fn func(done <-chan struct{})) {
+	c.background.mu.Lock()
+	if c.background.done == nil {
+		c.background.done = make(chan struct{})
+	}
+
+	done := c.background.done
+
+	c.background.wg.Add(1)
+	c.background.mu.Unlock()
+
+	go func() {
+		defer c.background.wg.Done()
+
+		fn(done)
+	}()
+}
+
+// every calls fn on each tick of a ticker from the controller's clock until
+// resources are released. The ticker is stopped before the goroutine exits and
+// fn is given the done channel so it can abandon blocking work.
+func (c *Controller) every(d time.Duration, fn func(done <-chan struct{})) {
+	ticker := c.clock.NewTicker(d)
+
+	c.goBackground(func(done <-chan struct{}) {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C():
+				fn(done)
+			case <-done:
+				return
+			}
+		}
+	})
+}
+
+// releaseResources signals every background goroutine to finish, waits for
+// them and emits ResourcesReleased.
+func (c *Controller) releaseResources() {
+	c.background.mu.Lock()
+	if c.background.done != nil {
+		close(c.background.done)
+		c.background.done = nil
+	}
+	c.background.mu.Unlock()
+
+	c.background.wg.Wait()
+	c.emit(Event{Type: ResourcesReleased})
+}
+
+// WithStatusInterval sends a Status message every d while the controller runs.
+func WithStatusInterval(d time.Duration) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.statusInterval = d
+		})
+	}
+}
+
+func (c *Controller) startStatusScheduler() {
+	if c.statusInterval <= 0 {
+		return
+	}
+
+	c.every(c.statusInterval, func(done <-chan struct{}) {
+		c.sendMessage(Status, origin{}, done)
+	})
+}
@@ -0,0 +1,55 @@
+package controls_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/controlstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestController_ReleasesResources(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	var statuses atomic.Int64
+
+	clock := controlstest.NewFakeClock(epoch)
+	rec := &eventRecorder{}
+
+	for range 3 {
+		c := controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+			controls.WithClock(clock),
+			controls.WithStatusInterval(time.Minute),
+			controls.WithAdminAPI("127.0.0.1:0"),
+			controls.WithEventHandler(rec.handle),
+		)
+		c.Register("svc",
+			controls.WithStart(func(_ context.Context) error { return nil }),
+			controls.WithStop(func(_ context.Context) {}),
+			controls.WithStatus(func() { statuses.Add(1) }),
+		)
+
+		c.Start()
+
+		before := statuses.Load()
+		clock.Advance(time.Minute)
+		require.Eventually(t, func() bool { return statuses.Load() == before+1 }, time.Second, time.Millisecond)
+
+		c.Stop()
+		require.Eventually(t, func() bool {
+			return len(rec.ofType(controls.ResourcesReleased)) > 0
+		}, time.Second, time.Millisecond)
+
+		rec = &eventRecorder{}
+	}
+
+	assert.True(t, clock.TickersStopped())
+}
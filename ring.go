@@ -0,0 +1,41 @@
+package controls
+
+// ring is a fixed size buffer keeping the most recent items.
+type ring[T any] struct {
+	items []T
+	next  int
+	full  bool
+}
+
+func (r *ring[T]) add(size int, item T) {
+	if size <= 0 {
+		return
+	}
+
+	if len(r.items) != size {
+		r.resize(size)
+	}
+
+	r.items[r.next] = item
+	r.next = (r.next + 1) % size
+	r.full = r.full || r.next == 0
+}
+
+func (r *ring[T]) resize(size int) {
+	kept := r.all()
+	if len(kept) > size {
+		kept = kept[len(kept)-size:]
+	}
+
+	r.items = make([]T, size)
+	r.next = copy(r.items, kept) % size
+	r.full = len(kept) == size
+}
+
+func (r *ring[T]) all() []T {
+	if !r.full {
+		return append([]T(nil), r.items[:r.next]...)
+	}
+
+	return append(append([]T(nil), r.items[r.next:]...), r.items[:r.next]...)
+}
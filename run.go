@@ -0,0 +1,28 @@
+package controls
+
+import (
+	"context"
+	"errors"
+)
+
+// Run is a one-call entry point for simple daemons. It runs services under a
+// controller that stops on SIGINT or SIGTERM, when ctx is cancelled or as
+// soon as a service fails, and waits for the shutdown. It returns nil after a
+// clean shutdown, and otherwise the failure that caused it joined with any
+// errors from stopping the services.
+func Run(ctx context.Context, services ...Service) error {
+	c := NewController(ctx, WithServices(services...), WithStopOnError())
+
+	if err := c.Start(); err != nil {
+		return errors.Join(err, c.Close())
+	}
+
+	failure := c.Wait()
+	err := c.Close()
+
+	if c.StopReason() == StopServiceFailed {
+		return errors.Join(failure, err)
+	}
+
+	return err
+}
@@ -0,0 +1,70 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	blocking := func(ctx context.Context) error {
+		controls.Ready(ctx)
+		<-ctx.Done()
+
+		return ctx.Err()
+	}
+
+	t.Run("clean shutdown", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		stopped := make(chan struct{})
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+
+		err := controls.Run(ctx, controls.Service{
+			Name:  "api",
+			Start: blocking,
+			Stop:  func(_ context.Context) { close(stopped) },
+		})
+		require.NoError(t, err)
+
+		select {
+		case <-stopped:
+		default:
+			t.Fatal("the service was not stopped")
+		}
+	})
+
+	t.Run("service failure", func(t *testing.T) {
+		errBoom := errors.New("boom") //nolint:err113
+
+		err := controls.Run(context.Background(),
+			controls.Service{
+				Name:  "api",
+				Start: blocking,
+				Stop:  func(_ context.Context) {},
+			},
+			controls.Service{
+				Name: "worker",
+				Start: func(ctx context.Context) error {
+					controls.Ready(ctx)
+					time.Sleep(10 * time.Millisecond)
+
+					return errBoom
+				},
+				Stop: func(_ context.Context) {},
+			},
+		)
+		require.ErrorIs(t, err, errBoom)
+		assert.Contains(t, err.Error(), "boom")
+	})
+}
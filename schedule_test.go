@@ -0,0 +1,47 @@
+package controls_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	// A Wednesday.
+	from := time.Date(2026, time.January, 7, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2026, 1, 7, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 1, 7, 10, 30, 0, 0, time.UTC)},
+		{"5 9-17/4 * * *", time.Date(2026, 1, 7, 13, 5, 0, 0, time.UTC)},
+		{"0 0 * * sun", time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)},
+		{"30 6 1,15 * *", time.Date(2026, 1, 15, 6, 30, 0, 0, time.UTC)},
+		{"0 0 1 mar *", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 13 * fri", time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 1, 7, 11, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := controls.ParseCron(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.next, schedule.Next(from))
+		})
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "@every soon"} {
+		_, err := controls.ParseCron(spec)
+		assert.ErrorIs(t, err, controls.ErrInvalidCron, spec)
+	}
+}
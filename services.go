@@ -2,65 +2,762 @@ package controls
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// ErrServiceNotFound is returned when a named service isn't registered.
+var ErrServiceNotFound = errors.New("service not found")
+
 type Services struct {
-	mu       sync.Mutex
-	services []Service
+	// ops serialises the operations that start, stop, pause and resume
+	// services, and is held while they call into service code. mu guards the
+	// services and what is recorded about them, and is only held briefly, so
+	// heartbeats, handles and reports don't wait on a StopFunc.
+	ops         sync.Mutex
+	mu          sync.Mutex
+	services    []*service
+	rejected    []error
+	historySize int
+	// startTimeout and statusTimeout are the controller-wide defaults for
+	// services that don't set their own.
+	startTimeout  time.Duration
+	statusTimeout time.Duration
+	// stopConcurrency bounds how many services in a wave are stopped at once.
+	stopConcurrency int
+	withTimeout     timeoutFunc
+	halting         halting
+	// unfinished holds a channel for each active service, closed once it
+	// stops. It has its own lock so it can be waited on without mu.
+	unfinished unfinished
+	now        func() time.Time
+	after      func(time.Duration) <-chan time.Time
+	logger     func() *slog.Logger
+	// controlLog is the controller's own, quiet-aware, logger.
+	controlLog func() *slog.Logger
+	// completed is called, without the lock held, when a one-shot service
+	// finishes.
+	completed func(name string)
+	// changed is called, without the lock held, when a service becomes ready
+	// or fails.
+	changed func()
+	// transitioned records a service's change of state. It is called with the
+	// lock held.
+	transitioned func(name string, from, to State)
+	// report passes a service's error to the controller. It is called without
+	// the lock held.
+	report func(err error)
+	// listeners is made available to services through their contexts.
+	listeners *ListenerRegistry
+	// timeSource is the controller's clock, made available to services
+	// through their contexts.
+	timeSource Clock
+}
+
+// service pairs a registered Service with what the controller has observed
+// about it at runtime. The runtime fields are guarded by Services.mu.
+type service struct {
+	Service
+
+	state      State
+	startedAt  time.Time
+	stoppedAt  time.Time
+	lastErr    error
+	lastHealth *HealthMessage
+	history    ring[HealthMessage]
+	// healthFailures counts every failed status check, consecutiveFailures
+	// only those since the last success or automatic restart.
+	healthFailures      int
+	consecutiveFailures int
+	restarts            int
+	ready               bool
+	// lastRestart is when the service was last restarted, startDuration and
+	// stopDuration how long it last took to become ready and to stop, and
+	// upTotal its uptime summed over previous runs.
+	lastRestart   time.Time
+	startDuration time.Duration
+	stopDuration  time.Duration
+	upTotal       time.Duration
+	lastBeat      time.Time
+	stalled       bool
+	// checking is set while a context-aware status check runs, including
+	// one abandoned after its timeout.
+	checking bool
+	// suspended is set while the service's PauseFunc has it paused, still
+	// holding whatever it started.
+	suspended bool
+	// settled is closed once a launched one-shot service completes or fails.
+	settled chan struct{}
+	handle  *ServiceHandle
+	// owner is the Services the service belongs to, which changes when it is
+	// adopted while running.
+	owner atomic.Pointer[Services]
+}
+
+// services returns the Services s currently belongs to.
+func (s *service) services() *Services {
+	return s.owner.Load()
+}
+
+// add adds s and returns its handle, unless its name is already taken. The
+// rejection is kept for Start to return.
+func (q *Services) add(s Service) (*ServiceHandle, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if slices.ContainsFunc(q.services, func(t *service) bool { return t.Name == s.Name }) {
+		err := fmt.Errorf("%w: %s", ErrServiceExists, s.Name)
+		q.rejected = append(q.rejected, err)
+
+		return nil, err
+	}
+
+	entry := newEntry(s)
+	entry.owner.Store(q)
+	q.services = append(q.services, entry)
+
+	return entry.handle, nil
 }
 
-func (q *Services) add(s Service) {
+// rejections joins the errors of the registrations add refused.
+func (q *Services) rejections() error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	q.services = append(q.services, s)
+	return errors.Join(q.rejected...)
+}
+
+// newEntry wraps s, not yet started, for adding to the services.
+func newEntry(s Service) *service {
+	return &service{
+		Service: s,
+		state:   Unknown,
+		handle:  &ServiceHandle{name: s.Name, inbox: make(chan Message, DefaultInboxSize)},
+	}
 }
 
-func (q *Services) start(ctx context.Context, errChan chan error) {
+// addAll adds services and returns them, or adds none and returns why if any
+// is unnamed, has no start function or has a name that is already taken.
+func (q *Services) addAll(services []Service) ([]*service, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var errs []error
+
+	for i, s := range services {
+		taken := slices.ContainsFunc(q.services, func(t *service) bool { return t.Name == s.Name }) ||
+			slices.ContainsFunc(services[:i], func(t Service) bool { return t.Name == s.Name })
+
+		switch {
+		case s.Name == "":
+			errs = append(errs, fmt.Errorf("service %d: %w", i, ErrUnnamedService))
+		case taken:
+			errs = append(errs, fmt.Errorf("%w: %s", ErrServiceExists, s.Name))
+		case s.Start == nil:
+			errs = append(errs, fmt.Errorf("%w: %s", ErrMissingStart, s.Name))
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	added := make([]*service, 0, len(services))
+
+	for _, s := range services {
+		entry := newEntry(s)
+		entry.owner.Store(q)
+		added = append(added, entry)
+	}
+
+	q.services = append(q.services, added...)
+
+	return added, nil
+}
+
+// empty reports whether no services are registered.
+func (q *Services) empty() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.services) == 0
+}
+
+// start launches every service that isn't being held back by hold and waits
+// until each is ready or has failed. It reports false if timeout fires first.
+func (q *Services) start(ctx context.Context, timeout <-chan struct{}) bool {
+	q.ops.Lock()
 	q.mu.Lock()
 
 	wg := &sync.WaitGroup{}
 	for _, s := range q.services {
+		if s.held() || s.live() {
+			continue
+		}
+
 		wg.Add(1)
+		q.launch(ctx, s, wg.Done)
+	}
+
+	q.mu.Unlock()
+	q.ops.Unlock()
 
-		go func(fn StartFunc, errs chan error) {
-			err := fn(ctx)
-			if err != nil {
-				errs <- err
+	if timeout == nil {
+		wg.Wait()
+
+		return true
+	}
+
+	ready := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(ready)
+	}()
+
+	select {
+	case <-ready:
+		return true
+	case <-timeout:
+		return false
+	}
+}
+
+// hold marks disabled services, and the named services as paused or stopped,
+// so start skips them, and returns how many services start will launch.
+// Services adopted while running are left as they are.
+func (q *Services) hold(states map[string]FlagState) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	starting := 0
+
+	for _, s := range q.services {
+		if s.live() {
+			continue
+		}
+
+		if s.disabled() {
+			q.setState(s, Disabled)
+
+			continue
+		}
+
+		switch states[s.Name] {
+		case FlagPause:
+			q.setState(s, Paused)
+		case FlagStop:
+			q.setState(s, Stopped)
+		case FlagRun:
+		}
+
+		if !s.held() {
+			starting++
+		}
+	}
+
+	return starting
+}
+
+// flagged returns the feature flag of every service registered WithFlag.
+func (q *Services) flagged() map[string]string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	flags := map[string]string{}
+
+	for _, s := range q.services {
+		if s.Flag != "" {
+			flags[s.Name] = s.Flag
+		}
+	}
+
+	return flags
+}
+
+// flagChanges names the services applyFlags changed: those it launched and
+// stopped, and those it paused and resumed in place.
+type flagChanges struct {
+	started, stopped, paused, resumed []string
+}
+
+// applyFlags brings running services in line with states, returning what it
+// changed and any errors from pausing, resuming or stopping services.
+func (q *Services) applyFlags(startCtx, stopCtx context.Context, states map[string]FlagState) (flagChanges, error) {
+	q.ops.Lock()
+	defer q.ops.Unlock()
+
+	var (
+		changes flagChanges
+		errs    []error
+	)
+
+	for _, s := range q.matching(func(s *service) bool { return s.state != Disabled }) {
+		state, ok := states[s.Name]
+		if !ok {
+			continue
+		}
+
+		switch {
+		case state == FlagRun && q.is(s, (*service).held):
+			launched, err := q.unpause(startCtx, s, func() {})
+			if launched {
+				changes.started = append(changes.started, s.Name)
+			} else {
+				changes.resumed = append(changes.resumed, s.Name)
+			}
+
+			errs = append(errs, err)
+		case state == FlagPause && q.is(s, (*service).launched):
+			stopped, err := q.pause(stopCtx, s)
+			if stopped {
+				changes.stopped = append(changes.stopped, s.Name)
+			} else {
+				changes.paused = append(changes.paused, s.Name)
 			}
 
-			wg.Done()
-		}(s.Start, errChan)
+			errs = append(errs, err)
+		case state == FlagStop && q.is(s, (*service).live):
+			errs = append(errs, q.halt(stopCtx, s))
+			changes.stopped = append(changes.stopped, s.Name)
+		}
+
+		switch state {
+		case FlagPause:
+			q.transition(s, Paused)
+		case FlagStop:
+			q.transition(s, Stopped)
+		case FlagRun:
+		}
+	}
+
+	return changes, errors.Join(errs...)
+}
+
+// launch marks s as running and calls its StartFunc in a new goroutine,
+// calling done once the service is ready or its StartFunc returns. It must be
+// called with q.mu held.
+func (q *Services) launch(ctx context.Context, s *service, done func()) {
+	q.setState(s, Running)
+	s.startedAt = q.clock()
+	s.beat(s.startedAt)
+
+	var settled chan struct{}
+	if s.OneShot {
+		settled = make(chan struct{})
+		s.settled = settled
+	}
+
+	s.ready = false
+	ctx = q.scoped(ctx, s)
+
+	// The service may be adopted by another controller while it runs, so
+	// its Services is looked up afresh rather than captured.
+	go func() {
+		release := sync.OnceFunc(done)
+		defer release()
+
+		readied := make(chan struct{})
+
+		ctx := context.WithValue(ctx, readyKey{}, sync.OnceFunc(func() {
+			s.services().markReady(s)
+			close(readied)
+			release()
+		}))
+
+		err := s.services().delay(ctx, s, release)
+		if err == nil {
+			err = s.services().await(ctx, s)
+		}
+
+		if err == nil {
+			err = s.services().startWithin(ctx, s, readied, release)
+		}
+
+		switch {
+		case errors.Is(err, errHalted), stoppedCleanly(ctx, err):
+			err = nil
+		case err != nil:
+			s.services().failed(s, err)
+		case s.OneShot:
+			s.services().complete(s)
+		default:
+			Ready(ctx)
+		}
+
+		if settled != nil {
+			close(settled)
+		}
+
+		if err != nil {
+			s.services().report(ServiceError{Name: s.Name, Err: err, Time: s.services().clock()})
+		}
+	}()
+}
+
+func (q *Services) clock() time.Time {
+	if q.now == nil {
+		return time.Now()
+	}
+
+	return q.now()
+}
+
+// lookup returns the service registered as name. It must be called with q.mu
+// held.
+func (q *Services) lookup(name string) (*service, error) {
+	for _, s := range q.services {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, name)
+}
+
+// matching returns the services for which keep reports true.
+func (q *Services) matching(keep func(*service) bool) []*service {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var matched []*service
+
+	for _, s := range q.services {
+		if keep(s) {
+			matched = append(matched, s)
+		}
 	}
 
+	return matched
+}
+
+// is reports whether s satisfies cond.
+func (q *Services) is(s *service, cond func(*service) bool) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return cond(s)
+}
+
+// transition moves s to state. It must be called with q.mu not held.
+func (q *Services) transition(s *service, state State) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.setState(s, state)
+}
+
+// failed records err against s and marks it failed, unless it is being
+// stopped, in which case it is left to finish stopping.
+func (q *Services) failed(s *service, err error) {
+	q.mu.Lock()
+	if s.state != Stopping {
+		q.setState(s, Failed)
+	}
+	s.lastErr = err
 	q.mu.Unlock()
+
+	q.notify()
+}
+
+func (q *Services) notify() {
+	if q.changed != nil {
+		q.changed()
+	}
+}
+
+// stop stops every service that was launched and hasn't since been stopped,
+// and returns how many it stopped along with their joined stop errors.
+// Services are stopped in dependency order, with independent services stopped
+// concurrently up to the stop concurrency.
+func (q *Services) stop(ctx context.Context) (int, error) {
+	q.ops.Lock()
+	defer q.ops.Unlock()
+
+	running := q.matching((*service).live)
+
+	var errs []error
+
+	for _, wave := range stopWaves(running) {
+		errs = append(errs, q.haltAll(ctx, wave, q.stopConcurrency))
+	}
+
+	return len(running), errors.Join(errs...)
+}
+
+// haltAll stops services concurrently with at most limit in flight, or all at
+// once when limit is zero or less, and joins their errors. It must be called
+// with q.ops held and q.mu not held.
+func (q *Services) haltAll(ctx context.Context, services []*service, limit int) error {
+	if limit <= 0 {
+		limit = len(services)
+	}
+
+	sem := make(chan struct{}, max(limit, 1))
+	wg := &sync.WaitGroup{}
+	errs := make([]error, len(services))
+
+	for i, s := range services {
+		sem <- struct{}{}
+
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			errs[i] = q.halt(ctx, s)
+		}()
+	}
+
 	wg.Wait()
+
+	return errors.Join(errs...)
 }
 
-func (q *Services) stop(ctx context.Context) int {
+// stopOne stops the named service, reporting false if it was already stopped.
+// The error is either a lookup failure or the error from stopping it.
+func (q *Services) stopOne(ctx context.Context, name string) (bool, error) {
+	q.ops.Lock()
+	defer q.ops.Unlock()
+
+	q.mu.Lock()
+	s, err := q.lookup(name)
+	live := err == nil && s.live()
+	q.mu.Unlock()
+
+	if !live {
+		return false, err
+	}
+
+	return true, q.halt(ctx, s)
+}
+
+// restart stops the named service if needed and starts it again using
+// startCtx. It reports whether the service had previously been stopped. An
+// error from stopping it is returned but doesn't prevent the restart.
+func (q *Services) restart(startCtx, stopCtx context.Context, name string) (bool, error) {
+	q.ops.Lock()
+	defer q.ops.Unlock()
+
+	q.mu.Lock()
+	s, err := q.lookup(name)
+
+	var disabled, wasStopped bool
+	if err == nil {
+		disabled = s.state == Disabled
+		wasStopped = !s.live()
+	}
+	q.mu.Unlock()
+
+	switch {
+	case err != nil:
+		return false, err
+	case disabled:
+		return false, fmt.Errorf("%w: %s", ErrServiceDisabled, name)
+	case !wasStopped:
+		err = q.halt(stopCtx, s)
+	}
+
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
+	q.launch(startCtx, s, func() {})
+	s.restarts++
+	s.lastRestart = s.startedAt
+
+	return wasStopped, err
+}
+
+// handle queues msg on every running service's channel, passes it to every
+// service with a message handler and joins any errors they return.
+func (q *Services) handle(msg Message) error {
+	q.mu.Lock()
+	services := slices.Clone(q.services)
+
+	for _, s := range services {
+		if s.launched() {
+			q.deliver(s, msg)
+		}
+	}
+	q.mu.Unlock()
+
+	var errs []error
+
+	for _, s := range services {
+		if s.Message == nil {
+			continue
+		}
+
+		if err := s.Message(msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// recordHealth stores h as the latest health message for every service named
+// name and reports whether any matched.
+func (q *Services) recordHealth(name string, h HealthMessage) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	found := false
+
 	for _, s := range q.services {
-		s.Stop(ctx)
+		if s.Name == name {
+			s.recordHealth(q.historySize, h)
+			found = true
+		}
 	}
 
-	return len(q.services)
+	return found
 }
 
-func (q *Services) status() {
+func (q *Services) reports(now time.Time) []ServiceReport {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
+	reports := make([]ServiceReport, 0, len(q.services))
 	for _, s := range q.services {
-		s.Status()
+		reports = append(reports, s.report(now))
+	}
+
+	return reports
+}
+
+// launched reports whether the service has been started and not yet stopped.
+func (s *service) launched() bool {
+	return s.state == Running || s.state == Failed
+}
+
+// held reports whether the service is being kept from starting.
+func (s *service) held() bool {
+	return s.state == Paused || s.state == Stopped || s.state == Disabled
+}
+
+// halt calls the service's stop function and marks it stopped, recording and
+// returning any error it reports. It must be called with q.ops held and q.mu
+// not held.
+func (q *Services) halt(ctx context.Context, s *service) error {
+	var err error
+
+	q.halting.add(s.Name)
+	defer q.halting.remove(s.Name)
+
+	q.mu.Lock()
+	q.deliver(s, Stop)
+	q.setState(s, Stopping)
+	s.suspended = false
+	q.mu.Unlock()
+
+	ctx, cancel := q.stopWithin(ctx, s)
+	defer cancel()
+
+	ctx = q.scoped(ctx, s)
+	begin := q.clock()
+
+	switch {
+	case s.StopErr != nil:
+		err = s.StopErr(ctx)
+	case s.Stop != nil:
+		s.Stop(ctx)
 	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.setState(s, Stopped)
+	s.stoppedAt = q.clock()
+	s.stopDuration = s.stoppedAt.Sub(begin)
+	s.upTotal += s.stoppedAt.Sub(s.startedAt)
+	s.ready = false
+
+	if err != nil {
+		err = fmt.Errorf("%s: %w", s.Name, err)
+		s.lastErr = err
+	}
+
+	return err
+}
+
+func (s *service) report(now time.Time) ServiceReport {
+	r := ServiceReport{
+		Name:           s.Name,
+		State:          s.state,
+		StartedAt:      s.startedAt,
+		LastError:      s.lastErr,
+		Restarts:       s.restarts,
+		Ready:          s.ready,
+		HealthFailures: s.healthFailures,
+		Stalled:        s.stalled,
+		LastRestart:    s.lastRestart,
+		StartDuration:  s.startDuration,
+		StopDuration:   s.stopDuration,
+		Labels:         maps.Clone(s.Labels),
+	}
+
+	if s.Details != nil {
+		r.Details = s.Details()
+	}
+
+	if s.lastHealth != nil {
+		h := *s.lastHealth
+		r.LastHealth = &h
+	}
+
+	switch {
+	case s.startedAt.IsZero():
+	case s.state == Running:
+		r.Uptime = now.Sub(s.startedAt)
+		r.TotalUptime = r.Uptime
+	case s.stoppedAt.After(s.startedAt):
+		r.Uptime = s.stoppedAt.Sub(s.startedAt)
+	}
+
+	r.TotalUptime += s.upTotal
+
+	return r
 }
 
 type Service struct {
-	Name   string
-	Start  StartFunc
-	Stop   StopFunc
-	Status StatusFunc
+	Name        string
+	Start       StartFunc
+	Stop        StopFunc
+	StopErr     StopErrFunc
+	Status      StatusFunc
+	StatusCtx   StatusContextFunc
+	HealthCheck HealthCheckFunc
+	Message     MessageFunc
+	Reload      ReloadFunc
+	Drain       DrainFunc
+	Pause       PauseFunc
+	Resume      ResumeFunc
+	Details     DetailsFunc
+	DependsOn   []string
+	Phase       int
+	Flag        string
+	Labels      map[string]string
+	OneShot     bool
+	Await       []string
+	StartDelay  time.Duration
+	StartJitter time.Duration
+	Enabled     func() bool
+	// UnhealthyThreshold is how many consecutive failed status checks
+	// trigger an automatic restart. Zero disables it.
+	UnhealthyThreshold int
+	HeartbeatTimeout   time.Duration
+	RestartOnStall     bool
+	StatusTimeout      time.Duration
+	StartTimeout       time.Duration
+	StopTimeout        time.Duration
 }
@@ -0,0 +1,166 @@
+package controls
+
+import (
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+)
+
+// SignalDispatcher receives OS signals once for the whole process and passes
+// each to its controllers in the order they subscribed, so several
+// controllers don't race to handle the same signal independently.
+type SignalDispatcher struct {
+	mu          sync.Mutex
+	subscribers []chan os.Signal
+	incoming    chan os.Signal
+	done        chan struct{}
+	closed      sync.Once
+}
+
+// NewSignalDispatcher starts relaying sigs, SIGINT and SIGTERM if none are
+// given, to controllers configured WithParentSignals.
+func NewSignalDispatcher(sigs ...os.Signal) *SignalDispatcher {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+
+	d := &SignalDispatcher{
+		incoming: make(chan os.Signal, 1),
+		done:     make(chan struct{}),
+	}
+
+	signal.Notify(d.incoming, sigs...)
+
+	go d.relay()
+
+	return d
+}
+
+func (d *SignalDispatcher) relay() {
+	for {
+		select {
+		case sig := <-d.incoming:
+			d.Dispatch(sig)
+		case <-d.done:
+			return
+		}
+	}
+}
+
+// Dispatch passes sig to every subscribed controller in subscription order,
+// as though the process had received it. A controller that already has a
+// signal waiting doesn't receive another.
+func (d *SignalDispatcher) Dispatch(sig os.Signal) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, ch := range d.subscribers {
+		select {
+		case ch <- sig:
+		default:
+		}
+	}
+}
+
+// Close stops relaying OS signals. Dispatch still works after Close.
+func (d *SignalDispatcher) Close() {
+	d.closed.Do(func() {
+		signal.Stop(d.incoming)
+		close(d.done)
+	})
+}
+
+func (d *SignalDispatcher) subscribe(ch chan os.Signal) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.subscribers = append(d.subscribers, ch)
+}
+
+// unsubscribe stops passing signals to ch. Once it returns, Dispatch no
+// longer sends on ch, so it can be closed.
+func (d *SignalDispatcher) unsubscribe(ch chan os.Signal) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.subscribers = slices.DeleteFunc(d.subscribers, func(s chan os.Signal) bool { return s == ch })
+}
+
+// WithParentSignals takes the controller's signals from d rather than
+// registering for them itself. The controller unsubscribes once it has
+// stopped.
+func WithParentSignals(d *SignalDispatcher) ControllerOpt {
+	return func(c Controllable) {
+		ch := make(chan os.Signal, 1)
+		if !replaceSignals(c, ch) {
+			return
+		}
+
+		d.subscribe(ch)
+
+		configure(c, func(ctrl *Controller) {
+			ctrl.unsubscribe()
+			ctrl.dispatcher, ctrl.subscribed = d, ch
+			ctrl.adoptSignals()
+		})
+	}
+}
+
+// unsubscribe detaches the controller from the dispatcher given to
+// WithParentSignals, if any.
+func (c *Controller) unsubscribe() {
+	if c.dispatcher != nil {
+		c.dispatcher.unsubscribe(c.subscribed)
+		c.dispatcher, c.subscribed = nil, nil
+	}
+}
+
+// WithSignals chooses which signals shut the controller down, in place of
+// SIGINT and SIGTERM. With no signals it is the same as WithoutSignals.
+func WithSignals(sigs ...os.Signal) ControllerOpt {
+	if len(sigs) == 0 {
+		return WithoutSignals()
+	}
+
+	return func(c Controllable) {
+		ch := make(chan os.Signal, 1)
+		if !replaceSignals(c, ch) {
+			return
+		}
+
+		signal.Notify(ch, sigs...)
+
+		configure(c, func(ctrl *Controller) {
+			ctrl.adoptSignals()
+		})
+	}
+}
+
+// replaceSignals gives c the signals channel ch, nil for none, and stops
+// signal delivery to the channel it replaces. It reports false, leaving the
+// old channel in place, when c rejects the change because it has started.
+func replaceSignals(c Controllable, ch chan os.Signal) bool {
+	old := c.Signals()
+	c.SetSignalsChannel(ch)
+
+	if c.Signals() != (chan<- os.Signal)(ch) {
+		return false
+	}
+
+	if old != nil {
+		signal.Stop(old)
+	}
+
+	return true
+}
+
+// adoptSignals marks the signals channel as created by the controller, so it
+// is closed once the controller has stopped.
+func (c *Controller) adoptSignals() {
+	c.channelsMutex.Lock()
+	defer c.channelsMutex.Unlock()
+
+	c.owned["signals"] = true
+}
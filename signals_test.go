@@ -0,0 +1,118 @@
+package controls_test
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignalDispatcher(t *testing.T) {
+	d := controls.NewSignalDispatcher(syscall.SIGUSR2)
+	defer d.Close()
+
+	stopped := make(chan string, 2)
+
+	newChild := func(name string) *controls.Controller {
+		c := controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithParentSignals(d),
+		)
+		c.Register(name,
+			controls.WithStart(noopStart),
+			controls.WithStop(func(_ context.Context) { stopped <- name }),
+		)
+		c.Start()
+
+		return c
+	}
+
+	first := newChild("first")
+	second := newChild("second")
+
+	d.Dispatch(syscall.SIGTERM)
+
+	assert.Eventually(t, first.IsStopped, time.Second, time.Millisecond)
+	assert.Eventually(t, second.IsStopped, time.Second, time.Millisecond)
+	assert.ElementsMatch(t, []string{"first", "second"}, []string{<-stopped, <-stopped})
+	assert.Equal(t, controls.StopSignalled, first.StopReason())
+	assert.Equal(t, controls.StopSignalled, second.StopReason())
+}
+
+func TestSignalDispatcher_Unsubscribe(t *testing.T) {
+	d := controls.NewSignalDispatcher(syscall.SIGUSR2)
+	defer d.Close()
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithParentSignals(d),
+	)
+	c.Register("svc", controls.WithStart(noopStart))
+	c.Start()
+
+	assert.NoError(t, c.Stop())
+	assert.NoError(t, c.Close())
+
+	d.Dispatch(syscall.SIGTERM)
+	assert.Empty(t, c.Signals(), "the controller unsubscribed once it had stopped")
+}
+
+func TestSignalDispatcher_Relay(t *testing.T) {
+	d := controls.NewSignalDispatcher(syscall.SIGUSR2)
+	defer d.Close()
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithParentSignals(d),
+	)
+	c.Register("svc",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+	c.Start()
+
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	assert.Eventually(t, c.IsStopped, time.Second, time.Millisecond)
+}
+
+func TestController_WithSignals(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithSignals(syscall.SIGUSR1),
+	)
+	c.Register("svc",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+	c.Start()
+
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, c.IsStopped, time.Second, time.Millisecond)
+	assert.Equal(t, controls.StopSignalled, c.StopReason())
+
+	off := controls.NewController(context.Background(), controls.WithSignals())
+	assert.Nil(t, off.Signals())
+}
+
+func TestController_WithSignalsAfterStart(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithSignals(syscall.SIGUSR1),
+	)
+	c.Register("svc", controls.WithStart(noopStart))
+	c.Start()
+
+	sigs := c.Signals()
+
+	controls.WithSignals(syscall.SIGUSR2)(c)
+	controls.WithoutSignals()(c)
+	assert.Equal(t, sigs, c.Signals(), "signals swapped while running")
+
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, c.IsStopped, time.Second, time.Millisecond)
+	assert.Equal(t, controls.StopSignalled, c.StopReason())
+}
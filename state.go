@@ -0,0 +1,197 @@
+package controls
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+)
+
+// ErrInvalidTransition is returned by SetState when the controller can't move
+// from its current state to the one requested.
+var ErrInvalidTransition = errors.New("invalid state transition")
+
+// transitions lists the states the controller may move to from each state.
+// Stopped is final: a stopped controller can't be started again.
+var transitions = map[State][]State{
+	Unknown:  {Starting, Running, Stopping, Stopped},
+	Starting: {Running, Degraded, Failed, Stopping, Stopped},
+	Running:  {Degraded, Failed, Paused, Draining, Stopping, Stopped},
+	Degraded: {Running, Failed, Paused, Draining, Stopping, Stopped},
+	Failed:   {Running, Degraded, Draining, Stopping, Stopped},
+	Paused:   {Running, Stopping, Stopped},
+	Draining: {Stopping, Stopped},
+	Stopping: {Stopped},
+}
+
+// up lists the states in which the controller's services have been started
+// and not yet stopped.
+var up = []State{Running, Degraded, Failed}
+
+// StateListener is called with the controller's previous and new state each
+// time it changes.
+type StateListener func(from, to State)
+
+// WithStateListener calls fn on every change of the controller's state, in
+// the goroutine making the change, so it must return promptly.
+func WithStateListener(fn StateListener) ControllerOpt {
+	return WithEventHandler(func(e Event) {
+		if e.Type == StateChanged && !e.Synthetic {
+			fn(e.Previous, e.State)
+		}
+	})
+}
+
+// atomicState holds the controller's state so that GetState, which services
+// may call in tight loops, never waits on stateMutex. Writers still take the
+// mutex, to keep transitions and their history in step. Its zero value holds
+// Unknown.
+type atomicState struct {
+	v atomic.Value
+}
+
+func (a *atomicState) load() State {
+	s, ok := a.v.Load().(State)
+	if !ok {
+		return Unknown
+	}
+
+	return s
+}
+
+func (a *atomicState) store(s State) {
+	a.v.Store(s)
+}
+
+// CanTransition reports whether the controller may move from one state to
+// another. Staying in the same state is always allowed.
+func CanTransition(from, to State) bool {
+	return from == to || slices.Contains(transitions[from], to)
+}
+
+func (c *Controller) SetState(state State) error {
+	c.stateMutex.Lock()
+	from := c.state.load()
+
+	if !CanTransition(from, state) {
+		c.stateMutex.Unlock()
+
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, state)
+	}
+
+	if from != state {
+		c.history.record("", from, state)
+	}
+
+	c.state.store(state)
+	c.stateMutex.Unlock()
+
+	if from != state {
+		c.changes.notify()
+		c.emit(Event{Type: StateChanged, State: state, Previous: from})
+	}
+
+	return nil
+}
+
+// moveTo sets the controller's state, logging a rejected transition.
+func (c *Controller) moveTo(state State) bool {
+	if err := c.SetState(state); err != nil {
+		c.log().Error(err.Error())
+
+		return false
+	}
+
+	return true
+}
+
+// swapState moves to state if the controller is currently in one of from,
+// reporting whether it did.
+func (c *Controller) swapState(state State, from ...State) bool {
+	c.stateMutex.Lock()
+	prev := c.state.load()
+
+	if prev == state || !slices.Contains(from, prev) {
+		c.stateMutex.Unlock()
+
+		return false
+	}
+
+	c.history.record("", prev, state)
+	c.state.store(state)
+	c.stateMutex.Unlock()
+
+	c.changes.notify()
+	c.emit(Event{Type: StateChanged, State: state, Previous: prev})
+
+	return true
+}
+
+func (c *Controller) IsStarting() bool {
+	return c.GetState() == Starting
+}
+
+// IsDegraded reports whether some, but not all, services have failed or are
+// unhealthy.
+func (c *Controller) IsDegraded() bool {
+	return c.GetState() == Degraded
+}
+
+// IsFailed reports whether every started service has failed.
+func (c *Controller) IsFailed() bool {
+	return c.GetState() == Failed
+}
+
+// isUp reports whether the controller is Running, Degraded or Failed.
+func (c *Controller) isUp() bool {
+	return slices.Contains(up, c.GetState())
+}
+
+// assess moves an up controller to Running, Degraded or Failed to reflect
+// its services.
+func (c *Controller) assess() {
+	c.swapState(c.services.condition(), up...)
+}
+
+// condition derives the controller's state from its launched services: Failed
+// if they all failed, Degraded if any failed, stalled or reported degraded or
+// unhealthy health, otherwise Running.
+func (q *Services) condition() State {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var launched, failed, impaired int
+
+	for _, s := range q.services {
+		if !s.launched() {
+			continue
+		}
+
+		launched++
+
+		switch {
+		case s.state == Failed:
+			failed++
+		case s.stalled:
+			impaired++
+		case s.lastHealth != nil && (s.lastHealth.Status == HealthDegraded || s.lastHealth.Status == Unhealthy):
+			impaired++
+		}
+	}
+
+	switch {
+	case launched > 0 && failed == launched:
+		return Failed
+	case failed+impaired > 0:
+		return Degraded
+	default:
+		return Running
+	}
+}
+
+// servicesChanged is called when a service becomes ready, completes or
+// fails.
+func (c *Controller) servicesChanged() {
+	c.changes.notify()
+	c.assess()
+}
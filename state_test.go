@@ -0,0 +1,174 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_Condition(t *testing.T) {
+	var failing atomic.Bool
+
+	failing.Store(true)
+
+	ready := make(chan struct{})
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("api",
+		controls.WithStart(func(ctx context.Context) error {
+			<-ready
+			controls.Ready(ctx)
+
+			return nil
+		}),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+	c.Register("worker",
+		controls.WithStart(func(_ context.Context) error {
+			if failing.Load() {
+				return errors.New("boom") //nolint:err113
+			}
+
+			return nil
+		}),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	go c.Start()
+
+	assert.Eventually(t, c.IsStarting, time.Second, time.Millisecond)
+	close(ready)
+	assert.Eventually(t, c.IsDegraded, time.Second, time.Millisecond)
+
+	failing.Store(false)
+	require.NoError(t, c.RestartService("worker"))
+	assert.Eventually(t, c.IsRunning, time.Second, time.Millisecond)
+
+	c.RecordHealth("api", controls.HealthMessage{Status: controls.Unhealthy})
+	assert.True(t, c.IsDegraded())
+
+	c.RecordHealth("api", controls.HealthMessage{Status: controls.Healthy})
+	assert.True(t, c.IsRunning())
+
+	require.NoError(t, c.Stop())
+}
+
+func TestController_AllFailed(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("broken",
+		controls.WithStart(func(_ context.Context) error { return errors.New("boom") }), //nolint:err113
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	c.Start()
+	assert.True(t, c.IsFailed())
+
+	require.NoError(t, c.Stop())
+}
+
+func TestController_WithStateListener(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		transitions []string
+	)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithStateListener(func(from, to controls.State) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			transitions = append(transitions, string(from)+"->"+string(to))
+		}),
+	)
+	c.Register("svc",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	c.Start()
+	require.NoError(t, c.Stop())
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{
+		"unknown->starting", "starting->running", "running->stopping", "stopping->stopped",
+	}, transitions)
+}
+
+func BenchmarkController_GetState(b *testing.B) {
+	c := controls.NewController(context.Background(), controls.WithoutSignals())
+	require.NoError(b, c.SetState(controls.Running))
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = c.GetState()
+		}
+	})
+}
+
+func BenchmarkController_IsRunning(b *testing.B) {
+	c := controls.NewController(context.Background(), controls.WithoutSignals())
+	require.NoError(b, c.SetState(controls.Running))
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = c.IsRunning()
+		}
+	})
+}
+
+// BenchmarkController_IsRunningWhileTransitioning reads the state while
+// another goroutine keeps moving the controller between Running and Degraded.
+func BenchmarkController_IsRunningWhileTransitioning(b *testing.B) {
+	c := controls.NewController(context.Background(), controls.WithoutSignals())
+	require.NoError(b, c.SetState(controls.Running))
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			state := controls.Running
+			if i%2 == 0 {
+				state = controls.Degraded
+			}
+
+			_ = c.SetState(state)
+		}
+	}()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = c.IsRunning()
+		}
+	})
+	b.StopTimer()
+
+	close(done)
+	<-stopped
+}
@@ -0,0 +1,124 @@
+// Package statsd sends a controls.Controller's metrics to a StatsD or
+// DogStatsD agent over UDP, for use with controls.WithMetrics.
+package statsd
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/phpboyscout/controls"
+)
+
+const (
+	// DefaultAddr is the local agent.
+	DefaultAddr = "127.0.0.1:8125"
+	// DefaultPrefix namespaces every metric.
+	DefaultPrefix = "controls."
+)
+
+var _ controls.MetricsSink = (*Sink)(nil)
+
+type Option func(*Sink)
+
+// Sink writes each metric as a single StatsD datagram. By default it uses the
+// DogStatsD format, which carries tags after "|#". Write errors are ignored,
+// as is usual for StatsD.
+type Sink struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	prefix string
+	tags   []string
+	plain  bool
+}
+
+// WithPrefix overrides DefaultPrefix.
+func WithPrefix(prefix string) Option {
+	return func(s *Sink) {
+		s.prefix = prefix
+	}
+}
+
+// WithTags adds tags, "key:value" pairs, to every metric.
+func WithTags(tags ...string) Option {
+	return func(s *Sink) {
+		s.tags = append(s.tags, tags...)
+	}
+}
+
+// WithoutTags writes plain StatsD, which has no tags, by appending each tag's
+// value to the metric name: service.starts tagged service:api becomes
+// service.starts.api.
+func WithoutTags() Option {
+	return func(s *Sink) {
+		s.plain = true
+	}
+}
+
+// New returns a Sink sending to the agent at addr, DefaultAddr when empty.
+func New(addr string, opts ...Option) (*Sink, error) {
+	if addr == "" {
+		addr = DefaultAddr
+	}
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Sink{conn: conn, prefix: DefaultPrefix}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
+}
+
+// Count sends a counter.
+func (s *Sink) Count(name string, value int64, tags ...string) {
+	s.send(name, strconv.FormatInt(value, 10), "c", tags)
+}
+
+// Gauge sends a gauge.
+func (s *Sink) Gauge(name string, value float64, tags ...string) {
+	s.send(name, strconv.FormatFloat(value, 'f', -1, 64), "g", tags)
+}
+
+// Close closes the connection.
+func (s *Sink) Close() error {
+	return s.conn.Close()
+}
+
+func (s *Sink) send(name, value, kind string, tags []string) {
+	var b strings.Builder
+
+	b.WriteString(s.prefix)
+	b.WriteString(name)
+
+	all := append(s.tags[:len(s.tags):len(s.tags)], tags...)
+
+	if s.plain {
+		for _, tag := range all {
+			_, v, _ := strings.Cut(tag, ":")
+			b.WriteString(".")
+			b.WriteString(v)
+		}
+	}
+
+	b.WriteString(":")
+	b.WriteString(value)
+	b.WriteString("|")
+	b.WriteString(kind)
+
+	if !s.plain && len(all) > 0 {
+		b.WriteString("|#")
+		b.WriteString(strings.Join(all, ","))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, _ = s.conn.Write([]byte(b.String()))
+}
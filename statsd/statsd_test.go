@@ -0,0 +1,58 @@
+package statsd_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls/statsd"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listen(t *testing.T) (net.PacketConn, func() string) {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pc.Close() })
+
+	return pc, func() string {
+		buf := make([]byte, 1024)
+
+		require.NoError(t, pc.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err := pc.ReadFrom(buf)
+		require.NoError(t, err)
+
+		return string(buf[:n])
+	}
+}
+
+func TestSink_DogStatsD(t *testing.T) {
+	pc, read := listen(t)
+
+	s, err := statsd.New(pc.LocalAddr().String(), statsd.WithTags("env:prod"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	s.Count("service.starts", 1, "service:api")
+	assert.Equal(t, "controls.service.starts:1|c|#env:prod,service:api", read())
+
+	s.Gauge("controller.uptime_seconds", 1.5)
+	assert.Equal(t, "controls.controller.uptime_seconds:1.5|g|#env:prod", read())
+}
+
+func TestSink_Plain(t *testing.T) {
+	pc, read := listen(t)
+
+	s, err := statsd.New(pc.LocalAddr().String(), statsd.WithPrefix("app."), statsd.WithoutTags())
+	require.NoError(t, err)
+	defer s.Close()
+
+	s.Gauge("service.state", 1, "service:api", "state:running")
+	assert.Equal(t, "app.service.state.api.running:1|g", read())
+
+	s.Count("service.errors", 2)
+	assert.Equal(t, "app.service.errors:2|c", read())
+}
@@ -0,0 +1,230 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultStatusTimeout bounds how long a single context-aware status check may
+// run before the controller stops waiting for it.
+const DefaultStatusTimeout = 5 * time.Second
+
+// ErrStatusTimeout is reported when a status check outlives its timeout.
+var ErrStatusTimeout = errors.New("status check timed out")
+
+type StatusContextFunc func(context.Context)
+type HealthCheckFunc func(context.Context) error
+
+// WithStatusContext sets a status check that is given a context cancelled
+// once the check's timeout elapses. It takes precedence over WithStatus.
+func WithStatusContext(fn StatusContextFunc) ServiceOption {
+	return func(s *Service) {
+		s.StatusCtx = fn
+	}
+}
+
+// WithHealthCheck sets a context-aware status check that reports failure by
+// returning an error. Its outcome is recorded as the service's health, and
+// repeated failures can restart the service; see WithRestartOnUnhealthy. It
+// takes precedence over WithStatusContext and WithStatus.
+func WithHealthCheck(fn HealthCheckFunc) ServiceOption {
+	return func(s *Service) {
+		s.HealthCheck = fn
+	}
+}
+
+// WithStatusCheckTimeout overrides DefaultStatusTimeout for the service's
+// context-aware check.
+func WithStatusCheckTimeout(d time.Duration) ServiceOption {
+	return func(s *Service) {
+		s.StatusTimeout = d
+	}
+}
+
+type timeoutFunc func(context.Context, time.Duration) (context.Context, context.CancelFunc)
+
+// check runs the service's context-aware check under a timeout, its own or
+// else fallback. A check that ignores its context is abandoned, left to
+// finish in the background, and finished is called once it returns.
+func (s *service) check(ctx context.Context, withTimeout timeoutFunc, fallback time.Duration, finished func()) error {
+	d := s.StatusTimeout
+	if d <= 0 {
+		d = fallback
+	}
+
+	if d <= 0 {
+		d = DefaultStatusTimeout
+	}
+
+	ctx, cancel := withTimeout(ctx, d)
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		defer finished()
+
+		if s.HealthCheck != nil {
+			done <- s.HealthCheck(ctx)
+
+			return
+		}
+
+		s.StatusCtx(ctx)
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.Name, err)
+		}
+
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", s.Name, ErrStatusTimeout)
+	}
+}
+
+// checkFailure is a failed status check, and whether the service has now
+// failed enough consecutive checks to be restarted.
+type checkFailure struct {
+	name    string
+	err     error
+	restart bool
+}
+
+// status runs the context-aware checks of the running services concurrently,
+// each under its own timeout, so one stuck check can't hold up the others,
+// and calls plain StatusFuncs in order as before. The services lock isn't
+// held while checks run. A check abandoned by an earlier status that still
+// hasn't returned isn't run again, and times out at once. It returns the
+// checks that failed, and the health recorded for every context-aware check.
+func (q *Services) status(withTimeout timeoutFunc) ([]checkFailure, []HealthMessage) {
+	q.mu.Lock()
+
+	var (
+		checks, plain []*service
+		busy          []bool
+	)
+
+	for _, s := range q.services {
+		if s.state != Running {
+			continue
+		}
+
+		switch {
+		case s.HealthCheck != nil, s.StatusCtx != nil:
+			checks = append(checks, s)
+			busy = append(busy, s.checking)
+			s.checking = true
+		case s.Status != nil:
+			plain = append(plain, s)
+		}
+	}
+
+	q.mu.Unlock()
+
+	wg := &sync.WaitGroup{}
+	errs := make([]error, len(checks))
+
+	for i, s := range checks {
+		if busy[i] {
+			errs[i] = fmt.Errorf("%s: %w: previous check still running", s.Name, ErrStatusTimeout)
+
+			continue
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			errs[i] = s.check(q.scoped(context.Background(), s), withTimeout, q.statusTimeout, func() {
+				owner := s.services()
+
+				owner.mu.Lock()
+				defer owner.mu.Unlock()
+
+				s.checking = false
+			})
+		}()
+	}
+
+	for _, s := range plain {
+		s.Status()
+	}
+
+	wg.Wait()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var failures []checkFailure
+
+	health := make([]HealthMessage, 0, len(checks))
+
+	for i, s := range checks {
+		if f, failed := q.checked(s, errs[i]); failed {
+			failures = append(failures, f)
+		}
+
+		health = append(health, *s.lastHealth)
+	}
+
+	return failures, health
+}
+
+// checked records the outcome of a check on s. It must be called with q.mu
+// held.
+func (q *Services) checked(s *service, err error) (checkFailure, bool) {
+	h := HealthMessage{ServiceName: s.Name, Status: Healthy, Timestamp: q.clock()}
+	if err != nil {
+		h.Status = Unhealthy
+		h.Message = err.Error()
+	}
+
+	s.recordHealth(q.historySize, h)
+
+	if err == nil {
+		s.consecutiveFailures = 0
+		s.beat(q.clock())
+
+		return checkFailure{}, false
+	}
+
+	s.healthFailures++
+	s.consecutiveFailures++
+
+	f := checkFailure{name: s.Name, err: err}
+
+	if s.UnhealthyThreshold > 0 && s.consecutiveFailures >= s.UnhealthyThreshold && s.state == Running {
+		s.consecutiveFailures = 0
+		f.restart = true
+	}
+
+	return f, true
+}
+
+// Status runs every service's status check through the control loop and
+// returns a Report taken once they have finished, along with the checks'
+// failures joined. It gives up when ctx is done, returning its cause.
+func (c *Controller) Status(ctx context.Context) (Report, error) {
+	reply := make(chan error, 1)
+
+	select {
+	case c.queries <- reply:
+	case <-ctx.Done():
+		return Report{}, context.Cause(ctx)
+	}
+
+	select {
+	case err := <-reply:
+		return c.Report(), err
+	case <-ctx.Done():
+		return Report{}, context.Cause(ctx)
+	}
+}
@@ -0,0 +1,168 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_StatusTimeout(t *testing.T) {
+	var (
+		buf      logBuffer
+		healthy  atomic.Int64
+		deadline = make(chan error, 1)
+		release  = make(chan struct{})
+	)
+
+	defer close(release)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+		controls.WithoutSignals(),
+	)
+	c.Register("stuck",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithStatusContext(func(_ context.Context) { <-release }),
+		controls.WithStatusCheckTimeout(10*time.Millisecond),
+	)
+	c.Register("probe",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithStatusContext(func(ctx context.Context) {
+			<-ctx.Done()
+			deadline <- ctx.Err()
+		}),
+		controls.WithStatusCheckTimeout(10*time.Millisecond),
+	)
+	c.Register("healthy",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithStatus(func() { healthy.Add(1) }),
+	)
+
+	c.Start()
+
+	c.Messages() <- controls.Status
+	c.Messages() <- controls.Status
+
+	assert.Eventually(t, func() bool { return healthy.Load() == 2 }, time.Second, time.Millisecond)
+	require.ErrorIs(t, <-deadline, context.DeadlineExceeded)
+
+	require.NoError(t, c.Stop())
+	assert.Contains(t, buf.String(), "stuck: "+controls.ErrStatusTimeout.Error())
+}
+
+func TestController_Status(t *testing.T) {
+	errDown := errors.New("database unreachable") //nolint:err113
+
+	var failing atomic.Bool
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("db",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithHealthCheck(func(_ context.Context) error {
+			if failing.Load() {
+				return errDown
+			}
+
+			return nil
+		}),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.Status(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded, "nothing answers before Start")
+
+	c.Start()
+
+	r, err := c.Status(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, r.Services[0].LastHealth)
+	assert.Equal(t, controls.Healthy, r.Services[0].LastHealth.Status)
+
+	failing.Store(true)
+
+	r, err = c.Status(context.Background())
+	require.ErrorIs(t, err, errDown)
+	assert.Equal(t, controls.Unhealthy, r.Services[0].LastHealth.Status)
+	assert.Equal(t, 1, r.Services[0].HealthFailures)
+	assert.Equal(t, controls.Degraded, r.State)
+
+	require.NoError(t, c.Stop())
+}
+
+func TestController_StatusSkipsHungCheck(t *testing.T) {
+	var calls atomic.Int64
+
+	release := make(chan struct{})
+	defer close(release)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("hung",
+		controls.WithStart(noopStart),
+		controls.WithHealthCheck(func(_ context.Context) error {
+			calls.Add(1)
+			<-release
+
+			return nil
+		}),
+		controls.WithStatusCheckTimeout(5*time.Millisecond),
+	)
+
+	require.NoError(t, c.Start())
+
+	for range 3 {
+		_, err := c.Status(context.Background())
+		require.ErrorIs(t, err, controls.ErrStatusTimeout)
+	}
+
+	assert.Equal(t, int64(1), calls.Load())
+
+	require.NoError(t, c.Stop())
+}
+
+func TestController_StatusSkipsStoppedServices(t *testing.T) {
+	var calls atomic.Int64
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("api", controls.WithStart(noopStart))
+	c.Register("db",
+		controls.WithStart(noopStart),
+		controls.WithHealthCheck(func(_ context.Context) error {
+			calls.Add(1)
+
+			return nil
+		}),
+	)
+
+	require.NoError(t, c.Start())
+	require.NoError(t, c.StopService("db"))
+
+	_, err := c.Status(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, calls.Load())
+	assert.Nil(t, c.Report().Services[1].LastHealth)
+
+	require.NoError(t, c.Stop())
+}
@@ -0,0 +1,136 @@
+package controls
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrStopping is the cause of the context given to start functions once the
+// controller begins stopping.
+var ErrStopping = errors.New("controller is stopping")
+
+// StopReason records why the controller began shutting down.
+type StopReason string
+
+const (
+	// NotStopped is reported until a shutdown begins.
+	NotStopped           StopReason = ""
+	StopRequested        StopReason = "requested"
+	StopSignalled        StopReason = "signal"
+	StopContextCancelled StopReason = "context_cancelled"
+	StopServiceFailed    StopReason = "service_failed"
+	StopUpgraded         StopReason = "upgraded"
+	StopDrained          StopReason = "drained"
+)
+
+// WithStopOnError shuts the controller down when a service reports an error,
+// recording StopServiceFailed as the reason.
+func WithStopOnError() ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.stopOnError = true
+		})
+	}
+}
+
+// StopReason reports why the controller began shutting down. The first
+// reason wins: a signal arriving during a requested Stop doesn't replace it.
+func (c *Controller) StopReason() StopReason {
+	c.shutdown.mu.Lock()
+	defer c.shutdown.mu.Unlock()
+
+	return c.shutdown.reason
+}
+
+// ExitCode suggests a process exit code for the shutdown: 1 when it was
+// caused by a failing service or services didn't stop cleanly, otherwise 0.
+func (c *Controller) ExitCode() int {
+	if c.StopReason() == StopServiceFailed {
+		return 1
+	}
+
+	select {
+	case <-c.shutdown.done:
+		if c.shutdown.err != nil {
+			return 1
+		}
+	default:
+	}
+
+	return 0
+}
+
+// stopForFailure records StopServiceFailed and err straight away, so a Stop
+// racing it can't claim the shutdown, then requests the stop once Start has
+// finished: a service can fail before the controller is Running.
+func (c *Controller) stopForFailure(err error) {
+	c.shutdown.stopFor(StopServiceFailed, err)
+
+	go func() {
+		for {
+			next := c.changes.next()
+
+			switch c.GetState() {
+			case Running, Degraded, Failed:
+				c.requestStop(StopServiceFailed, internal)
+
+				return
+			case Stopping, Stopped:
+				return
+			}
+
+			<-next
+		}
+	}()
+}
+
+// stopFor records reason and the error that caused it, unless a reason has
+// already been recorded.
+func (s *shutdown) stopFor(reason StopReason, cause error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.reason == NotStopped {
+		s.reason = reason
+		s.cause = cause
+	}
+}
+
+// request reports whether this is the first request to stop.
+func (s *shutdown) request() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	first := !s.requested
+	s.requested = true
+
+	return first
+}
+
+// begin reports whether the stop sequence has yet to run, and marks it run.
+func (s *shutdown) begin() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	first := !s.begun
+	s.begun = true
+
+	return first
+}
+
+// beginStopping cancels the context given to start functions, so services
+// blocked on it learn of the shutdown before their stop functions run.
+func (c *Controller) beginStopping() {
+	c.cancelRun(ErrStopping)
+	c.cancelAdopted(ErrStopping)
+}
+
+// stoppedCleanly reports whether err is a start function giving up because
+// the controller began stopping, rather than a failure.
+func stoppedCleanly(ctx context.Context, err error) bool {
+	if !errors.Is(context.Cause(ctx), ErrStopping) {
+		return false
+	}
+
+	return errors.Is(err, context.Canceled) || errors.Is(err, ErrStopping)
+}
@@ -0,0 +1,198 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_StopReason(t *testing.T) {
+	newController := func(ctx context.Context, opts ...controls.ControllerOpt) *controls.Controller {
+		c := controls.NewController(ctx, append([]controls.ControllerOpt{
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+		}, opts...)...)
+		c.Register("svc", controls.WithStart(noopStart), controls.WithStop(func(_ context.Context) {}))
+
+		return c
+	}
+
+	t.Run("requested", func(t *testing.T) {
+		c := newController(context.Background())
+		c.Start()
+		assert.Equal(t, controls.NotStopped, c.StopReason())
+
+		require.NoError(t, c.Stop())
+		assert.Equal(t, controls.StopRequested, c.StopReason())
+		assert.Equal(t, 0, c.ExitCode())
+	})
+
+	t.Run("signal", func(t *testing.T) {
+		signals := make(chan os.Signal, 1)
+		c := newController(context.Background())
+		c.SetSignalsChannel(signals)
+		c.Start()
+
+		signals <- syscall.SIGTERM
+
+		assert.Eventually(t, c.IsStopped, time.Second, time.Millisecond)
+		assert.Equal(t, controls.StopSignalled, c.StopReason())
+		assert.Equal(t, 0, c.ExitCode())
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c := newController(ctx)
+		c.Start()
+
+		cancel()
+
+		assert.Eventually(t, c.IsStopped, time.Second, time.Millisecond)
+		assert.Equal(t, controls.StopContextCancelled, c.StopReason())
+	})
+
+	t.Run("service failed", func(t *testing.T) {
+		c := newController(context.Background(), controls.WithStopOnError())
+		c.Register("broken",
+			controls.WithStart(func(_ context.Context) error { return errors.New("boom") }), //nolint:err113
+			controls.WithStop(func(_ context.Context) {}),
+		)
+		c.Start()
+
+		assert.Eventually(t, c.IsStopped, time.Second, time.Millisecond)
+		assert.Equal(t, controls.StopServiceFailed, c.StopReason())
+		assert.Equal(t, 1, c.ExitCode())
+	})
+}
+
+func TestController_StopCancelsStartContext(t *testing.T) {
+	cause := make(chan error, 1)
+	observed := make(chan bool, 1)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("poller",
+		controls.WithStart(func(ctx context.Context) error {
+			controls.Ready(ctx)
+			<-ctx.Done()
+			cause <- context.Cause(ctx)
+
+			return ctx.Err()
+		}),
+		controls.WithStop(func(_ context.Context) {
+			select {
+			case err := <-cause:
+				cause <- err
+				observed <- true
+			case <-time.After(time.Second):
+				observed <- false
+			}
+		}),
+	)
+
+	c.Start()
+	require.NoError(t, c.Stop())
+
+	assert.True(t, <-observed, "the start context is cancelled before the stop function runs")
+	require.ErrorIs(t, <-cause, controls.ErrStopping)
+	assert.Empty(t, c.Errs(), "returning the cancelled context's error is not a failure")
+}
+
+func TestController_CoalescesStopRequests(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+
+	var stops atomic.Int32
+
+	c := controls.NewController(ctx,
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSecondSignalTermination(),
+	)
+	c.SetSignalsChannel(signals)
+	c.Register("svc",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {
+			stops.Add(1)
+			time.Sleep(10 * time.Millisecond)
+		}),
+	)
+	c.Start()
+
+	var wg sync.WaitGroup
+
+	for range 5 {
+		wg.Go(func() { assert.NoError(t, c.Stop()) })
+	}
+
+	signals <- syscall.SIGTERM
+	cancel()
+
+	wg.Wait()
+	require.NoError(t, c.Close())
+
+	assert.Equal(t, int32(1), stops.Load())
+
+	var stopping int
+
+	for _, tr := range c.StateHistory() {
+		if tr.Service == "" && tr.To == controls.Stopping {
+			stopping++
+		}
+	}
+
+	assert.Equal(t, 1, stopping)
+	assert.Equal(t, controls.Stopped, c.GetState())
+}
+
+func TestController_WaitReturnsCause(t *testing.T) {
+	errBoom := errors.New("boom")         //nolint:err113
+	errShutdown := errors.New("shutdown") //nolint:err113
+
+	quiet := []controls.ControllerOpt{controls.WithLogger(discardLogger()), controls.WithoutSignals()}
+
+	t.Run("service failure", func(t *testing.T) {
+		c := controls.NewController(context.Background(), append(quiet, controls.WithStopOnError())...)
+		c.Register("broken",
+			controls.WithStart(func(_ context.Context) error { return errBoom }),
+			controls.WithStop(func(_ context.Context) {}),
+		)
+		require.NoError(t, c.Start())
+
+		err := c.Wait()
+		require.ErrorIs(t, err, errBoom)
+
+		var se controls.ServiceError
+		require.ErrorAs(t, err, &se)
+		assert.Equal(t, "broken", se.Name)
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		c := controls.NewController(ctx, quiet...)
+		c.Register("api", controls.WithStart(noopStart), controls.WithStop(func(_ context.Context) {}))
+		require.NoError(t, c.Start())
+
+		cancel(errShutdown)
+		require.ErrorIs(t, c.Wait(), errShutdown)
+	})
+
+	t.Run("requested", func(t *testing.T) {
+		c := controls.NewController(context.Background(), quiet...)
+		c.Register("api", controls.WithStart(noopStart), controls.WithStop(func(_ context.Context) {}))
+		require.NoError(t, c.Start())
+		require.NoError(t, c.Stop())
+		require.NoError(t, c.Wait())
+	})
+}
@@ -0,0 +1,97 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrStartTimeout is reported when a service isn't ready within its start
+// timeout.
+var ErrStartTimeout = errors.New("service start timed out")
+
+// WithStartTimeout gives every service d to become ready once its StartFunc
+// is called. A service that takes longer fails with ErrStartTimeout and its
+// start context is cancelled. Override it per service with
+// WithServiceStartTimeout. By default there is no limit.
+func WithStartTimeout(d time.Duration) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.services.startTimeout = d
+		})
+	}
+}
+
+// WithStatusTimeout replaces DefaultStatusTimeout for every service's
+// context-aware status check. Override it per service with
+// WithStatusCheckTimeout.
+func WithStatusTimeout(d time.Duration) ControllerOpt {
+	return func(c Controllable) {
+		configure(c, func(ctrl *Controller) {
+			ctrl.services.statusTimeout = d
+		})
+	}
+}
+
+// WithServiceStartTimeout overrides WithStartTimeout for the service.
+func WithServiceStartTimeout(d time.Duration) ServiceOption {
+	return func(s *Service) {
+		s.StartTimeout = d
+	}
+}
+
+// WithServiceStopTimeout gives the service d to stop in place of the
+// controller's shutdown timeout, whether it is longer or shorter.
+func WithServiceStopTimeout(d time.Duration) ServiceOption {
+	return func(s *Service) {
+		s.StopTimeout = d
+	}
+}
+
+// startWithin calls the service's StartFunc, failing it with ErrStartTimeout
+// if readied isn't closed within its start timeout. release stops Start
+// waiting for the service once it has timed out.
+func (q *Services) startWithin(ctx context.Context, s *service, readied <-chan struct{}, release func()) error {
+	d := s.StartTimeout
+	if d <= 0 {
+		d = q.startTimeout
+	}
+
+	if d <= 0 {
+		return s.Start(ctx)
+	}
+
+	after := time.After
+	if q.after != nil {
+		after = q.after
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	go func() {
+		select {
+		case <-readied:
+		case <-ctx.Done():
+		case <-after(d):
+			cancel(ErrStartTimeout)
+			release()
+		}
+	}()
+
+	err := s.Start(ctx)
+	if cause := context.Cause(ctx); errors.Is(cause, ErrStartTimeout) {
+		return cause
+	}
+
+	return err
+}
+
+// stopWithin bounds ctx by the service's own stop timeout, if it has one.
+func (q *Services) stopWithin(ctx context.Context, s *service) (context.Context, context.CancelFunc) {
+	if s.StopTimeout <= 0 || q.withTimeout == nil {
+		return ctx, func() {}
+	}
+
+	return q.withTimeout(context.WithoutCancel(ctx), s.StopTimeout)
+}
@@ -0,0 +1,120 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_StartTimeout(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithStartTimeout(20*time.Millisecond),
+	)
+	c.Register("hung",
+		controls.WithStart(func(ctx context.Context) error {
+			<-ctx.Done()
+
+			return ctx.Err()
+		}),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+	c.Register("patient",
+		controls.WithStart(func(ctx context.Context) error {
+			select {
+			case <-time.After(40 * time.Millisecond):
+				controls.Ready(ctx)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			<-ctx.Done()
+
+			return ctx.Err()
+		}),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithServiceStartTimeout(time.Second),
+	)
+
+	c.Start()
+
+	require.Eventually(t, func() bool { return c.LastError() != nil }, time.Second, time.Millisecond)
+	require.ErrorIs(t, c.LastError(), controls.ErrStartTimeout)
+	assert.Contains(t, c.LastError().Error(), "hung")
+
+	services := c.Services()
+	assert.Equal(t, controls.Failed, services[0].State)
+	assert.Equal(t, controls.Running, services[1].State)
+	assert.True(t, services[1].Ready)
+
+	require.NoError(t, c.Stop())
+}
+
+func TestController_ServiceStopTimeout(t *testing.T) {
+	remaining := make(chan time.Duration, 2)
+
+	record := func(ctx context.Context) {
+		deadline, _ := ctx.Deadline()
+		remaining <- time.Until(deadline)
+	}
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithShutdownTimeout(time.Second),
+	)
+	c.Register("default",
+		controls.WithStart(noopStart),
+		controls.WithStop(record),
+	)
+	c.Register("slow",
+		controls.WithStart(noopStart),
+		controls.WithStop(record),
+		controls.WithServiceStopTimeout(time.Minute),
+	)
+
+	c.Start()
+
+	require.NoError(t, c.StopService("default"))
+	assert.LessOrEqual(t, <-remaining, time.Second)
+
+	require.NoError(t, c.StopService("slow"))
+	assert.Greater(t, <-remaining, time.Second)
+
+	c.Stop()
+}
+
+func TestController_StatusTimeoutDefault(t *testing.T) {
+	errs := make(chan error, 1)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithStatusTimeout(10*time.Millisecond),
+	)
+	c.Register("db",
+		controls.WithStart(noopStart),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithHealthCheck(func(ctx context.Context) error {
+			<-ctx.Done()
+			errs <- ctx.Err()
+
+			return ctx.Err()
+		}),
+	)
+
+	c.Start()
+
+	_, err := c.Status(context.Background())
+	require.ErrorIs(t, err, controls.ErrStatusTimeout)
+	assert.True(t, errors.Is(<-errs, context.DeadlineExceeded))
+
+	require.NoError(t, c.Stop())
+}
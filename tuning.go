@@ -0,0 +1,235 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// TuneRuntime prefixes control messages that adjust the runtime profile of a
+// running RuntimeTuner, e.g. "tune-runtime gogc=50 memlimit=1073741824".
+// Use RuntimeMessage to build one. "ballast=0" releases the ballast.
+const TuneRuntime Message = "tune-runtime"
+
+// RuntimeServiceName is the name the runtime tuning service registers under.
+const RuntimeServiceName = "runtime"
+
+// ErrInvalidRuntimeMessage is returned when a TuneRuntime message can't be parsed.
+var ErrInvalidRuntimeMessage = errors.New("invalid runtime tuning message")
+
+// RuntimeProfile describes garbage collector settings. Zero values leave the
+// corresponding setting as it was.
+type RuntimeProfile struct {
+	// GCPercent is applied with debug.SetGCPercent (GOGC).
+	GCPercent int
+	// MemoryLimit is applied with debug.SetMemoryLimit (GOMEMLIMIT), in bytes.
+	MemoryLimit int64
+	// Ballast is the size in bytes of a heap allocation held for the lifetime
+	// of the service to reduce GC frequency for small heaps.
+	Ballast int
+}
+
+// RuntimeTuner is a service that applies a RuntimeProfile at start, restores
+// the previous settings on stop and accepts TuneRuntime control messages.
+type RuntimeTuner struct {
+	mu       sync.Mutex
+	profile  RuntimeProfile
+	previous RuntimeProfile
+	ballast  []byte
+	applied  bool
+}
+
+// NewRuntimeTuner returns a tuner that applies profile when started.
+func NewRuntimeTuner(profile RuntimeProfile) *RuntimeTuner {
+	return &RuntimeTuner{profile: profile}
+}
+
+// WithRuntimeTuning registers a RuntimeTuner applying profile as the
+// "runtime" service, as WithService does.
+func WithRuntimeTuning(profile RuntimeProfile) ControllerOpt {
+	return WithService(RuntimeServiceName, NewRuntimeTuner(profile).ServiceOptions()...)
+}
+
+// RuntimeMessage builds a TuneRuntime control message for profile.
+func RuntimeMessage(profile RuntimeProfile) Message {
+	parts := []string{string(TuneRuntime)}
+
+	if profile.GCPercent != 0 {
+		parts = append(parts, fmt.Sprintf("gogc=%d", profile.GCPercent))
+	}
+
+	if profile.MemoryLimit != 0 {
+		parts = append(parts, fmt.Sprintf("memlimit=%d", profile.MemoryLimit))
+	}
+
+	if profile.Ballast != 0 {
+		parts = append(parts, fmt.Sprintf("ballast=%d", profile.Ballast))
+	}
+
+	return Message(strings.Join(parts, " "))
+}
+
+// ServiceOptions returns the options wiring the tuner into a controller.
+func (t *RuntimeTuner) ServiceOptions() []ServiceOption {
+	return []ServiceOption{
+		WithStart(t.start),
+		WithStop(t.stop),
+		WithStatus(func() {}),
+		WithMessageHandler(t.handle),
+		WithDetails(t.details),
+	}
+}
+
+// Profile returns the profile currently in effect.
+func (t *RuntimeTuner) Profile() RuntimeProfile {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.profile
+}
+
+// Apply merges profile into the current profile, applying it immediately if
+// the tuner is running.
+func (t *RuntimeTuner) Apply(profile RuntimeProfile) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if profile.GCPercent != 0 {
+		t.profile.GCPercent = profile.GCPercent
+	}
+
+	if profile.MemoryLimit != 0 {
+		t.profile.MemoryLimit = profile.MemoryLimit
+	}
+
+	if profile.Ballast > 0 {
+		t.profile.Ballast = profile.Ballast
+	}
+
+	if t.applied {
+		t.apply(profile)
+	}
+}
+
+func (t *RuntimeTuner) start(_ context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.previous = RuntimeProfile{
+		GCPercent:   debug.SetGCPercent(-1),
+		MemoryLimit: debug.SetMemoryLimit(-1),
+	}
+	debug.SetGCPercent(t.previous.GCPercent)
+
+	t.apply(t.profile)
+	t.applied = true
+
+	return nil
+}
+
+func (t *RuntimeTuner) stop(_ context.Context) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.applied {
+		return
+	}
+
+	debug.SetGCPercent(t.previous.GCPercent)
+	debug.SetMemoryLimit(t.previous.MemoryLimit)
+
+	t.ballast = nil
+	t.applied = false
+}
+
+// apply must be called with t.mu held.
+func (t *RuntimeTuner) apply(profile RuntimeProfile) {
+	if profile.GCPercent != 0 {
+		debug.SetGCPercent(profile.GCPercent)
+	}
+
+	if profile.MemoryLimit != 0 {
+		debug.SetMemoryLimit(profile.MemoryLimit)
+	}
+
+	if profile.Ballast > 0 {
+		t.ballast = make([]byte, profile.Ballast)
+	}
+}
+
+func (t *RuntimeTuner) handle(msg Message) error {
+	fields := strings.Fields(string(msg))
+	if len(fields) == 0 || Message(fields[0]) != TuneRuntime {
+		return nil
+	}
+
+	var (
+		profile     RuntimeProfile
+		dropBallast bool
+	)
+
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrInvalidRuntimeMessage, field)
+		}
+
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: %q: %w", ErrInvalidRuntimeMessage, field, err)
+		}
+
+		switch key {
+		case "gogc":
+			if n < math.MinInt32 || n > math.MaxInt32 {
+				return fmt.Errorf("%w: %q out of range", ErrInvalidRuntimeMessage, field)
+			}
+
+			profile.GCPercent = int(n)
+		case "memlimit":
+			profile.MemoryLimit = n
+		case "ballast":
+			if n < 0 || n > math.MaxInt {
+				return fmt.Errorf("%w: %q out of range", ErrInvalidRuntimeMessage, field)
+			}
+
+			profile.Ballast = int(n)
+			dropBallast = n == 0
+		default:
+			return fmt.Errorf("%w: unknown setting %q", ErrInvalidRuntimeMessage, key)
+		}
+	}
+
+	t.Apply(profile)
+
+	if dropBallast {
+		t.dropBallast()
+	}
+
+	return nil
+}
+
+// dropBallast releases the ballast and stops it being allocated again.
+func (t *RuntimeTuner) dropBallast() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.profile.Ballast = 0
+	t.ballast = nil
+}
+
+func (t *RuntimeTuner) details() map[string]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return map[string]string{
+		"gogc":          strconv.Itoa(t.profile.GCPercent),
+		"memory_limit":  strconv.FormatInt(t.profile.MemoryLimit, 10),
+		"ballast_bytes": strconv.Itoa(len(t.ballast)),
+	}
+}
@@ -0,0 +1,53 @@
+package controls_test
+
+import (
+	"context"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeMessage(t *testing.T) {
+	msg := controls.RuntimeMessage(controls.RuntimeProfile{GCPercent: 50, MemoryLimit: 1 << 20})
+	assert.Equal(t, controls.Message("tune-runtime gogc=50 memlimit=1048576"), msg)
+}
+
+func TestController_RuntimeTuning(t *testing.T) {
+	original := debug.SetGCPercent(100)
+	defer debug.SetGCPercent(original)
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithRuntimeTuning(controls.RuntimeProfile{GCPercent: 150, Ballast: 1024}),
+	)
+	c.Start()
+
+	details := c.Report().Services[0].Details
+	assert.Equal(t, "150", details["gogc"])
+	assert.Equal(t, "1024", details["ballast_bytes"])
+
+	c.Messages() <- controls.RuntimeMessage(controls.RuntimeProfile{GCPercent: 75})
+
+	assert.Eventually(t, func() bool {
+		return c.Report().Services[0].Details["gogc"] == "75"
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 75, debug.SetGCPercent(75))
+
+	c.Messages() <- controls.Message("tune-runtime ballast=-1")
+	c.Messages() <- controls.Message("tune-runtime ballast=0")
+
+	assert.Eventually(t, func() bool {
+		return c.Report().Services[0].Details["ballast_bytes"] == "0"
+	}, time.Second, 5*time.Millisecond)
+	assert.True(t, c.IsRunning())
+
+	c.Stop()
+	require.Eventually(t, c.IsStopped, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 100, debug.SetGCPercent(100))
+}
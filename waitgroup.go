@@ -0,0 +1,179 @@
+package controls
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrWaitGroupMismatch is reported when a WaitGroup supplied through
+// SetWaitGroup is out of step with the controller's own accounting.
+var ErrWaitGroupMismatch = errors.New("wait group mismatch")
+
+// active reports whether a service in state is still doing work that Wait
+// waits for.
+func active(state State) bool {
+	return state == Running || state == Failed || state == Stopping
+}
+
+type unfinished struct {
+	mu       sync.Mutex
+	channels map[*service]chan struct{}
+}
+
+// track opens a channel for s when it becomes active, or is paused in place,
+// and closes it once it stops being either.
+func (u *unfinished) track(s *service) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	ch, open := u.channels[s]
+	busy := active(s.state) || s.suspended
+
+	switch {
+	case busy && !open:
+		if u.channels == nil {
+			u.channels = map[*service]chan struct{}{}
+		}
+
+		u.channels[s] = make(chan struct{})
+	case !busy && open:
+		close(ch)
+		delete(u.channels, s)
+	}
+}
+
+// forget stops tracking s, as when it is adopted by another controller,
+// releasing anything waiting for it.
+func (u *unfinished) forget(s *service) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if ch, open := u.channels[s]; open {
+		close(ch)
+		delete(u.channels, s)
+	}
+}
+
+// pending returns the channels of the active services.
+func (u *unfinished) pending() []chan struct{} {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	pending := make([]chan struct{}, 0, len(u.channels))
+	for _, ch := range u.channels {
+		pending = append(pending, ch)
+	}
+
+	return pending
+}
+
+// awaitServices waits for every active service to finish, giving up once the
+// shutdown has finished without them.
+func (c *Controller) awaitServices() {
+	for {
+		pending := c.services.unfinished.pending()
+		if len(pending) == 0 {
+			return
+		}
+
+		select {
+		case <-pending[0]:
+		case <-c.shutdown.done:
+			return
+		}
+	}
+}
+
+// tracker counts the controller's contributions to the deprecated WaitGroup
+// separately from whatever the caller adds to a WaitGroup set through
+// SetWaitGroup. The count and the WaitGroup change together under mu, so the
+// controller only ever removes what it has already added and never takes the
+// counter below zero itself.
+type tracker struct {
+	mu      sync.Mutex
+	pending int
+	drained chan struct{}
+}
+
+func (t *tracker) add(wg *sync.WaitGroup, n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pending += n
+	wg.Add(n)
+}
+
+// release removes up to n of the controller's contributions, as many as are
+// actually outstanding.
+func (t *tracker) release(wg *sync.WaitGroup, n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n = min(n, t.pending)
+	if n <= 0 {
+		return
+	}
+
+	t.pending -= n
+	wg.Add(-n)
+}
+
+func (t *tracker) outstanding() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.pending
+}
+
+// drain returns a channel closed once wg drains. A WaitGroup can't be waited
+// on with a deadline, so one goroutine waits on it and every caller shares
+// its channel, rather than each Wait leaving another goroutine behind when
+// the WaitGroup never drains.
+func (t *tracker) drain(wg *sync.WaitGroup) <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.drained != nil {
+		select {
+		case <-t.drained:
+			// It drained before, but may have been added to since.
+		default:
+			return t.drained
+		}
+	}
+
+	drained := make(chan struct{})
+	t.drained = drained
+
+	go func() {
+		wg.Wait()
+		close(drained)
+	}()
+
+	return drained
+}
+
+// addToWaitGroup mirrors the controller's accounting onto the attached
+// WaitGroup so callers waiting on it observe the same lifecycle.
+func (c *Controller) addToWaitGroup(n int) {
+	c.tracker.add(c.WaitGroup(), n)
+}
+
+// releaseWaitGroup removes the controller's contributions from the attached
+// WaitGroup.
+func (c *Controller) releaseWaitGroup(n int) {
+	c.tracker.release(c.WaitGroup(), n)
+}
+
+// waitExternal waits for the attached WaitGroup to drain once the controller's
+// own work is done, giving up after the shutdown timeout so a caller's missing
+// Done can't hang Wait forever.
+func (c *Controller) waitExternal() {
+	select {
+	case <-c.tracker.drain(c.WaitGroup()):
+	case <-c.clock.After(c.shutdownTimeout):
+		c.log().Error(fmt.Sprintf("%s: WaitGroup did not drain within %s of the controller stopping",
+			ErrWaitGroupMismatch, c.shutdownTimeout))
+	}
+}
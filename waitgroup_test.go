@@ -0,0 +1,144 @@
+package controls_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWaitGroupController(wg *sync.WaitGroup) (*controls.Controller, *bytes.Buffer) {
+	var buf bytes.Buffer
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+		controls.WithShutdownTimeout(50*time.Millisecond),
+		controls.WithoutSignals(),
+	)
+	c.SetWaitGroup(wg)
+	c.Register("test",
+		controls.WithStart(func(_ context.Context) error { return nil }),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	return c, &buf
+}
+
+func TestController_WaitGroupMismatch(t *testing.T) {
+	t.Run("undrained user wait group does not hang Wait", func(t *testing.T) {
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+
+		c, output := newWaitGroupController(wg)
+		c.Start()
+		c.Stop()
+
+		done := make(chan struct{})
+
+		go func() {
+			c.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Wait did not return")
+		}
+
+		assert.Contains(t, output.String(), controls.ErrWaitGroupMismatch.Error())
+	})
+
+	t.Run("repeated Waits share one wait on the WaitGroup", func(t *testing.T) {
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+
+		c, _ := newWaitGroupController(wg)
+		c.Start()
+		c.Stop()
+		c.Wait()
+
+		before := runtime.NumGoroutine()
+		for range 5 {
+			c.Wait()
+		}
+		assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+
+		wg.Done()
+		c.Wait()
+	})
+
+	t.Run("controller releases only what it added", func(t *testing.T) {
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+
+		c, _ := newWaitGroupController(wg)
+		c.Start()
+		require.NoError(t, c.StopService("test"))
+		require.NoError(t, c.StopService("test"))
+		require.NoError(t, c.RestartService("test"))
+		require.NoError(t, c.Stop())
+		c.Wait()
+
+		wg.Done()
+
+		drained := make(chan struct{})
+
+		go func() {
+			wg.Wait()
+			close(drained)
+		}()
+
+		select {
+		case <-drained:
+		case <-time.After(time.Second):
+			t.Fatal("WaitGroup did not drain")
+		}
+	})
+}
+
+func TestController_Wait(t *testing.T) {
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+	)
+	c.Register("test",
+		controls.WithStart(func(_ context.Context) error { return nil }),
+		controls.WithStop(func(_ context.Context) {}),
+	)
+
+	c.Wait()
+
+	c.Start()
+
+	waited := make(chan struct{})
+
+	go func() {
+		c.Wait()
+		close(waited)
+	}()
+
+	select {
+	case <-waited:
+		t.Fatal("Wait returned while a service was running")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	assert.NoError(t, c.StopService("test"))
+
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return once the service stopped")
+	}
+
+	c.Stop()
+}
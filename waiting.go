@@ -0,0 +1,88 @@
+package controls
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// changes lets goroutines wait for the next change to the controller's or a
+// service's state.
+type changes struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+// next returns a channel closed by the next call to notify.
+func (w *changes) next() <-chan struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.ch == nil {
+		w.ch = make(chan struct{})
+	}
+
+	return w.ch
+}
+
+func (w *changes) notify() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.ch != nil {
+		close(w.ch)
+		w.ch = nil
+	}
+}
+
+// WaitUntilRunning blocks until the controller is Running and every launched
+// service is ready. It returns the error of a service that failed while it
+// waited, ErrNotRunning if the controller stops first, or the cause of ctx
+// ending.
+func (c *Controller) WaitUntilRunning(ctx context.Context) error {
+	for {
+		next := c.changes.next()
+
+		if done, err := c.running(); done {
+			return err
+		}
+
+		select {
+		case <-next:
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+}
+
+// running reports whether WaitUntilRunning is finished, and with what error.
+func (c *Controller) running() (bool, error) {
+	switch c.GetState() {
+	case Running, Degraded, Failed:
+		return c.services.allReady()
+	case Draining, Stopping, Stopped:
+		return true, ErrNotRunning
+	default:
+		return false, nil
+	}
+}
+
+// allReady reports whether every launched service is ready, or the error of
+// the first that failed.
+func (q *Services) allReady() (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ready := true
+
+	for _, s := range q.services {
+		switch {
+		case s.state == Failed:
+			return true, fmt.Errorf("%s: %w", s.Name, s.lastErr)
+		case s.state == Running && !s.ready:
+			ready = false
+		}
+	}
+
+	return ready, nil
+}
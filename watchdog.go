@@ -0,0 +1,118 @@
+package controls
+
+import (
+	"time"
+)
+
+// WithHeartbeat makes the service report in at least every d, by calling
+// Controller.Heartbeat or passing a status check. A running service that
+// misses its deadline is marked stalled and a ServiceStalled event is emitted.
+func WithHeartbeat(d time.Duration) ServiceOption {
+	return func(s *Service) {
+		s.HeartbeatTimeout = d
+	}
+}
+
+// WithRestartOnStall restarts the service automatically when it stalls.
+func WithRestartOnStall() ServiceOption {
+	return func(s *Service) {
+		s.RestartOnStall = true
+	}
+}
+
+// Heartbeat records that the named service is still making progress, clearing
+// a stall.
+func (c *Controller) Heartbeat(name string) error {
+	if err := c.services.heartbeat(name); err != nil {
+		return err
+	}
+
+	c.assess()
+
+	return nil
+}
+
+// startWatchdog checks for stalled services at half the shortest heartbeat
+// deadline registered when the controller starts.
+func (c *Controller) startWatchdog() {
+	interval := c.services.heartbeatInterval()
+	if interval <= 0 {
+		return
+	}
+
+	c.every(interval, func(_ <-chan struct{}) {
+		for _, s := range c.services.stalled() {
+			c.log().Warn("Service stalled", "service", s.Name, "timeout", s.HeartbeatTimeout)
+			c.emit(Event{Type: ServiceStalled, Service: s.Name, Message: "no heartbeat within " + s.HeartbeatTimeout.String()})
+
+			if !s.RestartOnStall {
+				continue
+			}
+
+			if err := c.RestartService(s.Name); err != nil {
+				c.log().Error("Failed to restart stalled service", "service", s.Name, "error", err)
+			}
+		}
+
+		c.assess()
+	})
+}
+
+func (q *Services) heartbeat(name string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, s := range q.services {
+		if s.Name == name {
+			s.beat(q.clock())
+
+			return nil
+		}
+	}
+
+	return ErrServiceNotFound
+}
+
+func (s *service) beat(now time.Time) {
+	s.lastBeat = now
+	s.stalled = false
+}
+
+func (q *Services) heartbeatInterval() time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var shortest time.Duration
+
+	for _, s := range q.services {
+		if s.HeartbeatTimeout > 0 && (shortest == 0 || s.HeartbeatTimeout < shortest) {
+			shortest = s.HeartbeatTimeout
+		}
+	}
+
+	return shortest / 2
+}
+
+// stalled marks running services whose heartbeat is overdue as stalled and
+// returns those that weren't already.
+func (q *Services) stalled() []Service {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.clock()
+
+	var stalled []Service
+
+	for _, s := range q.services {
+		if s.HeartbeatTimeout <= 0 || s.state != Running || s.stalled {
+			continue
+		}
+
+		if now.Sub(s.lastBeat) > s.HeartbeatTimeout {
+			s.stalled = true
+			stalled = append(stalled, s.Service)
+		}
+	}
+
+	return stalled
+}
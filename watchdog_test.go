@@ -0,0 +1,116 @@
+package controls_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+	"github.com/phpboyscout/controls/controlstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_Heartbeat(t *testing.T) {
+	var starts atomic.Int64
+
+	clock := controlstest.NewFakeClock(epoch)
+	rec := &eventRecorder{}
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithClock(clock),
+		controls.WithEventHandler(rec.handle),
+	)
+	c.Register("worker",
+		controls.WithStart(func(_ context.Context) error {
+			starts.Add(1)
+
+			return nil
+		}),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithHeartbeat(10*time.Second),
+	)
+	c.Register("stuck",
+		controls.WithStart(func(_ context.Context) error {
+			starts.Add(1)
+
+			return nil
+		}),
+		controls.WithStop(func(_ context.Context) {}),
+		controls.WithHeartbeat(10*time.Second),
+		controls.WithRestartOnStall(),
+	)
+
+	c.Start()
+	require.Equal(t, int64(2), starts.Load())
+
+	for range 3 {
+		require.NoError(t, c.Heartbeat("worker"))
+		clock.Advance(5 * time.Second)
+	}
+
+	assert.Eventually(t, func() bool {
+		return len(rec.ofType(controls.ServiceRestarted)) == 1
+	}, time.Second, time.Millisecond)
+
+	stalled := rec.ofType(controls.ServiceStalled)
+	require.Len(t, stalled, 1)
+	assert.Equal(t, "stuck", stalled[0].Service)
+	assert.Eventually(t, func() bool { return starts.Load() == 3 }, time.Second, time.Millisecond)
+
+	for _, s := range c.Report().Services {
+		assert.False(t, s.Stalled, s.Name)
+	}
+
+	require.ErrorIs(t, c.Heartbeat("missing"), controls.ErrServiceNotFound)
+	require.NoError(t, c.Stop())
+}
+
+func TestController_HeartbeatWhileStopping(t *testing.T) {
+	quit := make(chan struct{})
+	done := make(chan struct{})
+
+	c := controls.NewController(context.Background(),
+		controls.WithLogger(discardLogger()),
+		controls.WithoutSignals(),
+		controls.WithShutdownTimeout(5*time.Second),
+	)
+	handle := c.Register("worker",
+		controls.WithStart(func(_ context.Context) error {
+			go func() {
+				defer close(done)
+
+				<-quit
+				// A worker reporting in on its way out mustn't wait for the
+				// StopFunc that is waiting for it.
+				_ = c.Heartbeat("worker")
+				_ = c.Report()
+			}()
+
+			return nil
+		}),
+		controls.WithStop(func(_ context.Context) {
+			close(quit)
+			<-done
+		}),
+		controls.WithHeartbeat(time.Minute),
+	)
+
+	c.Start()
+
+	stopped := make(chan error, 1)
+
+	go func() { stopped <- c.Stop() }()
+
+	select {
+	case err := <-stopped:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("stopping waited on the worker's heartbeat")
+	}
+
+	assert.Equal(t, controls.Stopped, handle.State())
+}
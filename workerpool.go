@@ -0,0 +1,176 @@
+package controls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// WorkerFunc is one worker in a WorkerPool. It is given its index in the pool
+// and should return once ctx is cancelled.
+type WorkerFunc func(ctx context.Context, id int) error
+
+// WorkerPool returns a service running n copies of fn, for Register or
+// WithServices. Starting it launches the workers, and stopping it cancels
+// every worker's context and waits for them to return within the shutdown
+// timeout. A worker that fails leaves the pool unhealthy until it is
+// restarted, and each worker's state is reported in the service's Details. An
+// n below one is treated as one.
+func WorkerPool(name string, n int, fn WorkerFunc, opts ...ServiceOption) Service {
+	p := &workerPool{fn: fn, size: max(n, 1)}
+
+	return newService(name, append([]ServiceOption{
+		WithStart(p.start),
+		WithStopErr(p.stop),
+		WithHealthCheck(p.check),
+		WithDetails(p.details),
+	}, opts...)...)
+}
+
+type workerPool struct {
+	fn   WorkerFunc
+	size int
+
+	mu      sync.Mutex
+	cancel  context.CancelCauseFunc
+	done    chan struct{}
+	workers []workerState
+}
+
+type workerState struct {
+	running bool
+	err     error
+}
+
+func (p *workerPool) start(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// The workers outlive Start, so they mustn't be cancelled along with its
+	// context.
+	ctx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+
+	done := make(chan struct{})
+	wg := &sync.WaitGroup{}
+
+	p.cancel = cancel
+	p.done = done
+	// Workers abandoned by an earlier stop keep writing to their own slice.
+	workers := make([]workerState, p.size)
+	p.workers = workers
+
+	for id := range workers {
+		workers[id].running = true
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err := p.fn(ctx, id)
+			if errors.Is(context.Cause(ctx), ErrStopping) && errors.Is(err, context.Canceled) {
+				err = nil
+			}
+
+			if err != nil {
+				LoggerFrom(ctx).Error("Worker failed", "worker", id, "error", err)
+			}
+
+			p.mu.Lock()
+			workers[id] = workerState{err: err}
+			p.mu.Unlock()
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	return nil
+}
+
+// stop cancels the workers and waits for them, returning the errors of any
+// that failed, or ctx's cause if some are still running when it is done.
+func (p *workerPool) stop(ctx context.Context) error {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.cancel, p.done = nil, nil
+	p.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel(ErrStopping)
+
+	select {
+	case <-done:
+		return p.failures()
+	case <-ctx.Done():
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		return fmt.Errorf("%d of %d workers still running: %w", p.running(), p.size, context.Cause(ctx))
+	}
+}
+
+func (p *workerPool) check(_ context.Context) error {
+	return p.failures()
+}
+
+// failures joins the errors of the workers that have failed.
+func (p *workerPool) failures() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var errs []error
+
+	for id, w := range p.workers {
+		if w.err != nil {
+			errs = append(errs, fmt.Errorf("worker %d: %w", id, w.err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// running counts the workers yet to return. It must be called with p.mu held.
+func (p *workerPool) running() int {
+	n := 0
+
+	for _, w := range p.workers {
+		if w.running {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (p *workerPool) details() map[string]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	d := map[string]string{
+		"workers": strconv.Itoa(p.size),
+		"running": strconv.Itoa(p.running()),
+	}
+
+	for id, w := range p.workers {
+		state := "stopped"
+
+		switch {
+		case w.running:
+			state = "running"
+		case w.err != nil:
+			state = "failed: " + w.err.Error()
+		}
+
+		d["worker."+strconv.Itoa(id)] = state
+	}
+
+	return d
+}
@@ -0,0 +1,123 @@
+package controls_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/phpboyscout/controls"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func poolDetails(t *testing.T, c *controls.Controller) map[string]string {
+	t.Helper()
+
+	r := c.Report()
+	require.Len(t, r.Services, 1)
+
+	return r.Services[0].Details
+}
+
+func TestWorkerPool(t *testing.T) {
+	t.Run("runs and stops every worker", func(t *testing.T) {
+		var running, stopped atomic.Int64
+
+		c := controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+			controls.WithServices(controls.WorkerPool("consumers", 3, func(ctx context.Context, _ int) error {
+				running.Add(1)
+				<-ctx.Done()
+				stopped.Add(1)
+
+				return ctx.Err()
+			})),
+		)
+
+		require.NoError(t, c.Start())
+		require.Eventually(t, func() bool { return running.Load() == 3 }, time.Second, 5*time.Millisecond)
+
+		d := poolDetails(t, c)
+		assert.Equal(t, "3", d["workers"])
+		assert.Equal(t, "3", d["running"])
+		assert.Equal(t, "running", d["worker.2"])
+
+		require.NoError(t, c.Stop())
+		assert.Equal(t, int64(3), stopped.Load())
+
+		d = poolDetails(t, c)
+		assert.Equal(t, "0", d["running"])
+		assert.Equal(t, "stopped", d["worker.0"])
+	})
+
+	t.Run("a failed worker makes the pool unhealthy", func(t *testing.T) {
+		errBoom := errors.New("boom") //nolint:err113
+
+		c := controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+			controls.WithServices(controls.WorkerPool("consumers", 2, func(ctx context.Context, id int) error {
+				if id == 1 {
+					return errBoom
+				}
+
+				<-ctx.Done()
+
+				return nil
+			})),
+		)
+
+		require.NoError(t, c.Start())
+		defer c.Stop()
+
+		require.Eventually(t, func() bool { return poolDetails(t, c)["running"] == "1" },
+			time.Second, 5*time.Millisecond)
+		assert.Equal(t, "failed: boom", poolDetails(t, c)["worker.1"])
+
+		_, err := c.Status(context.Background())
+		require.ErrorIs(t, err, errBoom)
+		assert.Contains(t, err.Error(), "worker 1")
+	})
+
+	t.Run("stop gives up on workers that ignore cancellation", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		c := controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+			controls.WithServices(controls.WorkerPool("stubborn", 2, func(_ context.Context, _ int) error {
+				<-release
+
+				return nil
+			}, controls.WithServiceStopTimeout(20*time.Millisecond))),
+		)
+
+		require.NoError(t, c.Start())
+
+		err := c.Stop()
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "2 of 2 workers still running")
+	})
+
+	t.Run("fewer than one worker runs one", func(t *testing.T) {
+		c := controls.NewController(context.Background(),
+			controls.WithLogger(discardLogger()),
+			controls.WithoutSignals(),
+			controls.WithServices(controls.WorkerPool("single", 0, func(ctx context.Context, _ int) error {
+				<-ctx.Done()
+
+				return nil
+			})),
+		)
+
+		require.NoError(t, c.Start())
+		defer c.Stop()
+
+		assert.Equal(t, "1", poolDetails(t, c)["workers"])
+	})
+}